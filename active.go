@@ -0,0 +1,38 @@
+package tmpls
+
+import (
+	"context"
+	"path"
+	"strings"
+)
+
+const defaultActiveClass = "active"
+
+// active returns funcs comparing the RenderContext's request path to a nav
+// item's path. They return Config.ActiveClass when it matches and "" when it
+// doesn't, so they work both as a class and in an if.
+func (t *Templates) active(prefix bool) ContextFunc {
+	return func(ctx context.Context) any {
+		return func(target string) string {
+			rc := RenderContextFrom(ctx)
+			if rc == nil || rc.Request == nil || !matchPath(rc.Request.URL.Path, target, prefix) {
+				return ""
+			}
+			if t.config.ActiveClass != "" {
+				return t.config.ActiveClass
+			}
+			return defaultActiveClass
+		}
+	}
+}
+
+// matchPath reports whether current is target or, with prefix, a path below
+// it. Trailing slashes are ignored.
+func matchPath(current string, target string, prefix bool) bool {
+	current = path.Clean("/" + current)
+	target = path.Clean("/" + target)
+	if current == target {
+		return true
+	}
+	return prefix && target != "/" && strings.HasPrefix(current, target+"/")
+}
@@ -0,0 +1,90 @@
+package tmpls_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestActive(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		config   tmpls.Config
+		path     string
+		src      string
+		expected string
+	}{
+		{
+			name:     "should match exact path",
+			path:     "/settings/",
+			src:      `<a class="{{ active "/settings" }}">`,
+			expected: `<a class="active">`,
+		},
+		{
+			name:     "should not match sub path exactly",
+			path:     "/settings/profile",
+			src:      `<a class="{{ active "/settings" }}">`,
+			expected: `<a class="">`,
+		},
+		{
+			name:     "should match sub path by prefix",
+			path:     "/settings/profile",
+			src:      `{{ if activePrefix "/settings" }}yes{{ end }}`,
+			expected: "yes",
+		},
+		{
+			name:     "should not match sibling by prefix",
+			path:     "/settings-old",
+			src:      `{{ if activePrefix "/settings" }}yes{{ else }}no{{ end }}`,
+			expected: "no",
+		},
+		{
+			name:     "should only match root exactly",
+			path:     "/settings",
+			src:      `{{ if activePrefix "/" }}yes{{ else }}no{{ end }}`,
+			expected: "no",
+		},
+		{
+			name:     "should use configured class",
+			config:   tmpls.Config{ActiveClass: "is-current"},
+			path:     "/",
+			src:      `{{ active "/" }}`,
+			expected: "is-current",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, test.config, test.src)
+			ctx := tmpls.WithRenderContext(
+				context.Background(),
+				&tmpls.RenderContext{Request: httptest.NewRequest(http.MethodGet, test.path, nil)},
+			)
+			output, err := templates.ExecuteContext(ctx, "page.html.tmpl", "page.html.tmpl", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestActiveWithoutRequest(t *testing.T) {
+	t.Parallel()
+
+	output, err := render(t, tmpls.Config{}, `[{{ active "/" }}]`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "[]" {
+		t.Fatalf("expected [] but got %s", output)
+	}
+}
@@ -0,0 +1,192 @@
+package tmpls
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+var (
+	errOverflow       = errors.New("integer overflow")
+	errDivisionByZero = errors.New("division by zero")
+)
+
+// number is an operand of the arithmetic funcs, an int64 unless isFloat.
+type number struct {
+	i       int64
+	f       float64
+	isFloat bool
+}
+
+func toNumber(value any) (number, error) {
+	v := reflect.ValueOf(value)
+	switch {
+	case v.CanInt():
+		return number{i: v.Int()}, nil
+	case v.CanUint():
+		if v.Uint() > math.MaxInt64 {
+			return number{}, errOverflow
+		}
+		return number{i: int64(v.Uint())}, nil
+	case v.CanFloat():
+		return number{f: v.Float(), isFloat: true}, nil
+	default:
+		return number{}, fmt.Errorf("unsupported type %T", value)
+	}
+}
+
+func (n number) float() float64 {
+	if n.isFloat {
+		return n.f
+	}
+	return float64(n.i)
+}
+
+func (n number) value() any {
+	if n.isFloat {
+		return n.f
+	}
+	return n.i
+}
+
+// arith applies op to the operands left to right. The result is an int64 if
+// every operand is an integer, failing rather than wrapping on overflow, and
+// otherwise a float64.
+func arith(
+	fn string,
+	operands []any,
+	intOp func(a, b int64) (int64, error),
+	floatOp func(a, b float64) (float64, error),
+) (any, error) {
+	if len(operands) < 2 {
+		return nil, fmt.Errorf("%s: requires at least 2 operands but got %d", fn, len(operands))
+	}
+	result, err := toNumber(operands[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	for _, operand := range operands[1:] {
+		n, err := toNumber(operand)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		if result.isFloat || n.isFloat {
+			result.f, err = floatOp(result.float(), n.float())
+			result.isFloat = true
+		} else {
+			result.i, err = intOp(result.i, n.i)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+	return result.value(), nil
+}
+
+// add returns the sum of its operands:
+//
+//	{{ add .Index 1 }}
+func add(operands ...any) (any, error) {
+	return arith("add", operands,
+		func(a, b int64) (int64, error) {
+			r := a + b
+			if (a > 0 && b > 0 && r < 0) || (a < 0 && b < 0 && r >= 0) {
+				return 0, errOverflow
+			}
+			return r, nil
+		},
+		func(a, b float64) (float64, error) { return a + b, nil },
+	)
+}
+
+// sub returns the first operand minus the others.
+func sub(operands ...any) (any, error) {
+	return arith("sub", operands,
+		func(a, b int64) (int64, error) {
+			r := a - b
+			if (b < 0 && r < a) || (b > 0 && r > a) {
+				return 0, errOverflow
+			}
+			return r, nil
+		},
+		func(a, b float64) (float64, error) { return a - b, nil },
+	)
+}
+
+// mul returns the product of its operands.
+func mul(operands ...any) (any, error) {
+	return arith("mul", operands,
+		func(a, b int64) (int64, error) {
+			if a == 0 || b == 0 {
+				return 0, nil
+			}
+			r := a * b
+			if r/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+				return 0, errOverflow
+			}
+			return r, nil
+		},
+		func(a, b float64) (float64, error) { return a * b, nil },
+	)
+}
+
+// div returns the first operand divided by the others, truncated toward zero
+// if they are all integers. Dividing by zero fails, also for floats.
+func div(operands ...any) (any, error) {
+	return arith("div", operands,
+		func(a, b int64) (int64, error) {
+			switch {
+			case b == 0:
+				return 0, errDivisionByZero
+			case a == math.MinInt64 && b == -1:
+				return 0, errOverflow
+			}
+			return a / b, nil
+		},
+		func(a, b float64) (float64, error) {
+			if b == 0 {
+				return 0, errDivisionByZero
+			}
+			return a / b, nil
+		},
+	)
+}
+
+// mod returns the remainder of dividing the first operand by the others, with
+// the sign of the first operand. Dividing by zero fails.
+func mod(operands ...any) (any, error) {
+	return arith("mod", operands,
+		func(a, b int64) (int64, error) {
+			if b == 0 {
+				return 0, errDivisionByZero
+			}
+			return a % b, nil
+		},
+		func(a, b float64) (float64, error) {
+			if b == 0 {
+				return 0, errDivisionByZero
+			}
+			return math.Mod(a, b), nil
+		},
+	)
+}
+
+// percent returns part as a percentage of total, or 0 if total is zero, e.g.
+// for progress bars before anything is known:
+//
+//	<progress value="{{ percent .Done .Total }}" max="100">
+func percent(part any, total any) (float64, error) {
+	p, err := toNumber(part)
+	if err != nil {
+		return 0, fmt.Errorf("percent: %w", err)
+	}
+	t, err := toNumber(total)
+	if err != nil {
+		return 0, fmt.Errorf("percent: %w", err)
+	}
+	if t.float() == 0 {
+		return 0, nil
+	}
+	return p.float() / t.float() * 100, nil
+}
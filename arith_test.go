@@ -0,0 +1,114 @@
+package tmpls_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestArithFuncs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "should do integer arithmetic across int types",
+			src: `{{ add .a 1 }} {{ sub .a .b 1 }} {{ mul .a .b }} ` +
+				`{{ div .a .b }} {{ mod .a .b }}`,
+			data:     map[string]any{"a": int64(7), "b": uint8(2)},
+			expected: "8 4 14 3 1",
+		},
+		{
+			name:     "should do float arithmetic when mixing ints and floats",
+			src:      `{{ add 1 0.5 }} {{ div 7 2.0 }} {{ mul .a 2 }} {{ mod 7.5 2 }}`,
+			data:     map[string]any{"a": float32(1.25)},
+			expected: "1.5 3.5 2.5 1.5",
+		},
+		{
+			name:     "should compute percentages",
+			src:      `{{ percent 1 4 }} {{ percent .Done .Total }}`,
+			data:     map[string]int{"Done": 0, "Total": 0},
+			expected: "25 0",
+		},
+		{
+			name:        "should fail to divide by zero",
+			src:         `{{ div 1 0 }}`,
+			expectError: true,
+		},
+		{
+			name:        "should fail to divide floats by zero",
+			src:         `{{ div 1.5 0 }}`,
+			expectError: true,
+		},
+		{
+			name:        "should fail to take modulo by zero",
+			src:         `{{ mod 1 0 }}`,
+			expectError: true,
+		},
+		{
+			name:        "should fail on addition overflow",
+			src:         `{{ add . 1 }}`,
+			data:        int64(math.MaxInt64),
+			expectError: true,
+		},
+		{
+			name:        "should fail on subtraction overflow",
+			src:         `{{ sub . 1 }}`,
+			data:        int64(math.MinInt64),
+			expectError: true,
+		},
+		{
+			name:        "should fail on multiplication overflow",
+			src:         `{{ mul . -1 }}`,
+			data:        int64(math.MinInt64),
+			expectError: true,
+		},
+		{
+			name:        "should fail on division overflow",
+			src:         `{{ div . -1 }}`,
+			data:        int64(math.MinInt64),
+			expectError: true,
+		},
+		{
+			name:        "should fail on unsigned values beyond int64",
+			src:         `{{ add . 1 }}`,
+			data:        uint64(math.MaxUint64),
+			expectError: true,
+		},
+		{
+			name:        "should fail on non numbers",
+			src:         `{{ add "1" 1 }}`,
+			expectError: true,
+		},
+		{
+			name:        "should fail on a single operand",
+			src:         `{{ add 1 }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{}, test.src, test.data)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package tmpls
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// asyncScript moves the content streamed for an async block into the page in
+// place of its placeholder.
+const asyncScript = `function tmplsAsync(id){` +
+	`var c=document.getElementById(id+"-content"),p=document.getElementById(id);` +
+	`if(c&&p){p.replaceWith(c.content);c.remove()}}`
+
+// StreamRequest is RenderRequest for pages with async blocks, which render a
+// placeholder while their data loads:
+//
+//	{{ async "comments" .Comments }}<p>Loading comments…</p>{{ end }}
+//
+// The page is written and flushed as soon as it has executed, with each
+// block's placeholder in place. The block's value, a func taking an optional
+// context and returning a value and optional error, is called in the
+// background from the start, and as each returns, the named template is
+// executed with its result and streamed in the same response along with an
+// inline script swapping it in, using the request's nonce.
+//
+// Errors of async blocks are logged and leave their placeholder in place,
+// since the status has already been sent. Outside StreamRequest, async blocks
+// wait for their value and render the template in place.
+func (t *Templates) StreamRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	glob string,
+	template string,
+	data any,
+) error {
+	ctx, nonce, err := t.requestContext(r)
+	if err != nil {
+		return err
+	}
+	ctx = withExecution(ctx)
+	start := time.Now()
+	async := &asyncBlocks{
+		templates: t,
+		nonce:     nonce,
+		stop:      make(chan struct{}),
+		results:   make(chan *asyncBlock),
+		commit: func(body []byte) error {
+			t.writeHeader(ctx, w, status, nonce, start)
+			if _, err := w.Write(body); err != nil {
+				return err
+			}
+			return http.NewResponseController(w).Flush()
+		},
+		w: w,
+	}
+	defer async.discard()
+	executionFrom(ctx).async = async
+
+	buffer := t.buffers.Get().(*bytes.Buffer)
+	defer func() {
+		buffer.Reset()
+		t.buffers.Put(buffer)
+	}()
+	if err := t.execute(ctx, buffer, glob, template, data); err != nil {
+		return err
+	}
+	if t.config.TrackUsage {
+		t.recordUsage(glob, template)
+	}
+	if !async.committed {
+		// engines other than HTMLEngine don't stream
+		return async.commit(buffer.Bytes())
+	}
+	return async.err
+}
+
+// asyncBlocks are the async blocks of an execution by StreamRequest.
+type asyncBlocks struct {
+	templates *Templates
+	nonce     string
+	// commit writes the status, headers and page.
+	commit    func(body []byte) error
+	committed bool
+	w         io.Writer
+	stop      chan struct{}
+	results   chan *asyncBlock
+	pending   int
+	count     int
+	scripted  bool
+	err       error
+}
+
+// asyncBlock is the result of an async block's value.
+type asyncBlock struct {
+	id    string
+	name  string
+	value any
+	err   error
+}
+
+// add starts calling value in the background, returning the id of the
+// block's placeholder.
+func (a *asyncBlocks) add(ctx context.Context, name string, value any) string {
+	a.count++
+	a.pending++
+	block := &asyncBlock{id: fmt.Sprintf("tmpls-async-%d", a.count), name: name}
+	stop, results := a.stop, a.results
+	go func() {
+		block.value, block.err = resolveAsync(ctx, value)
+		select {
+		case results <- block:
+		case <-stop:
+		}
+	}()
+	return block.id
+}
+
+// finish commits body, the executed page, then streams the content of each
+// async block as its value returns. Write errors are kept for StreamRequest,
+// since the page has already been sent.
+func (a *asyncBlocks) finish(ctx context.Context, executor Executor, body io.Writer) {
+	if metered, ok := body.(meteredWriter); ok {
+		body = metered.w
+	}
+	buffer, ok := body.(*bytes.Buffer)
+	if !ok || a.committed {
+		return
+	}
+	a.committed = true
+	if a.err = a.commit(buffer.Bytes()); a.err != nil {
+		return
+	}
+	for ; a.pending > 0; a.pending-- {
+		var block *asyncBlock
+		select {
+		case block = <-a.results:
+		case <-ctx.Done():
+			a.err = ctx.Err()
+			return
+		}
+		if a.err = a.stream(executor, block); a.err != nil {
+			return
+		}
+	}
+}
+
+// stream writes the content of block, preceded by asyncScript the first time.
+func (a *asyncBlocks) stream(executor Executor, block *asyncBlock) error {
+	var content bytes.Buffer
+	err := block.err
+	if err == nil {
+		err = executor.ExecuteTemplate(&content, block.name, block.value)
+	}
+	if err != nil {
+		a.templates.log().Error(
+			"Async block failed, leaving placeholder",
+			"template", block.name,
+			"error", err,
+		)
+		return nil
+	}
+	nonce := ""
+	if a.nonce != "" {
+		nonce = fmt.Sprintf(` nonce="%s"`, template.HTMLEscapeString(a.nonce))
+	}
+	var out bytes.Buffer
+	if !a.scripted {
+		a.scripted = true
+		fmt.Fprintf(&out, "<script%s>%s</script>", nonce, asyncScript)
+	}
+	fmt.Fprintf(
+		&out,
+		`<template id="%s-content">%s</template><script%s>tmplsAsync(%q)</script>`,
+		block.id,
+		content.String(),
+		nonce,
+		block.id,
+	)
+	if _, err := out.WriteTo(a.w); err != nil {
+		return err
+	}
+	if w, ok := a.w.(http.ResponseWriter); ok {
+		return http.NewResponseController(w).Flush()
+	}
+	return nil
+}
+
+// discard drops blocks still loading, e.g. because the page failed.
+func (a *asyncBlocks) discard() {
+	close(a.stop)
+	a.stop = make(chan struct{})
+	a.results = make(chan *asyncBlock)
+	a.pending = 0
+}
+
+var (
+	contextType = reflect.TypeFor[context.Context]()
+	errorType   = reflect.TypeFor[error]()
+)
+
+// resolveAsync calls value if it is a func taking an optional context and
+// returning a value and optional error, or else returns it as is.
+func resolveAsync(ctx context.Context, value any) (result any, err error) {
+	fn := reflect.ValueOf(value)
+	if fn.Kind() != reflect.Func {
+		return value, nil
+	}
+	typ := fn.Type()
+	var args []reflect.Value
+	switch {
+	case typ.NumIn() == 1 && typ.In(0) == contextType:
+		args = []reflect.Value{reflect.ValueOf(ctx)}
+	case typ.NumIn() != 0:
+		return nil, fmt.Errorf("async: unsupported func %T", value)
+	}
+	if typ.NumOut() != 1 && (typ.NumOut() != 2 || typ.Out(1) != errorType) {
+		return nil, fmt.Errorf("async: unsupported func %T", value)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("async: panic: %v", r)
+		}
+	}()
+	out := fn.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return nil, out[1].Interface().(error)
+	}
+	return out[0].Interface(), nil
+}
+
+// async renders the template name with value, which is resolved in the
+// background when streamed by StreamRequest while the block's content is
+// rendered as a placeholder.
+func async(ctx context.Context) any {
+	return func(name string, value any, placeholder string, dot any) (template.HTML, error) {
+		executor, execution, err := executorFrom(ctx)
+		if err != nil {
+			return "", err
+		}
+		var buffer bytes.Buffer
+		if execution.async == nil {
+			resolved, err := resolveAsync(ctx, value)
+			if err != nil {
+				return "", err
+			}
+			if err := executor.ExecuteTemplate(&buffer, name, resolved); err != nil {
+				return "", err
+			}
+			return template.HTML(buffer.String()), nil
+		}
+		id := execution.async.add(ctx, name, value)
+		if err := executor.ExecuteTemplate(&buffer, placeholder, dot); err != nil {
+			return "", err
+		}
+		return template.HTML(fmt.Sprintf(
+			`<tmpls-async id="%s">%s</tmpls-async>`,
+			id,
+			buffer.String(),
+		)), nil
+	}
+}
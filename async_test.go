@@ -0,0 +1,185 @@
+package tmpls_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+// flushRecorder records a response, signalling its first flush.
+type flushRecorder struct {
+	mu      sync.Mutex
+	header  http.Header
+	status  int
+	body    strings.Builder
+	flushed chan struct{}
+	once    sync.Once
+}
+
+func (r *flushRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *flushRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *flushRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body.Write(p)
+}
+
+func (r *flushRecorder) Flush() {
+	r.once.Do(func() {
+		close(r.flushed)
+	})
+}
+
+func (r *flushRecorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body.String()
+}
+
+const asyncPage = `{{ define "comments" }}` +
+	`<ul>{{ range . }}<li>{{ . }}</li>{{ end }}</ul>{{ end }}` +
+	`<main>{{ async "comments" .Comments }}<p>Loading {{ .Title }}</p>{{ end }}</main>`
+
+func TestStreamRequest(t *testing.T) {
+	t.Parallel()
+
+	templates := newTemplates(t, tmpls.Config{CSP: "script-src 'nonce-{nonce}'"}, asyncPage)
+	release := make(chan struct{})
+	data := map[string]any{
+		"Title": "comments",
+		"Comments": func(ctx context.Context) ([]string, error) {
+			<-release
+			return []string{"<first>", "second"}, nil
+		},
+	}
+	w := &flushRecorder{header: http.Header{}, flushed: make(chan struct{})}
+	done := make(chan error)
+	go func() {
+		done <- templates.StreamRequest(
+			w,
+			httptest.NewRequest(http.MethodGet, "/", nil),
+			http.StatusOK,
+			"page.html.tmpl",
+			"page.html.tmpl",
+			data,
+		)
+	}()
+
+	<-w.flushed
+	expected := `<main><tmpls-async id="tmpls-async-1"><p>Loading comments</p></tmpls-async></main>`
+	if output := w.String(); output != expected {
+		t.Fatalf("expected %s but got %s", expected, output)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	output := w.String()
+	nonce := strings.TrimSuffix(
+		strings.TrimPrefix(w.header.Get("Content-Security-Policy"), "script-src 'nonce-"),
+		"'",
+	)
+	for _, expected := range []string{
+		`<script nonce="` + nonce + `">function tmplsAsync(`,
+		`<template id="tmpls-async-1-content"><ul><li>&lt;first&gt;</li><li>second</li></ul>` +
+			`</template><script nonce="` + nonce + `">tmplsAsync("tmpls-async-1")</script>`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("expected %s in %s", expected, output)
+		}
+	}
+	if w.status != http.StatusOK {
+		t.Fatalf("expected %d but got %d", http.StatusOK, w.status)
+	}
+}
+
+func TestAsync(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		comments    any
+		stream      bool
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should render inline when executed",
+			comments: func() []string { return []string{"a"} },
+			expected: `<main><ul><li>a</li></ul></main>`,
+		},
+		{
+			name:     "should render plain values inline",
+			comments: []string{"a"},
+			expected: `<main><ul><li>a</li></ul></main>`,
+		},
+		{
+			name:        "should fail inline for value errors",
+			comments:    func() ([]string, error) { return nil, errors.New("down") },
+			expectError: true,
+		},
+		{
+			name:        "should fail for unsupported funcs",
+			comments:    func(string) []string { return nil },
+			expectError: true,
+		},
+		{
+			name:     "should leave placeholder when streamed value fails",
+			comments: func() ([]string, error) { return nil, errors.New("down") },
+			stream:   true,
+			expected: `<main><tmpls-async id="tmpls-async-1"><p>Loading x</p></tmpls-async></main>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, tmpls.Config{}, asyncPage)
+			data := map[string]any{"Title": "x", "Comments": test.comments}
+			if test.stream {
+				recorder := httptest.NewRecorder()
+				err := templates.StreamRequest(
+					recorder,
+					httptest.NewRequest(http.MethodGet, "/", nil),
+					http.StatusOK,
+					"page.html.tmpl",
+					"page.html.tmpl",
+					data,
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if output := recorder.Body.String(); output != test.expected {
+					t.Fatalf("expected %s but got %s", test.expected, output)
+				}
+				return
+			}
+			output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", data)
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
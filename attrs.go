@@ -0,0 +1,135 @@
+package tmpls
+
+import (
+	"fmt"
+	"html/template"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// classes joins class names, skipping empty ones, for conditional classes:
+//
+//	<button class="{{ classes "btn" (when .Primary "btn-primary") }}">
+//
+// Arguments may be strings, string slices, or maps of names to whether they
+// apply.
+func classes(names ...any) (string, error) {
+	var out []string
+	for _, name := range names {
+		switch v := name.(type) {
+		case nil:
+		case string:
+			out = append(out, strings.Fields(v)...)
+		case []string:
+			for _, s := range v {
+				out = append(out, strings.Fields(s)...)
+			}
+		case map[string]bool:
+			keys := make([]string, 0, len(v))
+			for key, ok := range v {
+				if ok {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			out = append(out, keys...)
+		default:
+			return "", fmt.Errorf("classes: unsupported type %T", name)
+		}
+	}
+	return strings.Join(out, " "), nil
+}
+
+// when returns value if condition is set, or else an empty string.
+func when(condition bool, value any) any {
+	if condition {
+		return value
+	}
+	return ""
+}
+
+var attrNamePattern = regexp.MustCompile(`^[a-zA-Z_:][-a-zA-Z0-9_:.]*$`)
+
+// urlAttrs hold URLs, whose values are checked like html/template does.
+var urlAttrs = map[string]bool{
+	"action": true, "formaction": true, "href": true, "poster": true, "src": true,
+	"cite": true, "data": true, "codebase": true, "background": true,
+	"longdesc": true, "usemap": true, "manifest": true, "ping": true, "icon": true,
+}
+
+// attrBase returns lower, a lowercase attribute name, without the data- and
+// namespace prefixes html/template ignores when classifying attributes, so
+// xlink:href is an href. Namespace declarations, xmlns:*, return "xmlns".
+func attrBase(lower string) string {
+	lower = strings.TrimPrefix(lower, "data-")
+	if prefix, short, ok := strings.Cut(lower, ":"); ok {
+		if prefix == "xmlns" {
+			return "xmlns"
+		}
+		return short
+	}
+	return lower
+}
+
+// isURLAttr reports whether base, as returned by attrBase, holds a URL,
+// guessing from the name like html/template for unknown attributes.
+func isURLAttr(base string) bool {
+	return urlAttrs[base] || base == "xmlns" || strings.Contains(base, "src") ||
+		strings.Contains(base, "uri") || strings.Contains(base, "url")
+}
+
+// attrs builds attributes from name-value pairs, for use inside a tag:
+//
+//	<a {{ attrs "href" .URL "aria-current" (when .Active "page") "hidden" .Hidden }}>
+//
+// Values are escaped; true renders a boolean attribute while false, nil and
+// empty strings drop the attribute. Event handler and style attributes are
+// rejected since their values can't be escaped safely, and URL attributes
+// with unsafe schemes are replaced like html/template does.
+func attrs(pairs ...any) (template.HTMLAttr, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("attrs requires name-value pairs but got %d arguments", len(pairs))
+	}
+	var out []string
+	for i := 0; i < len(pairs); i += 2 {
+		name, ok := pairs[i].(string)
+		if !ok || !attrNamePattern.MatchString(name) {
+			return "", fmt.Errorf("attrs: invalid attribute name %v", pairs[i])
+		}
+		lower := strings.ToLower(name)
+		// namespaced handlers, such as xlink:onload, run too
+		local := lower[strings.IndexByte(lower, ':')+1:]
+		if strings.HasPrefix(local, "on") || local == "style" || local == "srcdoc" {
+			return "", fmt.Errorf("attrs: unsafe attribute %q", name)
+		}
+		var value string
+		switch v := pairs[i+1].(type) {
+		case nil:
+			continue
+		case bool:
+			if v {
+				out = append(out, name)
+			}
+			continue
+		case string:
+			value = v
+		default:
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+				continue
+			}
+			value = fmt.Sprint(v)
+		}
+		if value == "" {
+			continue
+		}
+		if isURLAttr(attrBase(lower)) {
+			value = safeHref(value)
+		} else {
+			value = template.HTMLEscapeString(value)
+		}
+		out = append(out, name+`="`+value+`"`)
+	}
+	return template.HTMLAttr(strings.Join(out, " ")), nil
+}
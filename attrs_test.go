@@ -0,0 +1,117 @@
+package tmpls_test
+
+import (
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestAttrFuncs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		data        map[string]any
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "should join classes",
+			src: `<b class="{{ classes "btn" (when .Primary "btn-primary") ` +
+				`(when .Big "big") }}">`,
+			data:     map[string]any{"Primary": true, "Big": false},
+			expected: `<b class="btn btn-primary">`,
+		},
+		{
+			name: "should join class slices and maps",
+			src:  `<b class="{{ classes .List " x  y " .Map }}">`,
+			data: map[string]any{
+				"List": []string{"a", ""},
+				"Map":  map[string]bool{"c": true, "b": true, "d": false},
+			},
+			expected: `<b class="a x y b c">`,
+		},
+		{
+			name:        "should fail for unsupported class type",
+			src:         `<b class="{{ classes 1 }}">`,
+			expectError: true,
+		},
+		{
+			name: "should build attributes",
+			src:  `<a {{ attrs "href" .URL "title" .Title "hidden" .Hidden "data-id" .ID }}>`,
+			data: map[string]any{
+				"URL":    "/a?b=1&c=2",
+				"Title":  `"quoted" <t>`,
+				"Hidden": true,
+				"ID":     7,
+			},
+			expected: `<a href="/a?b=1&amp;c=2" title="&#34;quoted&#34; &lt;t&gt;" ` +
+				`hidden data-id="7">`,
+		},
+		{
+			name: "should drop false and empty attributes",
+			src: `<a {{ attrs "hidden" false "title" "" "aria-current" (when false "page") ` +
+				`"id" nil }}>`,
+			expected: `<a >`,
+		},
+		{
+			name:     "should replace unsafe urls",
+			src:      `<a {{ attrs "href" .URL }}>`,
+			data:     map[string]any{"URL": "javascript:alert(1)"},
+			expected: `<a href="#ZgotmplZ">`,
+		},
+		{
+			name: "should replace unsafe urls in namespaced and other url attributes",
+			src: `<svg {{ attrs "xlink:href" .URL }}><object {{ attrs "data" .URL }}>` +
+				`<b {{ attrs "xmlns:x" .URL "data-src" .URL "imgurl" .URL }}>`,
+			data: map[string]any{"URL": "javascript:alert(1)"},
+			expected: `<svg xlink:href="#ZgotmplZ"><object data="#ZgotmplZ">` +
+				`<b xmlns:x="#ZgotmplZ" data-src="#ZgotmplZ" imgurl="#ZgotmplZ">`,
+		},
+		{
+			name:        "should reject event handlers",
+			src:         `<a {{ attrs "onclick" "alert(1)" }}>`,
+			expectError: true,
+		},
+		{
+			name:        "should reject namespaced event handlers",
+			src:         `<svg {{ attrs "xlink:onload" "alert(1)" }}>`,
+			expectError: true,
+		},
+		{
+			name:        "should reject namespaced styles",
+			src:         `<svg {{ attrs "svg:style" "color: red" }}>`,
+			expectError: true,
+		},
+		{
+			name:        "should reject invalid names",
+			src:         `<a {{ attrs "a b" "c" }}>`,
+			expectError: true,
+		},
+		{
+			name:        "should reject odd arguments",
+			src:         `<a {{ attrs "href" }}>`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{}, test.src, test.data)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package tmpls
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+)
+
+// Audit logs every execution, for environments that must reconstruct what
+// was shown to whom. Each record has the glob, template, correlation ID added
+// with WithCorrelationID and a SHA-256 hash of the data's JSON encoding, so
+// the data itself stays out of the logs but a stored copy can be matched to
+// the record.
+type Audit struct {
+	// Logger receives the records. Defaults to the Templates' logger.
+	Logger *slog.Logger
+	// Level is the level of the records. Defaults to slog.LevelInfo.
+	Level slog.Level
+}
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx carrying id, which audit records of
+// executions with ctx include.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFrom returns the correlation ID carried by ctx, or "".
+func CorrelationIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// dataHash returns the hex SHA-256 hash of data's JSON encoding.
+func dataHash(data any) (string, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (t *Templates) audit(
+	ctx context.Context,
+	glob string,
+	templateName string,
+	hash string,
+	hashErr error,
+	err error,
+) {
+	logger := t.config.Audit.Logger
+	if logger == nil {
+		logger = t.log()
+	}
+	attrs := []slog.Attr{
+		slog.String("glob", glob),
+		slog.String("template", templateName),
+		slog.String("correlationID", CorrelationIDFrom(ctx)),
+		slog.String("dataHash", hash),
+	}
+	if hashErr != nil {
+		attrs = append(attrs, slog.String("dataHashError", hashErr.Error()))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.LogAttrs(ctx, t.config.Audit.Level, "Executed template", attrs...)
+}
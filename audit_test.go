@@ -0,0 +1,113 @@
+package tmpls_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestAudit(t *testing.T) {
+	t.Parallel()
+
+	sum := sha256.Sum256([]byte(`{"Name":"a"}`))
+	hash := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name          string
+		src           string
+		ctx           context.Context
+		data          any
+		expected      map[string]string
+		expectError   bool
+		expectHashErr bool
+	}{
+		{
+			name: "should log execution",
+			src:  `{{ .Name }}`,
+			ctx:  tmpls.WithCorrelationID(context.Background(), "req-1"),
+			data: map[string]string{"Name": "a"},
+			expected: map[string]string{
+				"msg":           "Executed template",
+				"glob":          "page.html.tmpl",
+				"template":      "page.html.tmpl",
+				"correlationID": "req-1",
+				"dataHash":      hash,
+			},
+		},
+		{
+			name:        "should log failed execution",
+			src:         `{{ index .Name 5 }}`,
+			ctx:         context.Background(),
+			data:        map[string]string{"Name": "a"},
+			expectError: true,
+			expected: map[string]string{
+				"correlationID": "",
+				"dataHash":      hash,
+			},
+		},
+		{
+			name:          "should log unhashable data",
+			src:           `x`,
+			ctx:           context.Background(),
+			data:          map[string]any{"Func": func() {}},
+			expectHashErr: true,
+			expected: map[string]string{
+				"dataHash": "",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var logs bytes.Buffer
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: fstest.MapFS{
+						"common/empty.html.tmpl": &fstest.MapFile{},
+						"page.html.tmpl":         &fstest.MapFile{Data: []byte(test.src)},
+					},
+					CommonGlob: "common/*.html.tmpl",
+					Audit: &tmpls.Audit{
+						Logger: slog.New(slog.NewJSONHandler(&logs, nil)),
+					},
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = templates.ExecuteContext(
+				test.ctx,
+				"page.html.tmpl",
+				"page.html.tmpl",
+				test.data,
+			)
+			if (err != nil) != test.expectError {
+				t.Fatalf("expected error=%v but got %v", test.expectError, err)
+			}
+			var record map[string]any
+			if err := json.Unmarshal(logs.Bytes(), &record); err != nil {
+				t.Fatalf("expected one record but got %s", logs.String())
+			}
+			for key, expected := range test.expected {
+				if record[key] != expected {
+					t.Fatalf("expected %s=%s but got %v", key, expected, record[key])
+				}
+			}
+			if _, ok := record["error"]; ok != test.expectError {
+				t.Fatalf("expected error attr=%v but got %v", test.expectError, record)
+			}
+			if _, ok := record["dataHashError"]; ok != test.expectHashErr {
+				t.Fatalf("expected dataHashError attr=%v but got %v", test.expectHashErr, record)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package tmpls
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// AvatarProvider builds the URLs of the avatar func.
+type AvatarProvider interface {
+	// AvatarURL returns the URL of the avatar for email, size pixels wide.
+	AvatarURL(email string, size int) string
+}
+
+// AvatarProviderFunc adapts an ordinary function to an AvatarProvider.
+type AvatarProviderFunc func(email string, size int) string
+
+func (f AvatarProviderFunc) AvatarURL(email string, size int) string {
+	return f(email, size)
+}
+
+// Gravatar is the AvatarProvider for gravatar.com, the default.
+type Gravatar struct {
+	// Default is the image shown for addresses without a Gravatar, e.g.
+	// "identicon" or the URL of an image. Defaults to Gravatar's logo.
+	Default string
+	// Rating is the highest rating of images shown, e.g. "pg". Defaults to
+	// "g".
+	Rating string
+}
+
+// AvatarURL returns the Gravatar URL for email, hashed with SHA-256 after
+// trimming and lowercasing as Gravatar requires.
+func (g Gravatar) AvatarURL(email string, size int) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	query := url.Values{"s": {strconv.Itoa(size)}}
+	if g.Default != "" {
+		query.Set("d", g.Default)
+	}
+	if g.Rating != "" {
+		query.Set("r", g.Rating)
+	}
+	return "https://www.gravatar.com/avatar/" + hex.EncodeToString(sum[:]) + "?" + query.Encode()
+}
+
+// avatar returns the URL of the avatar for email from Config.Avatars, left to
+// html/template to escape for the attribute it is in:
+//
+//	<img src="{{ avatar .Email 64 }}" width="64" height="64" alt="">
+func (t *Templates) avatar(email string, size int) (string, error) {
+	if size <= 0 {
+		return "", fmt.Errorf("avatar: size must be positive but got %d", size)
+	}
+	provider := t.config.Avatars
+	if provider == nil {
+		provider = Gravatar{}
+	}
+	return provider.AvatarURL(email, size), nil
+}
@@ -0,0 +1,76 @@
+package tmpls_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestAvatar(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		config      tmpls.Config
+		src         string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "should render gravatar urls of normalized emails",
+			src:  `<img src="{{ avatar . 64 }}">`,
+			data: "  Test@Example.com ",
+			expected: `<img src="https://www.gravatar.com/avatar/` +
+				`973dfe463ec85785f5f95af5ba3906eedb2d931c24e69824a89ea65dba4e813b?s=64">`,
+		},
+		{
+			name: "should escape gravatar options",
+			config: tmpls.Config{
+				Avatars: tmpls.Gravatar{Default: "https://example.com/a b.png", Rating: "pg"},
+			},
+			src:  `<img src="{{ avatar . 32 }}">`,
+			data: "test@example.com",
+			expected: `<img src="https://www.gravatar.com/avatar/` +
+				`973dfe463ec85785f5f95af5ba3906eedb2d931c24e69824a89ea65dba4e813b` +
+				`?d=https%3A%2F%2Fexample.com%2Fa&#43;b.png&amp;r=pg&amp;s=32">`,
+		},
+		{
+			name: "should use custom providers",
+			config: tmpls.Config{
+				Avatars: tmpls.AvatarProviderFunc(func(email string, size int) string {
+					return fmt.Sprintf("/avatars/%s?size=%d", email, size)
+				}),
+			},
+			src:      `<img src="{{ avatar . 48 }}">`,
+			data:     `"><script>`,
+			expected: `<img src="/avatars/%22%3e%3cscript%3e?size=48">`,
+		},
+		{
+			name:        "should fail on non positive sizes",
+			src:         `{{ avatar . 0 }}`,
+			data:        "test@example.com",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, test.config, test.src, test.data)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
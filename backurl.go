@@ -0,0 +1,61 @@
+package tmpls
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// backURL returns target, typically a return URL from a query param, if it
+// is relative without a host or has the host of the RenderContext's Request,
+// and otherwise fallback, "/" by default, so a crafted link can't send users
+// on to another site:
+//
+//	<a href="{{ backURL (.Request.URL.Query.Get "next") "/account" }}">Back</a>
+//
+// Backslashes and control characters are rejected since browsers read "/\"
+// like "//", the start of a host.
+func (t *Templates) backURL(ctx context.Context) any {
+	return func(target string, fallback ...string) (string, error) {
+		if len(fallback) > 1 {
+			return "", fmt.Errorf("backURL takes at most one fallback but got %d", len(fallback))
+		}
+		back := "/"
+		if len(fallback) == 1 {
+			back = fallback[0]
+		}
+		if target == "" {
+			return back, nil
+		}
+		if reason := t.offsite(ctx, target); reason != "" {
+			t.log().Warn("Replaced offsite return URL", "reason", reason)
+			return back, nil
+		}
+		return target, nil
+	}
+}
+
+// offsite returns why target might lead to another site, or "" if it can't.
+func (t *Templates) offsite(ctx context.Context, target string) string {
+	if strings.ContainsFunc(target, func(r rune) bool {
+		return r == '\\' || r < 0x20 || r == 0x7f
+	}) {
+		return "backslash or control character"
+	}
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return "unparsable"
+	}
+	if parsed.Scheme == "" && parsed.Host == "" && !strings.HasPrefix(target, "//") {
+		return ""
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "scheme or host without scheme"
+	}
+	rc := RenderContextFrom(ctx)
+	if rc == nil || rc.Request == nil || !strings.EqualFold(parsed.Host, rc.Request.Host) {
+		return "other host"
+	}
+	return ""
+}
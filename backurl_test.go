@@ -0,0 +1,103 @@
+package tmpls_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestBackURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		src      string
+		target   string
+		expected string
+	}{
+		{
+			name:     "should keep relative paths",
+			src:      `{{ backURL . }}`,
+			target:   "/orders?page=2#top",
+			expected: "/orders?page=2#top",
+		},
+		{
+			name:     "should keep relative paths without a leading slash",
+			src:      `{{ backURL . }}`,
+			target:   "settings",
+			expected: "settings",
+		},
+		{
+			name:     "should keep urls of the request's host",
+			src:      `{{ backURL . }}`,
+			target:   "https://example.com/orders",
+			expected: "https://example.com/orders",
+		},
+		{
+			name:     "should fall back for empty targets",
+			src:      `{{ backURL . "/account" }}`,
+			expected: "/account",
+		},
+		{
+			name:     "should fall back for other hosts",
+			src:      `{{ backURL . "/account" }}`,
+			target:   "https://evil.example/",
+			expected: "/account",
+		},
+		{
+			name:     "should fall back for scheme relative urls",
+			src:      `{{ backURL . }}`,
+			target:   "//evil.example/",
+			expected: "/",
+		},
+		{
+			name:     "should fall back for triple slashes",
+			src:      `{{ backURL . }}`,
+			target:   "///evil.example/",
+			expected: "/",
+		},
+		{
+			name:     "should fall back for backslashes",
+			src:      `{{ backURL . }}`,
+			target:   `/\evil.example/`,
+			expected: "/",
+		},
+		{
+			name:     "should fall back for control characters",
+			src:      `{{ backURL . }}`,
+			target:   "/\t/evil.example/",
+			expected: "/",
+		},
+		{
+			name:     "should fall back for other schemes",
+			src:      `{{ backURL . }}`,
+			target:   "javascript:alert(1)",
+			expected: "/",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, tmpls.Config{}, test.src)
+			ctx := tmpls.WithRenderContext(context.Background(), &tmpls.RenderContext{
+				Request: httptest.NewRequest(http.MethodGet, "https://example.com/login", nil),
+			})
+			output, err := templates.ExecuteContext(
+				ctx,
+				"page.html.tmpl",
+				"page.html.tmpl",
+				test.target,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
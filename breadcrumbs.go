@@ -0,0 +1,79 @@
+package tmpls
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+// Breadcrumb is one step in a trail from the site root to the current page.
+type Breadcrumb struct {
+	Name string
+	// URL may be empty for the current page.
+	URL string
+}
+
+// Breadcrumbs is a trail rendered by the breadcrumbs func, or ranged over by
+// a partial for custom markup. The last crumb is the current page.
+type Breadcrumbs []Breadcrumb
+
+// HTML renders the trail as an ordered list in a nav, marking the last crumb
+// as the current page.
+func (b Breadcrumbs) HTML() template.HTML {
+	if len(b) == 0 {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString(`<nav aria-label="Breadcrumb"><ol>`)
+	for i, crumb := range b {
+		name := template.HTMLEscapeString(crumb.Name)
+		switch {
+		case i == len(b)-1:
+			fmt.Fprintf(&s, `<li aria-current="page">%s</li>`, name)
+		case crumb.URL == "":
+			fmt.Fprintf(&s, `<li>%s</li>`, name)
+		default:
+			fmt.Fprintf(&s, `<li><a href="%s">%s</a></li>`, safeHref(crumb.URL), name)
+		}
+	}
+	s.WriteString(`</ol></nav>`)
+	return template.HTML(s.String())
+}
+
+// JSONLD renders the trail as a schema.org BreadcrumbList script for search
+// engines. Crumb URLs should be absolute.
+func (b Breadcrumbs) JSONLD() (template.HTML, error) {
+	type listItem struct {
+		Type     string `json:"@type"`
+		Position int    `json:"position"`
+		Name     string `json:"name"`
+		Item     string `json:"item,omitempty"`
+	}
+	items := make([]listItem, len(b))
+	for i, crumb := range b {
+		items[i] = listItem{Type: "ListItem", Position: i + 1, Name: crumb.Name, Item: crumb.URL}
+	}
+	// json.Marshal escapes <, > and &, so the script can't be closed early
+	data, err := json.Marshal(struct {
+		Context string     `json:"@context"`
+		Type    string     `json:"@type"`
+		Items   []listItem `json:"itemListElement"`
+	}{Context: "https://schema.org", Type: "BreadcrumbList", Items: items})
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(`<script type="application/ld+json">` + string(data) + `</script>`), nil
+}
+
+// safeHref escapes u for an href attribute, replacing it like html/template
+// does if it has a scheme other than http, https or mailto.
+func safeHref(u string) string {
+	if parsed, err := url.Parse(u); err != nil ||
+		(parsed.Scheme != "" && parsed.Scheme != "http" && parsed.Scheme != "https" &&
+			parsed.Scheme != "mailto") {
+		return "#ZgotmplZ"
+	}
+	return template.HTMLEscapeString(u)
+}
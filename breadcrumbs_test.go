@@ -0,0 +1,74 @@
+package tmpls_test
+
+import (
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestBreadcrumbs(t *testing.T) {
+	t.Parallel()
+
+	crumbs := tmpls.Breadcrumbs{
+		{Name: "Home", URL: "https://example.com/"},
+		{Name: "Bad", URL: "javascript:alert(1)"},
+		{Name: "Section"},
+		{Name: "Tom & Jerry", URL: "https://example.com/tom"},
+	}
+
+	tests := []struct {
+		name     string
+		src      string
+		data     any
+		expected string
+	}{
+		{
+			name: "should render trail",
+			src:  `{{ breadcrumbs . }}`,
+			data: crumbs,
+			expected: `<nav aria-label="Breadcrumb"><ol>` +
+				`<li><a href="https://example.com/">Home</a></li>` +
+				`<li><a href="#ZgotmplZ">Bad</a></li>` +
+				`<li>Section</li>` +
+				`<li aria-current="page">Tom &amp; Jerry</li>` +
+				`</ol></nav>`,
+		},
+		{
+			name: "should render json-ld",
+			src:  `{{ breadcrumbsJSONLD . }}`,
+			data: tmpls.Breadcrumbs{
+				{Name: "Home", URL: "https://example.com/"},
+				{Name: "</script>"},
+			},
+			expected: `<script type="application/ld+json">{"@context":"https://schema.org",` +
+				`"@type":"BreadcrumbList","itemListElement":[` +
+				`{"@type":"ListItem","position":1,"name":"Home","item":"https://example.com/"},` +
+				`{"@type":"ListItem","position":2,"name":"\u003c/script\u003e"}]}</script>`,
+		},
+		{
+			name:     "should render nothing for empty trail",
+			src:      `{{ breadcrumbs . }}`,
+			data:     tmpls.Breadcrumbs{},
+			expected: "",
+		},
+		{
+			name:     "should range in custom partial",
+			src:      `{{ range . }}{{ .Name }}/{{ end }}`,
+			data:     crumbs[:1],
+			expected: "Home/",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{}, test.src, test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package tmpls
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+)
+
+// BuildInfo describes the deployed build for the buildVersion and buildInfo
+// funcs, e.g. for footers and diagnostics pages.
+type BuildInfo struct {
+	// Version is the release, e.g. "v1.4.2".
+	Version string
+	// Revision is the VCS commit the binary was built from.
+	Revision string
+	// Time is when Revision was committed.
+	Time time.Time
+}
+
+// readBuildInfo returns the BuildInfo the Go toolchain stamped into the
+// binary, or a zero BuildInfo if there is none.
+func readBuildInfo() BuildInfo {
+	var info BuildInfo
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Version = build.Main.Version
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			info.Time, _ = time.Parse(time.RFC3339, setting.Value)
+		}
+	}
+	return info
+}
+
+// buildInfo returns Config.BuildInfo, read from the binary by default, or a
+// zero BuildInfo when Deterministic is set.
+func (t *Templates) buildInfo() BuildInfo {
+	switch {
+	case t.config.BuildInfo != nil:
+		return *t.config.BuildInfo
+	case t.config.Deterministic != nil:
+		return BuildInfo{}
+	default:
+		return readBuildInfo()
+	}
+}
+
+// readEnv reads the environment variables named by Config.Env once, so
+// templates see the values the process started with. Nothing is read when
+// Deterministic is set.
+func (t *Templates) readEnv() map[string]string {
+	env := make(map[string]string, len(t.config.Env))
+	for _, name := range t.config.Env {
+		if t.config.Deterministic == nil {
+			env[name] = os.Getenv(name)
+		} else {
+			env[name] = ""
+		}
+	}
+	return env
+}
+
+// env returns the environment variable name, failing unless Config.Env
+// allows it:
+//
+//	{{ if eq (env "FEATURE_PHASE") "beta" }}
+func (t *Templates) env(name string) (string, error) {
+	value, ok := t.envValues[name]
+	if !ok {
+		return "", fmt.Errorf("env: %q not in Config.Env", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,105 @@
+package tmpls_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestBuildInfo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		config   tmpls.Config
+		src      string
+		expected string
+	}{
+		{
+			name: "should render configured build info",
+			config: tmpls.Config{
+				BuildInfo: &tmpls.BuildInfo{
+					Version:  "v1.4.2",
+					Revision: "abc123",
+					Time:     time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			src: `{{ buildVersion }} {{ buildInfo.Revision }} ` +
+				`{{ buildInfo.Time.Format "2006-01-02" }}`,
+			expected: "v1.4.2 abc123 2026-05-01",
+		},
+		{
+			name:     "should render zero build info when deterministic",
+			config:   tmpls.Config{Deterministic: &tmpls.Deterministic{}},
+			src:      `[{{ buildVersion }}]`,
+			expected: "[]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			out, err := render(t, test.config, test.src, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, out)
+			}
+		})
+	}
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("TMPLS_FEATURE_PHASE", "beta")
+	t.Setenv("TMPLS_SECRET", "hunter2")
+
+	tests := []struct {
+		name        string
+		config      tmpls.Config
+		src         string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:     "should render allowed variables",
+			config:   tmpls.Config{Env: []string{"TMPLS_FEATURE_PHASE", "TMPLS_UNSET"}},
+			src:      `{{ env "TMPLS_FEATURE_PHASE" }}[{{ env "TMPLS_UNSET" }}]`,
+			expected: "beta[]",
+		},
+		{
+			name:        "should fail on variables not allowed",
+			config:      tmpls.Config{Env: []string{"TMPLS_FEATURE_PHASE"}},
+			src:         `{{ env "TMPLS_SECRET" }}`,
+			expectedErr: true,
+		},
+		{
+			name: "should not read variables when deterministic",
+			config: tmpls.Config{
+				Env:           []string{"TMPLS_FEATURE_PHASE"},
+				Deterministic: &tmpls.Deterministic{},
+			},
+			src:      `[{{ env "TMPLS_FEATURE_PHASE" }}]`,
+			expected: "[]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := render(t, test.config, test.src, nil)
+			if test.expectedErr {
+				if err == nil {
+					t.Fatalf("expected error but got %s", out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, out)
+			}
+		})
+	}
+}
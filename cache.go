@@ -0,0 +1,63 @@
+package tmpls
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"time"
+)
+
+// fragment is the cached output of a cache block.
+type fragment struct {
+	output  template.HTML
+	expires time.Time
+}
+
+// cache renders the content of a cache block at most once per ttl for key,
+// shared by every execution:
+//
+//	{{ cache "sidebar" 5m }}...{{ end }}
+//
+// The ttl is a duration or a string such as "5m". Failed renders aren't
+// cached, and nothing is cached while DisableCache is set. Cached fragments
+// are dropped whenever templates are reloaded.
+func (t *Templates) cache(ctx context.Context) any {
+	return func(key string, ttl any, name string, dot any) (template.HTML, error) {
+		executor, _, err := executorFrom(ctx)
+		if err != nil {
+			return "", err
+		}
+		duration, err := toDuration(ttl)
+		if err != nil {
+			return "", fmt.Errorf("cache %q: %w", key, err)
+		}
+		state := t.state.Load()
+		now := t.clock.Now()
+		if value, ok := state.fragments.Load(key); ok && !t.config.DisableCache {
+			if cached := value.(*fragment); now.Before(cached.expires) {
+				return cached.output, nil
+			}
+		}
+		var buffer bytes.Buffer
+		if err := executor.ExecuteTemplate(&buffer, name, dot); err != nil {
+			return "", err
+		}
+		output := template.HTML(buffer.String())
+		if !t.config.DisableCache {
+			state.fragments.Store(key, &fragment{output: output, expires: now.Add(duration)})
+		}
+		return output, nil
+	}
+}
+
+func toDuration(value any) (time.Duration, error) {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		return time.ParseDuration(v)
+	default:
+		return 0, fmt.Errorf("unsupported duration type %T", value)
+	}
+}
@@ -0,0 +1,128 @@
+package tmpls_test
+
+import (
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	var now atomic.Pointer[time.Time]
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	now.Store(&start)
+	newTemplates := func(disableCache bool) *tmpls.Templates {
+		t.Helper()
+		templates, err := tmpls.New(
+			tmpls.Config{
+				TemplatesFS: fstest.MapFS{
+					"common/empty.html.tmpl": &fstest.MapFile{},
+					"page.html.tmpl": &fstest.MapFile{
+						Data: []byte(`{{ cache "5m" 5m }}{{ .N }}{{ end }}` +
+							`{{ with .N }}{{- cache "k" "1h" -}} {{ . }} {{- end }}{{ end }}`),
+					},
+					"other.html.tmpl": &fstest.MapFile{
+						Data: []byte(`{{ cache "5m" .TTL }}{{ .N }}{{ end }}`),
+					},
+				},
+				CommonGlob:   "common/*.html.tmpl",
+				DisableCache: disableCache,
+				Clock: tmpls.ClockFunc(func() time.Time {
+					return *now.Load()
+				}),
+			},
+			slog.Default(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return templates
+	}
+	templates := newTemplates(false)
+
+	tests := []struct {
+		name        string
+		glob        string
+		data        map[string]any
+		advance     time.Duration
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should render on first execution",
+			glob:     "page.html.tmpl",
+			data:     map[string]any{"N": 1},
+			expected: "11",
+		},
+		{
+			name:     "should reuse fragments within ttl",
+			glob:     "page.html.tmpl",
+			data:     map[string]any{"N": 2},
+			advance:  4 * time.Minute,
+			expected: "11",
+		},
+		{
+			name:     "should share fragments by key",
+			glob:     "other.html.tmpl",
+			data:     map[string]any{"N": 3, "TTL": time.Minute},
+			expected: "1",
+		},
+		{
+			name:     "should render again once expired",
+			glob:     "page.html.tmpl",
+			data:     map[string]any{"N": 4},
+			advance:  time.Minute,
+			expected: "41",
+		},
+		{
+			name:        "should fail for invalid ttl",
+			glob:        "other.html.tmpl",
+			data:        map[string]any{"N": 5, "TTL": 5},
+			advance:     time.Hour,
+			expectError: true,
+		},
+	}
+
+	// steps share the cache, so run in order
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			advanced := now.Load().Add(test.advance)
+			now.Store(&advanced)
+			output, err := templates.Execute(test.glob, test.glob, test.data)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+
+	t.Run("should not cache with cache disabled", func(t *testing.T) {
+		templates := newTemplates(true)
+		for _, n := range []int{1, 2} {
+			output, err := templates.Execute("other.html.tmpl", "other.html.tmpl", map[string]any{
+				"N":   n,
+				"TTL": "1h",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := fmt.Sprint(n); output != expected {
+				t.Fatalf("expected %s but got %s", expected, output)
+			}
+		}
+	})
+}
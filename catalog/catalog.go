@@ -0,0 +1,129 @@
+// Package catalog renders templates with each of their fixture variants into
+// a static site, giving teams a living style guide of every component state.
+package catalog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/fivethirty/tmpls/fixture"
+)
+
+// Component is a template and its rendered states.
+type Component struct {
+	Template string
+	States   []State
+}
+
+// State is a component rendered with one fixture variant. Path is relative
+// to the catalog root and empty if rendering failed.
+type State struct {
+	Variant string
+	Path    string
+	Error   string
+}
+
+// Generate renders every template matching glob in config.TemplatesFS with
+// each of its fixture variants, or with nil data if it has none, and writes
+// the catalog to dir. Failed states are shown in the catalog and their errors
+// returned once it has been written.
+func Generate(dir string, config tmpls.Config, logger *slog.Logger, glob string) error {
+	templates, err := tmpls.New(config, logger)
+	if err != nil {
+		return err
+	}
+	files, err := fs.Glob(config.TemplatesFS, glob)
+	if err != nil {
+		return err
+	}
+
+	var components []Component
+	var errs []error
+	for _, file := range files {
+		if fixture.IsFixture(file) {
+			continue
+		}
+		variants, err := fixture.Variants(config.TemplatesFS, file)
+		if err != nil {
+			return err
+		}
+		if len(variants) == 0 {
+			variants = []fixture.Variant{{Name: fixture.DefaultVariant}}
+		}
+		component := Component{Template: file}
+		for _, variant := range variants {
+			state := State{Variant: variant.Name}
+			output, err := templates.Execute(file, path.Base(file), variant.Data)
+			if err != nil {
+				state.Error = err.Error()
+				errs = append(errs, fmt.Errorf("%s (%s): %w", file, variant.Name, err))
+			} else {
+				state.Path = path.Join("states", file, variant.Name+".html")
+				if err := write(dir, state.Path, []byte(output)); err != nil {
+					return err
+				}
+			}
+			component.States = append(component.States, state)
+		}
+		components = append(components, component)
+	}
+
+	var index bytes.Buffer
+	if err := indexTemplate.Execute(&index, components); err != nil {
+		return err
+	}
+	if err := write(dir, "index.html", index.Bytes()); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+func write(dir string, name string, data []byte) error {
+	file := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o644)
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!doctype html>
+<title>Component catalog</title>
+<style>
+  body { font-family: sans-serif; margin: 2rem; }
+  .states {
+    display: grid;
+    grid-template-columns: repeat(auto-fill, minmax(20rem, 1fr));
+    gap: 1rem;
+  }
+  figure { margin: 0; border: 1px solid #ddd; }
+  figcaption { padding: .5rem; background: #f5f5f5; }
+  iframe { width: 100%; height: 15rem; border: 0; }
+  pre { color: #b00020; white-space: pre-wrap; padding: .5rem; }
+</style>
+<h1>Component catalog</h1>
+{{- range . }}
+<section id="{{ .Template }}">
+  <h2>{{ .Template }}</h2>
+  <div class="states">
+  {{- range .States }}
+    <figure>
+      <figcaption>{{ .Variant }}</figcaption>
+      {{- if .Error }}
+      <pre>{{ .Error }}</pre>
+      {{- else }}
+      <iframe src="{{ .Path }}" loading="lazy"></iframe>
+      {{- end }}
+    </figure>
+  {{- end }}
+  </div>
+</section>
+{{- end }}
+`))
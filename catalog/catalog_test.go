@@ -0,0 +1,87 @@
+package catalog_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/fivethirty/tmpls/catalog"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	err := catalog.Generate(
+		dir,
+		tmpls.Config{
+			TemplatesFS: fstest.MapFS{
+				"common/empty.html.tmpl": &fstest.MapFile{},
+				"components/button.html.tmpl": &fstest.MapFile{
+					Data: []byte(`<button>{{ .Label }}</button>`),
+				},
+				"components/button.html.tmpl.json": &fstest.MapFile{
+					Data: []byte(`{"Label": "Save"}`),
+				},
+				"components/button.html.tmpl.long.json": &fstest.MapFile{
+					Data: []byte(`{"Label": "Save all changes"}`),
+				},
+				"components/broken.html.tmpl": &fstest.MapFile{
+					Data: []byte(`{{ .Missing.Field }}`),
+				},
+				"components/broken.html.tmpl.json": &fstest.MapFile{Data: []byte(`"text"`)},
+			},
+			CommonGlob: "common/*.html.tmpl",
+		},
+		slog.New(slog.DiscardHandler),
+		"components/*.html.tmpl",
+	)
+	if err == nil || !strings.Contains(err.Error(), "components/broken.html.tmpl") {
+		t.Fatalf("expected error for broken component but got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		contains []string
+	}{
+		{
+			name: "should link every state from index",
+			file: "index.html",
+			contains: []string{
+				`src="states/components/button.html.tmpl/default.html"`,
+				`src="states/components/button.html.tmpl/long.html"`,
+				"can&#39;t evaluate field Missing",
+			},
+		},
+		{
+			name:     "should render default state",
+			file:     "states/components/button.html.tmpl/default.html",
+			contains: []string{"<button>Save</button>"},
+		},
+		{
+			name:     "should render variant state",
+			file:     "states/components/button.html.tmpl/long.html",
+			contains: []string{"<button>Save all changes</button>"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			contents, err := os.ReadFile(filepath.Join(dir, test.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range test.contains {
+				if !strings.Contains(string(contents), s) {
+					t.Fatalf("expected %s to contain %s", contents, s)
+				}
+			}
+		})
+	}
+}
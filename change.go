@@ -0,0 +1,178 @@
+package tmpls
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+	"sync/atomic"
+)
+
+// ChangeEvent reports a file in TemplatesFS that changed while watching.
+type ChangeEvent struct {
+	// Path is relative to the root of TemplatesFS.
+	Path string
+	Op   ChangeOp
+}
+
+type ChangeOp int
+
+const (
+	Created ChangeOp = iota + 1
+	Modified
+	Removed
+)
+
+func (o ChangeOp) String() string {
+	switch o {
+	case Created:
+		return "created"
+	case Modified:
+		return "modified"
+	case Removed:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// changeBatch collects changes until they are flushed, keeping one per path.
+type changeBatch struct {
+	changes []ChangeEvent
+	index   map[string]int
+}
+
+func (b *changeBatch) add(change ChangeEvent) {
+	if b.index == nil {
+		b.index = map[string]int{}
+	}
+	i, ok := b.index[change.Path]
+	if !ok {
+		b.index[change.Path] = len(b.changes)
+		b.changes = append(b.changes, change)
+		return
+	}
+	// a file created and then written within a batch is still new
+	if b.changes[i].Op == Created && change.Op == Modified {
+		return
+	}
+	b.changes[i] = change
+}
+
+func (b *changeBatch) take() []ChangeEvent {
+	changes := b.changes
+	*b = changeBatch{}
+	return changes
+}
+
+// cachedExecutor is a parsed glob and the files it depends on.
+type cachedExecutor struct {
+	executor Executor
+	files    []string
+	// stale executors depend on changed files and are parsed again on their
+	// next use.
+	stale bool
+	// lastUsed orders executors for TenantCache eviction.
+	lastUsed atomic.Int64
+}
+
+// dependsOn reports whether changing file affects an executor, either because
+// it was parsed, is a directory containing parsed files, or may now match one
+// of the patterns it was parsed from.
+func (c *cachedExecutor) dependsOn(file string, patterns ...string) bool {
+	for _, f := range c.files {
+		if f == file || strings.HasPrefix(f, file+"/") {
+			return true
+		}
+	}
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, file); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidate marks the cached executors affected by changes stale so they
+// are parsed again, returning how many were marked. A created directory may
+// hold files matching any glob, so it marks everything. Creating or removing
+// files also drops the components discovered through ComponentsGlob, and any
+// change drops the fragments cached by cache blocks and the errors cached for
+// missing globs.
+func (t *Templates) invalidate(changes []ChangeEvent) int {
+	state := t.state.Load()
+	state.fragments.Clear()
+	state.missing.Clear()
+	state.responses.Clear()
+	for _, change := range changes {
+		if change.Op != Modified {
+			state.components.Store(nil)
+		}
+	}
+	all := false
+	for _, change := range changes {
+		if change.Op != Created {
+			continue
+		}
+		if info, err := fs.Stat(state.fsys, change.Path); err == nil && info.IsDir() {
+			all = true
+			break
+		}
+	}
+	invalidated := 0
+	state.executors.Range(func(key, value any) bool {
+		glob := key.(string)
+		executor := value.(*cachedExecutor)
+		if executor.stale {
+			return true
+		}
+		if !all && !t.affected(executor, glob, changes) {
+			return true
+		}
+		if state.executors.CompareAndSwap(key, executor, executor.with(true)) {
+			invalidated++
+		}
+		return true
+	})
+	return invalidated
+}
+
+// affected reports whether any of changes affects executor, parsed for glob.
+func (t *Templates) affected(executor *cachedExecutor, glob string, changes []ChangeEvent) bool {
+	for _, change := range changes {
+		if executor.dependsOn(change.Path, glob, t.config.CommonGlob, t.config.ComponentsGlob) {
+			return true
+		}
+	}
+	return false
+}
+
+// with returns a copy of c marked stale or not.
+func (c *cachedExecutor) with(stale bool) *cachedExecutor {
+	copied := &cachedExecutor{executor: c.executor, files: c.files, stale: stale}
+	copied.lastUsed.Store(c.lastUsed.Load())
+	return copied
+}
+
+// keepPrevious handles glob failing to parse again after changing. Unless
+// StrictReload is set or its files were removed, the error is logged and
+// previous is served until glob changes again. Otherwise the stale executor
+// is dropped.
+func (t *Templates) keepPrevious(
+	state *state,
+	glob string,
+	previous *cachedExecutor,
+	err error,
+) (Executor, bool) {
+	if t.config.StrictReload || errors.Is(err, fs.ErrNotExist) {
+		state.executors.CompareAndDelete(glob, previous)
+		return nil, false
+	}
+	t.log().Error(
+		"Parsing changed templates failed, serving previous templates",
+		"glob", glob,
+		"error", err,
+	)
+	state.executors.CompareAndSwap(glob, previous, previous.with(false))
+	return previous.executor, true
+}
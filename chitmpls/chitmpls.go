@@ -0,0 +1,60 @@
+// Package chitmpls serves tmpls templates as plain http.Handlers, for routers
+// such as chi that mount them directly.
+package chitmpls
+
+import (
+	"net/http"
+
+	"github.com/fivethirty/tmpls"
+)
+
+// Page is an http.Handler rendering a template with the data loaded for each
+// request, e.g. with chi:
+//
+//	r.Method(http.MethodGet, "/users/{id}", &chitmpls.Page{
+//		Templates: templates,
+//		Glob:      "pages/user.html.tmpl",
+//		Template:  "user.html.tmpl",
+//		Data: func(r *http.Request) (any, error) {
+//			return users.Get(r.Context(), chi.URLParam(r, "id"))
+//		},
+//	})
+type Page struct {
+	Templates *tmpls.Templates
+	Glob      string
+	Template  string
+	// Data loads the template's data for a request. If nil, the template is
+	// executed with nil data.
+	Data func(r *http.Request) (any, error)
+	// Status is written with the page. Defaults to 200.
+	Status int
+	// Error writes the response if loading data or rendering fails. Defaults
+	// to a plain 500 Internal Server Error.
+	Error func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+func (p *Page) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var data any
+	if p.Data != nil {
+		var err error
+		if data, err = p.Data(r); err != nil {
+			p.fail(w, r, err)
+			return
+		}
+	}
+	status := p.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if err := p.Templates.RenderRequest(w, r, status, p.Glob, p.Template, data); err != nil {
+		p.fail(w, r, err)
+	}
+}
+
+func (p *Page) fail(w http.ResponseWriter, r *http.Request, err error) {
+	if p.Error != nil {
+		p.Error(w, r, err)
+		return
+	}
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
@@ -0,0 +1,99 @@
+package chitmpls_test
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/fivethirty/tmpls/chitmpls"
+)
+
+func TestPage(t *testing.T) {
+	t.Parallel()
+
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: fstest.MapFS{
+				"common/empty.html.tmpl": &fstest.MapFile{},
+				"user.html.tmpl": &fstest.MapFile{
+					Data: []byte(`<p class="{{ active "/users/1" }}">{{ . }}</p>`),
+				},
+			},
+			CommonGlob: "common/*.html.tmpl",
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		page         *chitmpls.Page
+		expected     string
+		expectStatus int
+	}{
+		{
+			name: "should render loaded data",
+			page: &chitmpls.Page{
+				Template: "user.html.tmpl",
+				Data: func(r *http.Request) (any, error) {
+					return r.PathValue("id"), nil
+				},
+			},
+			expected:     `<p class="active">1</p>`,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "should render with status",
+			page:         &chitmpls.Page{Template: "user.html.tmpl", Status: http.StatusAccepted},
+			expected:     `<p class="active"></p>`,
+			expectStatus: http.StatusAccepted,
+		},
+		{
+			name: "should fail when loading fails",
+			page: &chitmpls.Page{
+				Template: "user.html.tmpl",
+				Data: func(*http.Request) (any, error) {
+					return nil, errors.New("not found")
+				},
+			},
+			expected:     "Internal Server Error\n",
+			expectStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "should call error handler",
+			page: &chitmpls.Page{
+				Template: "missing.html.tmpl",
+				Error: func(w http.ResponseWriter, _ *http.Request, err error) {
+					if tmpls.IsParseError(err) {
+						w.WriteHeader(http.StatusNotFound)
+					}
+				},
+			},
+			expectStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			test.page.Templates = templates
+			test.page.Glob = test.page.Template
+			mux := http.NewServeMux()
+			mux.Handle("GET /users/{id}", test.page)
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+			if recorder.Code != test.expectStatus {
+				t.Fatalf("expected status %d but got %d", test.expectStatus, recorder.Code)
+			}
+			if recorder.Body.String() != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+
+	"github.com/fivethirty/tmpls"
+)
+
+// check prints parse errors and references to undefined templates, failing
+// if there are any, and warns about defines no template references.
+func check(args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	templateFlags := newTemplateFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	templates, err := tmpls.New(
+		templateFlags.config(),
+		slog.New(slog.NewTextHandler(stderr, nil)),
+	)
+	if err != nil {
+		return err
+	}
+	var problems []string
+	validateErr := templates.Validate()
+	if joined, ok := validateErr.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			problems = append(problems, err.Error())
+		}
+	} else if validateErr != nil {
+		problems = append(problems, validateErr.Error())
+	}
+	undefined, unused, err := checkReferences(templates)
+	if err != nil {
+		return err
+	}
+	problems = append(problems, undefined...)
+	for _, problem := range problems {
+		fmt.Fprintln(stdout, problem)
+	}
+	for _, warning := range unused {
+		fmt.Fprintln(stdout, "warning: "+warning)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problems", len(problems))
+	}
+	return nil
+}
+
+// checkReferences returns the references to templates not defined in the
+// set of the page reaching them, for every page, and the defines no file
+// references. Files and pages that don't parse are skipped, since Validate
+// reports them.
+func checkReferences(templates *tmpls.Templates) (undefined []string, unused []string, err error) {
+	files, err := templates.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	referenced := map[string]bool{}
+	for _, file := range files {
+		for _, define := range file.Defines {
+			for _, name := range define.References {
+				referenced[name] = true
+			}
+		}
+	}
+
+	for _, page := range files {
+		if page.Shared || page.Err != nil {
+			continue
+		}
+		set, err := templates.DefinedIn(page.Path)
+		if err != nil {
+			continue
+		}
+		// later files override earlier definitions, like when executing
+		defined := map[string][]string{}
+		for _, file := range set {
+			for _, define := range file.Defines {
+				defined[define.Name] = define.References
+			}
+		}
+		reached := map[string]bool{}
+		var queue []string
+		for _, define := range page.Defines {
+			queue = append(queue, define.Name)
+		}
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			if reached[name] {
+				continue
+			}
+			reached[name] = true
+			for _, ref := range defined[name] {
+				if _, ok := defined[ref]; !ok {
+					undefined = append(undefined, fmt.Sprintf(
+						"%s: template %q referenced by %q is not defined",
+						page.Path,
+						ref,
+						name,
+					))
+					reached[ref] = true
+					continue
+				}
+				queue = append(queue, ref)
+			}
+		}
+	}
+
+	for _, file := range files {
+		for _, define := range file.Defines {
+			if define.Name != path.Base(file.Path) && !referenced[define.Name] {
+				unused = append(unused, fmt.Sprintf(
+					"%s: template %q is never referenced",
+					file.Path,
+					define.Name,
+				))
+			}
+		}
+	}
+	return undefined, unused, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		expected    []string
+		expectError bool
+	}{
+		{
+			name: "should pass valid templates",
+			files: map[string]string{
+				"common/layout.html.tmpl": `{{ define "layout" }}<main>{{ template "content" . }}` +
+					`</main>{{ end }}`,
+				"page.html.tmpl": `{{ template "layout" . }}{{ define "content" }}hi{{ end }}`,
+				"mail.txt.tmpl":  `Hi`,
+			},
+		},
+		{
+			name: "should report parse errors",
+			files: map[string]string{
+				"common/layout.html.tmpl": `{{ define "layout" }}{{ end }}`,
+				"page.html.tmpl":          `{{ template "layout" }}{{ if }}`,
+			},
+			expected:    []string{"page.html.tmpl"},
+			expectError: true,
+		},
+		{
+			name: "should report undefined templates in reach of pages",
+			files: map[string]string{
+				"common/layout.html.tmpl": `{{ define "layout" }}` +
+					`{{ template "content" . }}{{ end }}`,
+				"page.html.tmpl":  `{{ template "layout" . }}`,
+				"other.html.tmpl": `{{ template "missing" . }}`,
+			},
+			expected: []string{
+				`page.html.tmpl: template "content" referenced by "layout" is not defined`,
+				`other.html.tmpl: template "missing"` +
+					` referenced by "other.html.tmpl" is not defined`,
+			},
+			expectError: true,
+		},
+		{
+			name: "should warn about unused defines",
+			files: map[string]string{
+				"common/layout.html.tmpl": `{{ define "layout" }}` +
+					`{{ end }}{{ define "old" }}{{ end }}`,
+				"page.html.tmpl": `{{ template "layout" . }}`,
+			},
+			expected: []string{
+				`warning: common/layout.html.tmpl: template "old" is never referenced`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			for name, contents := range test.files {
+				file := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(file, []byte(contents), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var stdout, stderr bytes.Buffer
+			err := run([]string{"check", "-dir", dir}, &stdout, &stderr)
+			if test.expectError != (err != nil) {
+				t.Fatalf("expected error %t but got %v", test.expectError, err)
+			}
+			for _, expected := range test.expected {
+				if !strings.Contains(stdout.String(), expected) {
+					t.Fatalf("expected %s in %s", expected, stdout.String())
+				}
+			}
+			if len(test.expected) == 0 && stdout.Len() > 0 {
+				t.Fatalf("expected no output but got %s", stdout.String())
+			}
+		})
+	}
+}
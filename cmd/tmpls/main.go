@@ -0,0 +1,165 @@
+// Command tmpls is a development tool for tmpls templates.
+//
+// Usage:
+//
+//	tmpls serve [-dir dir] [-common glob] [-addr addr]
+//	tmpls render [-dir dir] [-common glob] [-data file] template
+//	tmpls catalog [-dir dir] [-common glob] [-out dir] glob
+//	tmpls check [-dir dir] [-common glob]
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/fivethirty/tmpls/catalog"
+	"github.com/fivethirty/tmpls/fixture"
+	"github.com/fivethirty/tmpls/preview"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(2)
+	}
+}
+
+const usage = `usage: tmpls <command> [flags]
+
+commands:
+  serve    serve template previews with live reload
+  render   print a rendered template
+  catalog  write a static catalog of fixture variants
+  check    report parse errors, undefined templates and unused defines`
+
+func run(args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "serve":
+		return serve(args[1:], stderr)
+	case "render":
+		return render(args[1:], stdout, stderr)
+	case "catalog":
+		return generateCatalog(args[1:], stderr)
+	case "check":
+		return check(args[1:], stdout, stderr)
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+type templateFlags struct {
+	dir    *string
+	common *string
+}
+
+func newTemplateFlags(flags *flag.FlagSet) templateFlags {
+	return templateFlags{
+		dir: flags.String("dir", ".", "directory containing templates"),
+		common: flags.String(
+			"common",
+			"common/*.html.tmpl",
+			"glob of templates shared by every page",
+		),
+	}
+}
+
+func (f templateFlags) config() tmpls.Config {
+	return tmpls.Config{
+		TemplatesFS: os.DirFS(*f.dir),
+		CommonGlob:  *f.common,
+	}
+}
+
+func serve(args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	templateFlags := newTemplateFlags(flags)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	logger := slog.New(slog.NewTextHandler(stderr, nil))
+	handler, err := preview.Handler(templateFlags.config(), logger)
+	if err != nil {
+		return err
+	}
+	logger.Info("Serving template previews", "addr", "http://"+*addr, "dir", *templateFlags.dir)
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server.ListenAndServe()
+}
+
+func render(args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	templateFlags := newTemplateFlags(flags)
+	dataFile := flags.String("data", "", "fixture to render with instead of the template's own")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: tmpls render [flags] template")
+	}
+	page := flags.Arg(0)
+
+	config := templateFlags.config()
+	templates, err := tmpls.New(config, slog.New(slog.NewTextHandler(stderr, nil)))
+	if err != nil {
+		return err
+	}
+	data, err := loadData(config, page, *dataFile)
+	if err != nil {
+		return err
+	}
+	output, err := templates.Execute(page, path.Base(page), data)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(stdout, output)
+	return err
+}
+
+func generateCatalog(args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("catalog", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	templateFlags := newTemplateFlags(flags)
+	out := flags.String("out", "catalog", "directory to write the catalog to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: tmpls catalog [flags] glob")
+	}
+	logger := slog.New(slog.NewTextHandler(stderr, nil))
+	return catalog.Generate(*out, templateFlags.config(), logger, flags.Arg(0))
+}
+
+// loadData decodes dataFile if set, or else the page's own fixture.
+func loadData(config tmpls.Config, page string, dataFile string) (any, error) {
+	if dataFile == "" {
+		data, _, err := fixture.Load(config.TemplatesFS, page)
+		return data, err
+	}
+	contents, err := os.ReadFile(dataFile)
+	if err != nil {
+		return nil, err
+	}
+	return fixture.Decode(dataFile, contents)
+}
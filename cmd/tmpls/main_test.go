@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "should require command", args: nil},
+		{name: "should reject unknown command", args: []string{"nope"}},
+		{name: "should reject unknown flag", args: []string{"serve", "-nope"}},
+		{name: "should require template to render", args: []string{"render"}},
+		{name: "should require glob for catalog", args: []string{"catalog"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := run(test.args, io.Discard, io.Discard)
+			if err == nil || errors.Is(err, flag.ErrHelp) {
+				t.Fatalf("expected error but got %v", err)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"common/layout.html.tmpl": `{{ define "layout" }}<main>{{ . }}</main>{{ end }}`,
+		"page.html.tmpl":          `{{ template "layout" .Title }}`,
+		"page.html.tmpl.json":     `{"Title": "From fixture"}`,
+		"other.json":              `{"Title": "From flag"}`,
+	}
+	for name, contents := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "should render with fixture",
+			args:     []string{"render", "-dir", dir, "page.html.tmpl"},
+			expected: "<main>From fixture</main>",
+		},
+		{
+			name: "should render with data flag",
+			args: []string{
+				"render", "-dir", dir, "-data", filepath.Join(dir, "other.json"), "page.html.tmpl",
+			},
+			expected: "<main>From flag</main>",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var stdout bytes.Buffer
+			if err := run(test.args, &stdout, io.Discard); err != nil {
+				t.Fatal(err)
+			}
+			if stdout.String() != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package tmpls
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Group is a group of items made by the groupBy func.
+type Group struct {
+	// Key is the field value shared by the group's items.
+	Key any
+	// Items are the group's items, in their original order.
+	Items []any
+}
+
+// collectionItems returns the items of a slice or array, or the values of a
+// map in key order as range visits them.
+func collectionItems(fn string, collection any) ([]any, error) {
+	v := indirectValue(reflect.ValueOf(collection))
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil, nil
+	case reflect.Slice, reflect.Array:
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = v.Index(i).Interface()
+		}
+		return items, nil
+	case reflect.Map:
+		keys := v.MapKeys()
+		var err error
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			c, compareErr := compareValues(a, b)
+			if compareErr != nil && err == nil {
+				err = compareErr
+			}
+			return c
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: map keys: %w", fn, err)
+		}
+		items := make([]any, len(keys))
+		for i, key := range keys {
+			items[i] = v.MapIndex(key).Interface()
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf(
+			"%s: unsupported type %T, expected a slice, array or map",
+			fn,
+			collection,
+		)
+	}
+}
+
+// chunk splits a collection into slices of size items, the last one holding
+// the rest, e.g. for the rows of a grid:
+//
+//	{{ range chunk 3 .Products }}<div class="row">…</div>{{ end }}
+func chunk(size int, collection any) ([][]any, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("chunk: size must be positive but got %d", size)
+	}
+	items, err := collectionItems("chunk", collection)
+	if err != nil {
+		return nil, err
+	}
+	chunks := [][]any{}
+	for c := range slices.Chunk(items, size) {
+		chunks = append(chunks, c)
+	}
+	return chunks, nil
+}
+
+// batch splits a collection into count slices whose sizes differ by at most
+// one, the first ones being larger, e.g. for columns:
+//
+//	{{ range batch 3 .Links }}<ul class="column">…</ul>{{ end }}
+func batch(count int, collection any) ([][]any, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("batch: count must be positive but got %d", count)
+	}
+	items, err := collectionItems("batch", collection)
+	if err != nil {
+		return nil, err
+	}
+	batches := make([][]any, count)
+	size, rest := len(items)/count, len(items)%count
+	for i := range batches {
+		n := size
+		if i < rest {
+			n++
+		}
+		batches[i] = items[:n:n]
+		items = items[n:]
+	}
+	return batches, nil
+}
+
+// groupBy groups the items of a collection by the value of field, a field or
+// map key path such as "Author.Name", in the order each value is first seen:
+//
+//	{{ range groupBy "Category" .Posts }}<h2>{{ .Key }}</h2>…{{ end }}
+func groupBy(field string, collection any) ([]Group, error) {
+	items, err := collectionItems("groupBy", collection)
+	if err != nil {
+		return nil, err
+	}
+	groups := []Group{}
+	index := map[any]int{}
+	for i, item := range items {
+		key, err := itemField("groupBy", field, i, item)
+		if err != nil {
+			return nil, err
+		}
+		var k any
+		if key.IsValid() {
+			if !key.Type().Comparable() {
+				return nil, fmt.Errorf("groupBy: field %q of item %d has uncomparable type %s",
+					field, i, key.Type())
+			}
+			k = key.Interface()
+		}
+		j, ok := index[k]
+		if !ok {
+			j = len(groups)
+			index[k] = j
+			groups = append(groups, Group{Key: k})
+		}
+		groups[j].Items = append(groups[j].Items, item)
+	}
+	return groups, nil
+}
+
+// sortBy returns the items of a collection stably sorted by field, a field or
+// map key path, descending if it starts with "-":
+//
+//	{{ range sortBy "-Price" .Products }}
+//
+// Fields must be numbers, strings, bools or times of the same kind.
+func sortBy(field string, collection any) ([]any, error) {
+	items, err := collectionItems("sortBy", collection)
+	if err != nil {
+		return nil, err
+	}
+	path, descending := strings.CutPrefix(field, "-")
+	keys := make([]reflect.Value, len(items))
+	for i, item := range items {
+		if keys[i], err = itemField("sortBy", path, i, item); err != nil {
+			return nil, err
+		}
+	}
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		c, compareErr := compareValues(keys[a], keys[b])
+		if compareErr != nil && err == nil {
+			err = fmt.Errorf("sortBy: field %q: %w", path, compareErr)
+		}
+		if descending {
+			return -c
+		}
+		return c
+	})
+	if err != nil {
+		return nil, err
+	}
+	sorted := make([]any, len(items))
+	for i, j := range order {
+		sorted[i] = items[j]
+	}
+	return sorted, nil
+}
+
+// first returns the first item of a collection, or nil if it is empty, so
+// templates needn't guard index with len:
+//
+//	{{ with first .Results }}Top result: {{ .Title }}{{ end }}
+func first(collection any) (any, error) {
+	items, err := collectionItems("first", collection)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+// last returns the last item of a collection, or nil if it is empty.
+func last(collection any) (any, error) {
+	items, err := collectionItems("last", collection)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[len(items)-1], nil
+}
+
+// indexOf returns the index of the first item of a slice or array equal to
+// value, or of value in a string, or -1.
+func indexOf(value any, collection any) (int, error) {
+	return index("indexOf", value, collection)
+}
+
+func index(fn string, value any, collection any) (int, error) {
+	if s, ok := collection.(string); ok {
+		sub, ok := value.(string)
+		if !ok {
+			return -1, fmt.Errorf("%s: can't find %T in a string", fn, value)
+		}
+		return strings.Index(s, sub), nil
+	}
+	if v := indirectValue(reflect.ValueOf(collection)); v.Kind() == reflect.Map {
+		return -1, fmt.Errorf("%s: unsupported type %T, expected a slice, array or string",
+			fn, collection)
+	}
+	items, err := collectionItems(fn, collection)
+	if err != nil {
+		return -1, err
+	}
+	return slices.IndexFunc(items, func(item any) bool {
+		return valuesEqual(item, value)
+	}), nil
+}
+
+// contains reports whether a slice or array has an item equal to value, a
+// map has value as a key, or a string has value as a substring:
+//
+//	{{ if contains "admin" .User.Roles }}
+func contains(value any, collection any) (bool, error) {
+	v := indirectValue(reflect.ValueOf(collection))
+	if v.Kind() != reflect.Map {
+		i, err := index("contains", value, collection)
+		return i >= 0, err
+	}
+	for _, key := range v.MapKeys() {
+		if valuesEqual(key.Interface(), value) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// valuesEqual reports whether a and b are equal, comparing numbers of
+// different types by value, e.g. an int literal with an int64 field.
+func valuesEqual(a any, b any) bool {
+	if c, err := compareValues(reflect.ValueOf(a), reflect.ValueOf(b)); err == nil {
+		return c == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// itemField returns the value at field in item, the i-th of a collection, or
+// the zero Value if a pointer on the way is nil.
+func itemField(fn string, field string, i int, item any) (reflect.Value, error) {
+	v := reflect.ValueOf(item)
+	for name := range strings.SplitSeq(field, ".") {
+		if v = indirectValue(v); !v.IsValid() {
+			return v, nil
+		}
+		var ok bool
+		switch v.Kind() {
+		case reflect.Map:
+			if ok = v.Type().Key().Kind() == reflect.String; ok {
+				v = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+				ok = v.IsValid()
+			}
+		case reflect.Struct:
+			f, found := v.Type().FieldByName(name)
+			if ok = found && f.IsExported(); ok {
+				v = v.FieldByIndex(f.Index)
+			}
+		}
+		if !ok {
+			err := fmt.Errorf("%s: item %d (%T) has no field %q", fn, i, item, field)
+			return reflect.Value{}, err
+		}
+	}
+	return v, nil
+}
+
+// compareValues orders two numbers, strings, bools or times, failing if they
+// are of other or different kinds. Numbers of different kinds, such as an int
+// and a uint or float, are compared by value.
+func compareValues(a, b reflect.Value) (int, error) {
+	a, b = indirectValue(a), indirectValue(b)
+	switch {
+	case !a.IsValid() || !b.IsValid():
+		// nil pointers sort first
+		return cmp.Compare(boolInt(a.IsValid()), boolInt(b.IsValid())), nil
+	case a.Type() == timeType && b.Type() == timeType:
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time)), nil
+	case a.CanInt() && b.CanInt():
+		return cmp.Compare(a.Int(), b.Int()), nil
+	case a.CanUint() && b.CanUint():
+		return cmp.Compare(a.Uint(), b.Uint()), nil
+	case a.CanFloat() && b.CanFloat():
+		return cmp.Compare(a.Float(), b.Float()), nil
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return cmp.Compare(a.String(), b.String()), nil
+	case a.Kind() == reflect.Bool && b.Kind() == reflect.Bool:
+		return cmp.Compare(boolInt(a.Bool()), boolInt(b.Bool())), nil
+	case isNumber(a) && isNumber(b):
+		return compareNumbers(a, b), nil
+	default:
+		return 0, fmt.Errorf("can't compare %s with %s", a.Type(), b.Type())
+	}
+}
+
+// compareNumbers orders numbers of different kinds, normalized by toNumber.
+func compareNumbers(a, b reflect.Value) int {
+	x, xErr := toNumber(a.Interface())
+	y, yErr := toNumber(b.Interface())
+	switch {
+	case xErr != nil:
+		// only uints beyond int64 overflow
+		return compareOverflow(a, b)
+	case yErr != nil:
+		return -compareOverflow(b, a)
+	case x.isFloat || y.isFloat:
+		return cmp.Compare(x.float(), y.float())
+	default:
+		return cmp.Compare(x.i, y.i)
+	}
+}
+
+// compareOverflow orders a, a uint beyond int64, with b, a signed int or
+// float.
+func compareOverflow(a, b reflect.Value) int {
+	if b.CanFloat() {
+		return cmp.Compare(float64(a.Uint()), b.Float())
+	}
+	return 1
+}
+
+// indirectValue follows pointers and interfaces, returning the zero Value
+// for nil.
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,209 @@
+package tmpls_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+type product struct {
+	Name     string
+	Category string
+	Price    float64
+	Added    time.Time
+	Supplier *supplier
+}
+
+type supplier struct {
+	Name string
+}
+
+var products = []product{
+	{Name: "pen", Category: "office", Price: 2, Added: time.Unix(300, 0)},
+	{Name: "mug", Category: "kitchen", Price: 8, Added: time.Unix(100, 0)},
+	{Name: "pad", Category: "office", Price: 5, Added: time.Unix(200, 0)},
+	{Name: "cup", Category: "kitchen", Price: 5, Added: time.Unix(400, 0)},
+	{Name: "ink", Category: "office", Price: 3, Added: time.Unix(500, 0)},
+}
+
+func TestCollectionFuncs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "should chunk",
+			src: `{{ range chunk 2 . }}[{{ range . }}{{ .Name }} {{ end }}]{{ end }}` +
+				`{{ len (chunk 2 nil) }}`,
+			data:     products,
+			expected: "[pen mug ][pad cup ][ink ]0",
+		},
+		{
+			name:        "should fail to chunk by zero",
+			src:         `{{ chunk 0 . }}`,
+			data:        products,
+			expectError: true,
+		},
+		{
+			name:        "should fail to chunk non collections",
+			src:         `{{ chunk 2 . }}`,
+			data:        "pens",
+			expectError: true,
+		},
+		{
+			name:     "should batch into columns",
+			src:      `{{ range batch 3 . }}[{{ range . }}{{ .Name }} {{ end }}]{{ end }}`,
+			data:     products,
+			expected: "[pen mug ][pad cup ][ink ]",
+		},
+		{
+			name:     "should batch fewer items than columns",
+			src:      `{{ range batch 3 . }}[{{ range . }}{{ . }}{{ end }}]{{ end }}`,
+			data:     []int{1},
+			expected: "[1][][]",
+		},
+		{
+			name: "should group by field in first seen order",
+			src: `{{ range groupBy "Category" . }}{{ .Key }}:` +
+				`{{ range .Items }} {{ .Name }}{{ end }};{{ end }}`,
+			data:     products,
+			expected: "office: pen pad ink;kitchen: mug cup;",
+		},
+		{
+			name: "should group map values in key order",
+			src: `{{ range groupBy "kind" . }}{{ .Key }}:` +
+				`{{ range .Items }} {{ .name }}{{ end }};{{ end }}`,
+			data: map[string]map[string]string{
+				"b": {"kind": "x", "name": "b"},
+				"a": {"kind": "y", "name": "a"},
+				"c": {"kind": "x", "name": "c"},
+			},
+			expected: "y: a;x: b c;",
+		},
+		{
+			name:        "should fail to group by missing fields",
+			src:         `{{ groupBy "Color" . }}`,
+			data:        products,
+			expectError: true,
+		},
+		{
+			name:     "should sort by field stably",
+			src:      `{{ range sortBy "Price" . }}{{ .Name }} {{ end }}`,
+			data:     products,
+			expected: "pen ink pad cup mug ",
+		},
+		{
+			name:     "should sort descending",
+			src:      `{{ range sortBy "-Price" . }}{{ .Name }} {{ end }}`,
+			data:     products,
+			expected: "mug pad cup ink pen ",
+		},
+		{
+			name:     "should sort by time",
+			src:      `{{ range sortBy "Added" . }}{{ .Name }} {{ end }}`,
+			data:     products,
+			expected: "mug pad pen cup ink ",
+		},
+		{
+			name: "should sort by nested fields with nil first",
+			src:  `{{ range sortBy "Supplier.Name" . }}{{ .Name }} {{ end }}`,
+			data: []product{
+				{Name: "pen", Supplier: &supplier{Name: "b"}},
+				{Name: "mug", Supplier: &supplier{Name: "a"}},
+				{Name: "pad"},
+			},
+			expected: "pad mug pen ",
+		},
+		{
+			name:        "should fail to sort mixed types",
+			src:         `{{ sortBy "v" . }}`,
+			data:        []map[string]any{{"v": 1}, {"v": "a"}},
+			expectError: true,
+		},
+		{
+			name:     "should return first and last items",
+			src:      `{{ (first .).Name }} {{ (last .).Name }}`,
+			data:     products,
+			expected: "pen ink",
+		},
+		{
+			name:     "should return nil first and last items of empty collections",
+			src:      `{{ with first . }}x{{ else }}none{{ end }} {{ last nil }}`,
+			data:     []product{},
+			expected: "none ",
+		},
+		{
+			name:     "should find index of items and substrings",
+			src:      `{{ indexOf 3 . }} {{ indexOf 9 . }} {{ indexOf "lo" "hello" }}`,
+			data:     []int64{1, 2, 3},
+			expected: "2 -1 3",
+		},
+		{
+			name:        "should fail to find index in maps",
+			src:         `{{ indexOf "a" . }}`,
+			data:        map[string]int{"a": 1},
+			expectError: true,
+		},
+		{
+			name: "should check slices, map keys and strings contain values",
+			src: `{{ contains "admin" .roles }} {{ contains "root" .roles }} ` +
+				`{{ contains "beta" .flags }} {{ contains "ell" "hello" }}`,
+			data: map[string]any{
+				"roles": []string{"user", "admin"},
+				"flags": map[string]bool{"beta": false},
+			},
+			expected: "true false true true",
+		},
+		{
+			name: "should compare numbers of different kinds by value",
+			src: `{{ contains 1 .uints }} {{ contains 2.0 .ints }} {{ contains 2.5 .ints }} ` +
+				`{{ indexOf 3 .floats }} {{ contains -1 .big }}`,
+			data: map[string]any{
+				"uints":  []uint{1, 2},
+				"ints":   []int8{1, 2},
+				"floats": []float32{1, 3},
+				"big":    []uint64{1 << 63},
+			},
+			expected: "true true false 1 false",
+		},
+		{
+			name: "should sort numbers of different kinds by value",
+			src:  `{{ range sortBy "n" . }}{{ .n }} {{ end }}`,
+			data: []map[string]any{
+				{"n": uint(3)}, {"n": 1.5}, {"n": -2}, {"n": uint64(1 << 63)},
+			},
+			expected: "-2 1.5 3 9223372036854775808 ",
+		},
+		{
+			name:        "should fail to check non collections contain values",
+			src:         `{{ contains 1 . }}`,
+			data:        1,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{}, test.src, test.data)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package tmpls
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// rgb is a color with channels from 0 to 1.
+type rgb struct {
+	r, g, b float64
+}
+
+// parseHexColor parses a "#rgb" or "#rrggbb" color, with or without the #.
+func parseHexColor(s string) (rgb, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return rgb{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return rgb{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	return rgb{
+		r: float64(n>>16&0xff) / 255,
+		g: float64(n>>8&0xff) / 255,
+		b: float64(n&0xff) / 255,
+	}, nil
+}
+
+func (c rgb) hex() string {
+	channel := func(v float64) int {
+		// nudged so halves lost to float error still round up
+		return int(math.Round(min(max(v, 0), 1)*255 + 1e-9))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", channel(c.r), channel(c.g), channel(c.b))
+}
+
+// hsl returns the hue in turns and saturation and lightness of c.
+func (c rgb) hsl() (h, s, l float64) {
+	high, low := max(c.r, c.g, c.b), min(c.r, c.g, c.b)
+	l = (high + low) / 2
+	if high == low {
+		return 0, 0, l
+	}
+	d := high - low
+	if l > 0.5 {
+		s = d / (2 - high - low)
+	} else {
+		s = d / (high + low)
+	}
+	switch high {
+	case c.r:
+		h = (c.g - c.b) / d
+		if c.g < c.b {
+			h += 6
+		}
+	case c.g:
+		h = (c.b-c.r)/d + 2
+	default:
+		h = (c.r-c.g)/d + 4
+	}
+	return h / 6, s, l
+}
+
+func fromHSL(h, s, l float64) rgb {
+	if s == 0 {
+		return rgb{l, l, l}
+	}
+	var q float64
+	if l < 0.5 {
+		q = l * (1 + s)
+	} else {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+	channel := func(t float64) float64 {
+		t -= math.Floor(t)
+		switch {
+		case t < 1.0/6:
+			return p + (q-p)*6*t
+		case t < 1.0/2:
+			return q
+		case t < 2.0/3:
+			return p + (q-p)*(2.0/3-t)*6
+		default:
+			return p
+		}
+	}
+	return rgb{channel(h + 1.0/3), channel(h), channel(h - 1.0/3)}
+}
+
+// adjustLightness adds amount, from -1 to 1, to the HSL lightness of color.
+func adjustLightness(fn string, amount float64, color string) (string, error) {
+	c, err := parseHexColor(color)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+	h, s, l := c.hsl()
+	return fromHSL(h, s, min(max(l+amount, 0), 1)).hex(), nil
+}
+
+// lighten raises the HSL lightness of a hex color by amount, from 0 to 1,
+// like Sass's lighten with a percentage:
+//
+//	style="background: {{ lighten 0.2 .Theme.Brand }}"
+func lighten(amount float64, color string) (string, error) {
+	return adjustLightness("lighten", amount, color)
+}
+
+// darken lowers the HSL lightness of a hex color by amount, from 0 to 1.
+func darken(amount float64, color string) (string, error) {
+	return adjustLightness("darken", -amount, color)
+}
+
+// luminance returns the WCAG relative luminance of c.
+func (c rgb) luminance() float64 {
+	linear := func(v float64) float64 {
+		if v <= 0.04045 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(c.r) + 0.7152*linear(c.g) + 0.0722*linear(c.b)
+}
+
+func ratio(a, b rgb) float64 {
+	la, lb := a.luminance(), b.luminance()
+	return (max(la, lb) + 0.05) / (min(la, lb) + 0.05)
+}
+
+// contrastRatio returns the WCAG contrast ratio of two hex colors, from 1 to
+// 21. Body text needs at least 4.5 to meet level AA.
+func contrastRatio(a string, b string) (float64, error) {
+	ca, err := parseHexColor(a)
+	if err != nil {
+		return 0, fmt.Errorf("contrastRatio: %w", err)
+	}
+	cb, err := parseHexColor(b)
+	if err != nil {
+		return 0, fmt.Errorf("contrastRatio: %w", err)
+	}
+	return ratio(ca, cb), nil
+}
+
+// contrastText returns black or white, whichever contrasts more with a hex
+// background color, for text on user-chosen colors:
+//
+//	style="background: {{ .Color }}; color: {{ contrastText .Color }}"
+func contrastText(background string) (string, error) {
+	c, err := parseHexColor(background)
+	if err != nil {
+		return "", fmt.Errorf("contrastText: %w", err)
+	}
+	black, white := rgb{0, 0, 0}, rgb{1, 1, 1}
+	if ratio(c, black) >= ratio(c, white) {
+		return black.hex(), nil
+	}
+	return white.hex(), nil
+}
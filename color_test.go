@@ -0,0 +1,70 @@
+package tmpls_test
+
+import (
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestColorFuncs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "should lighten and darken",
+			src: `{{ lighten 0.2 "#336699" }} {{ darken 0.2 "#336699" }} ` +
+				`{{ lighten 1 "f00" }}`,
+			expected: "#6699cc #1a334d #ffffff",
+		},
+		{
+			name:     "should keep grays gray",
+			src:      `{{ lighten 0.1 "#808080" }} {{ darken 0.6 "#808080" }}`,
+			expected: "#9a9a9a #000000",
+		},
+		{
+			name:     "should pick contrasting text",
+			src:      `{{ contrastText "#ffeb3b" }} {{ contrastText "#1a237e" }}`,
+			expected: "#000000 #ffffff",
+		},
+		{
+			name: "should compute contrast ratios",
+			src: `{{ printf "%.1f" (contrastRatio "#000" "#fff") }} ` +
+				`{{ contrastRatio "#777" "777777" }}`,
+			expected: "21.0 1",
+		},
+		{
+			name:        "should fail on invalid colors",
+			src:         `{{ lighten 0.1 "#12345" }}`,
+			expectError: true,
+		},
+		{
+			name:        "should fail on non hex colors",
+			src:         `{{ contrastText "#zzzzzz" }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{}, test.src, nil)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
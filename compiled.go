@@ -0,0 +1,31 @@
+package tmpls
+
+import "io"
+
+// CompiledKey identifies a template compiled ahead of time by the glob and
+// template name it is executed with.
+type CompiledKey struct {
+	Glob     string
+	Template string
+}
+
+// CompiledFunc renders a template compiled ahead of time to Go, typically
+// generated by the tmplsgen package.
+type CompiledFunc func(w io.Writer, data any) error
+
+// Parse parses glob together with CommonGlob and every func into a new
+// template set, bypassing the cache. It is intended for tooling such as code
+// generators that need the same set Execute would use.
+func (t *Templates) Parse(glob string) (Executor, error) {
+	tmpl, _, err := t.newExecutor(t.state.Load().fsys, glob)
+	return tmpl, err
+}
+
+func (t *Templates) compiled(glob string, templateName string) (CompiledFunc, bool) {
+	// compiled templates would go stale while hot-swapping
+	if t.config.Compiled == nil || t.config.DisableCache {
+		return nil, false
+	}
+	fn, ok := t.config.Compiled[CompiledKey{Glob: glob, Template: templateName}]
+	return fn, ok
+}
@@ -0,0 +1,145 @@
+package tmpls_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestCompiled(t *testing.T) {
+	t.Parallel()
+
+	compiled := map[tmpls.CompiledKey]tmpls.CompiledFunc{
+		{Glob: "test.html.tmpl", Template: "test.html.tmpl"}: func(w io.Writer, data any) error {
+			_, err := io.WriteString(w, "compiled "+data.(templateData).Text)
+			return err
+		},
+		{Glob: "other.html.tmpl", Template: "other.html.tmpl"}: func(io.Writer, any) error {
+			return errors.New("failed")
+		},
+	}
+
+	tests := []struct {
+		name         string
+		disableCache bool
+		glob         string
+		expected     string
+		expectError  bool
+	}{
+		{
+			name:     "should execute compiled template",
+			glob:     "test.html.tmpl",
+			expected: "compiled world",
+		},
+		{
+			name:         "should ignore compiled templates when cache is disabled",
+			disableCache: true,
+			glob:         "test.html.tmpl",
+			expected:     "hello world",
+		},
+		{
+			name:        "should return compiled template errors",
+			glob:        "other.html.tmpl",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpls, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS:  testFS,
+					CommonGlob:   "common/*.html.tmpl",
+					DisableCache: test.disableCache,
+					Compiled:     compiled,
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			output, err := tmpls.Execute(test.glob, test.glob, templateData{Text: "world"})
+			if test.expectError != (err != nil) {
+				t.Fatalf("expectError=%v, got %v", test.expectError, err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+type executeFault struct{}
+
+func (executeFault) BeforeParse(string) error {
+	return nil
+}
+
+func (executeFault) BeforeExecute(context.Context, string, string) error {
+	return errors.New("injected")
+}
+
+func TestCompiledFallbacks(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"page.html.tmpl": &fstest.MapFile{Data: []byte(`interpreted`)},
+		"common/error.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ define "error" }}oops{{ end }}`),
+		},
+	}
+	compiled := map[tmpls.CompiledKey]tmpls.CompiledFunc{
+		{Glob: "page.html.tmpl", Template: "page.html.tmpl"}: func(io.Writer, any) error {
+			return errors.New("failed")
+		},
+	}
+
+	tests := []struct {
+		name     string
+		faults   tmpls.FaultInjector
+		expected string
+	}{
+		{
+			name:     "should render ErrorTemplate for compiled errors",
+			expected: "oops",
+		},
+		{
+			name:     "should inject faults before compiled templates",
+			faults:   executeFault{},
+			expected: "oops",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS:   fsys,
+					CommonGlob:    "common/*.html.tmpl",
+					Compiled:      compiled,
+					ErrorTemplate: "error",
+					Faults:        test.faults,
+				},
+				slog.New(slog.DiscardHandler),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package tmpls
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// Component is a partial rendered by the component func, with its props
+// checked against a Go struct type.
+type Component struct {
+	// Template names the partial, usually defined in CommonGlob. Defaults to
+	// the component's name.
+	Template string
+	// Props is a value of the component's props struct, e.g. ButtonProps{}.
+	// Props given as a map or key-value pairs are converted to it, fields
+	// tagged `tmpls:"required"` must be non-zero, and props of any other
+	// type are rejected. If nil, props are passed to the partial unchecked.
+	Props any
+}
+
+// component renders the component name with props, given as one value or as
+// key-value pairs:
+//
+//	{{ component "Button" "Label" "Save" "Primary" true }}
+func (t *Templates) component(ctx context.Context) any {
+	return func(name string, args ...any) (template.HTML, error) {
+		executor, _, err := executorFrom(ctx)
+		if err != nil {
+			return "", err
+		}
+		component, ok, err := t.lookupComponent(name)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", fmt.Errorf("unknown component %q", name)
+		}
+		props, err := componentArgs(args)
+		if err != nil {
+			return "", fmt.Errorf("component %q: %w", name, err)
+		}
+		if component.Props != nil {
+			props, err = convertProps(reflect.TypeOf(component.Props), props)
+			if err != nil {
+				return "", fmt.Errorf("component %q: %w", name, err)
+			}
+		}
+		templateName := component.Template
+		if templateName == "" {
+			templateName = name
+		}
+		var buffer bytes.Buffer
+		if err := executor.ExecuteTemplate(&buffer, templateName, props); err != nil {
+			return "", err
+		}
+		return template.HTML(buffer.String()), nil
+	}
+}
+
+// lookupComponent returns the component registered as name in
+// Config.Components, or else discovered through ComponentsGlob.
+func (t *Templates) lookupComponent(name string) (Component, bool, error) {
+	if component, ok := t.config.Components[name]; ok {
+		return component, true, nil
+	}
+	if t.config.ComponentsGlob == "" {
+		return Component{}, false, nil
+	}
+	state := t.state.Load()
+	components := state.components.Load()
+	if components == nil || t.config.DisableCache {
+		discovered, err := discoverComponents(state.fsys, t.config.ComponentsGlob)
+		if err != nil {
+			return Component{}, false, err
+		}
+		components = &discovered
+		state.components.Store(components)
+	}
+	component, ok := (*components)[name]
+	return component, ok, nil
+}
+
+// discoverComponents registers the files matching glob under their base
+// names without extensions, rendering each file's own template.
+func discoverComponents(fsys fs.FS, glob string) (map[string]Component, error) {
+	matches, err := fs.Glob(fsys, glob)
+	if err != nil {
+		return nil, err
+	}
+	components := make(map[string]Component, len(matches))
+	for _, match := range matches {
+		base := path.Base(match)
+		name, _, _ := strings.Cut(base, ".")
+		components[name] = Component{Template: base}
+	}
+	return components, nil
+}
+
+// componentArgs returns a single argument as is, and pairs of arguments as a
+// map.
+func componentArgs(args []any) (any, error) {
+	switch len(args) {
+	case 0:
+		return nil, nil
+	case 1:
+		return args[0], nil
+	default:
+		return dict(args...)
+	}
+}
+
+// convertProps converts props to the struct typ, or a pointer to it, and
+// checks its required fields are set.
+func convertProps(typ reflect.Type, props any) (any, error) {
+	structType := typ
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("props type %s is not a struct", typ)
+	}
+	value := reflect.New(structType).Elem()
+	given := reflect.ValueOf(props)
+	switch {
+	case props == nil:
+	case given.Type() == structType:
+		value.Set(given)
+	case given.Type() == reflect.PointerTo(structType):
+		if !given.IsNil() {
+			value.Set(given.Elem())
+		}
+	case given.Kind() == reflect.Map && given.Type().Key().Kind() == reflect.String:
+		if err := setProps(value, given); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("props should be %s but are %T", structType, props)
+	}
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+		if field.Tag.Get("tmpls") == "required" && value.Field(i).IsZero() {
+			return nil, &PropError{Prop: field.Name, Expected: field.Type.String()}
+		}
+	}
+	if typ.Kind() == reflect.Pointer {
+		return value.Addr().Interface(), nil
+	}
+	return value.Interface(), nil
+}
+
+// setProps sets the fields of value from the entries of props, converting
+// between numeric types.
+func setProps(value reflect.Value, props reflect.Value) error {
+	iter := props.MapRange()
+	for iter.Next() {
+		name := iter.Key().String()
+		field, ok := value.Type().FieldByName(name)
+		if !ok || !field.IsExported() || len(field.Index) != 1 {
+			return fmt.Errorf("unknown prop %q", name)
+		}
+		prop := iter.Value()
+		for prop.Kind() == reflect.Interface && !prop.IsNil() {
+			prop = prop.Elem()
+		}
+		if prop.Kind() == reflect.Interface {
+			continue
+		}
+		switch {
+		case prop.Type().AssignableTo(field.Type):
+		case isNumber(prop) && isNumber(reflect.New(field.Type).Elem()):
+			prop = prop.Convert(field.Type)
+		default:
+			return &PropError{Prop: name, Expected: field.Type.String(), Got: prop.Type().String()}
+		}
+		value.FieldByIndex(field.Index).Set(prop)
+	}
+	return nil
+}
+
+func isNumber(v reflect.Value) bool {
+	return v.CanInt() || v.CanUint() || v.CanFloat()
+}
@@ -0,0 +1,224 @@
+package tmpls_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+type buttonProps struct {
+	Label   string `tmpls:"required"`
+	Primary bool
+	Count   int64
+}
+
+func TestComponent(t *testing.T) {
+	t.Parallel()
+
+	button := `{{ define "button" }}<button{{ if .Primary }} class="primary"{{ end }}>` +
+		`{{ .Label }}{{ with .Count }} ({{ . }}){{ end }}</button>{{ end }}`
+	badge := `{{ define "badge" }}<span>{{ . }}</span>{{ end }}`
+
+	tests := []struct {
+		name        string
+		src         string
+		data        any
+		expected    string
+		expectedErr *tmpls.PropError
+		expectError bool
+	}{
+		{
+			name:     "should render key value props",
+			src:      `{{ component "Button" "Label" "Save" "Primary" true }}`,
+			expected: `<button class="primary">Save</button>`,
+		},
+		{
+			name:     "should convert numbers",
+			src:      `{{ component "Button" "Label" "Inbox" "Count" 3 }}`,
+			expected: `<button>Inbox (3)</button>`,
+		},
+		{
+			name:     "should render struct props",
+			src:      `{{ component "Button" . }}`,
+			data:     buttonProps{Label: "Go"},
+			expected: `<button>Go</button>`,
+		},
+		{
+			name:     "should render map props",
+			src:      `{{ component "Button" . }}`,
+			data:     map[string]any{"Label": "Go", "Primary": nil},
+			expected: `<button>Go</button>`,
+		},
+		{
+			name:     "should pass unchecked props",
+			src:      `{{ component "Badge" "new" }}`,
+			expected: `<span>new</span>`,
+		},
+		{
+			name:        "should reject missing required prop",
+			src:         `{{ component "Button" "Primary" true }}`,
+			expectedErr: &tmpls.PropError{Prop: "Label", Expected: "string"},
+		},
+		{
+			name:        "should reject wrong type",
+			src:         `{{ component "Button" "Label" 1 }}`,
+			expectedErr: &tmpls.PropError{Prop: "Label", Expected: "string", Got: "int"},
+		},
+		{
+			name:        "should reject unknown prop",
+			src:         `{{ component "Button" "Label" "Save" "Size" "lg" }}`,
+			expectError: true,
+		},
+		{
+			name:        "should reject odd key value pairs",
+			src:         `{{ component "Button" "Label" "Save" "Primary" }}`,
+			expectError: true,
+		},
+		{
+			name:        "should reject props of another type",
+			src:         `{{ component "Button" "Save" }}`,
+			expectError: true,
+		},
+		{
+			name:        "should reject unknown component",
+			src:         `{{ component "Link" }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: fstest.MapFS{
+						"common/button.html.tmpl": &fstest.MapFile{Data: []byte(button)},
+						"common/badge.html.tmpl":  &fstest.MapFile{Data: []byte(badge)},
+						"page.html.tmpl":          &fstest.MapFile{Data: []byte(test.src)},
+					},
+					CommonGlob: "common/*.html.tmpl",
+					Components: map[string]tmpls.Component{
+						"Button": {Template: "button", Props: buttonProps{}},
+						"Badge":  {Template: "badge"},
+					},
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", test.data)
+			if test.expectedErr != nil {
+				var propErr *tmpls.PropError
+				if !errors.As(err, &propErr) || *propErr != *test.expectedErr {
+					t.Fatalf("expected %v but got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestComponentsGlob(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		fsys        fstest.MapFS
+		components  map[string]tmpls.Component
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "should register components by base name",
+			fsys: fstest.MapFS{
+				"components/button.html.tmpl": &fstest.MapFile{
+					Data: []byte(`<button>{{ .Label }}</button>`),
+				},
+				"page.html.tmpl": &fstest.MapFile{
+					Data: []byte(`{{ component "button" "Label" "Save" }}`),
+				},
+			},
+			expected: `<button>Save</button>`,
+		},
+		{
+			name: "should parse components into every glob",
+			fsys: fstest.MapFS{
+				"components/badge.html.tmpl": &fstest.MapFile{
+					Data: []byte(`{{ define "badge" }}<span>{{ . }}</span>{{ end }}`),
+				},
+				"page.html.tmpl": &fstest.MapFile{Data: []byte(`{{ template "badge" "new" }}`)},
+			},
+			expected: `<span>new</span>`,
+		},
+		{
+			name: "should prefer registered components",
+			fsys: fstest.MapFS{
+				"components/button.html.tmpl": &fstest.MapFile{
+					Data: []byte(`<button>{{ .Label }}</button>`),
+				},
+				"page.html.tmpl": &fstest.MapFile{
+					Data: []byte(`{{ component "button" "Label" 1 }}`),
+				},
+			},
+			components: map[string]tmpls.Component{
+				"button": {Template: "button.html.tmpl", Props: buttonProps{}},
+			},
+			expectError: true,
+		},
+		{
+			name: "should allow no components",
+			fsys: fstest.MapFS{
+				"page.html.tmpl": &fstest.MapFile{Data: []byte(`page`)},
+			},
+			expected: `page`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			test.fsys["common/empty.html.tmpl"] = &fstest.MapFile{}
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS:    test.fsys,
+					CommonGlob:     "common/*.html.tmpl",
+					ComponentsGlob: "components/*.html.tmpl",
+					Components:     test.components,
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
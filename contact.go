@@ -0,0 +1,88 @@
+package tmpls
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// mailtoLink returns a mailto: link to address showing label, or else the
+// address, obfuscated against scrapers without JavaScript:
+//
+//	{{ mailtoLink .Email }} {{ mailtoLink "sales@example.com" "Contact sales" }}
+//
+// Invalid addresses link to UnsafeURL, as with safeMailto.
+func (t *Templates) mailtoLink(address string, label ...string) (template.HTML, error) {
+	return contactLink(string(t.safeMailto(address)), address, label)
+}
+
+// telLink returns a tel: link to number showing label, or else the number as
+// written, obfuscated like mailtoLink. The link keeps only the digits and a
+// leading +, so numbers can be written as "+1 (555) 010-0199". Numbers with
+// other characters link to UnsafeURL.
+func (t *Templates) telLink(number string, label ...string) (template.HTML, error) {
+	href := "tel:" + telDigits(number)
+	if href == "tel:" {
+		href = string(t.unsafeURL("invalid phone number", ""))
+	}
+	return contactLink(href, number, label)
+}
+
+// telDigits returns the digits of number with its leading +, or "" if it has
+// no digits or characters other than common separators.
+func telDigits(number string) string {
+	var digits strings.Builder
+	for i, r := range strings.TrimSpace(number) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			digits.WriteRune(r)
+		case strings.ContainsRune(" -.()/", r):
+		default:
+			return ""
+		}
+	}
+	if strings.TrimPrefix(digits.String(), "+") == "" {
+		return ""
+	}
+	return digits.String()
+}
+
+// contactLink writes a link to href showing text, or the first of label,
+// with both entity-encoded so the address isn't in the page source as is,
+// and with empty comments splitting the shown text at each "@" and ".".
+// Browsers decode and skip both, so the link still works and copies.
+func contactLink(href string, text string, label []string) (template.HTML, error) {
+	switch len(label) {
+	case 0:
+	case 1:
+		text = label[0]
+	default:
+		return "", fmt.Errorf("expected at most one label but got %d", len(label))
+	}
+	var out strings.Builder
+	out.WriteString(`<a href="`)
+	writeEntities(&out, href)
+	out.WriteString(`">`)
+	for _, r := range text {
+		if r == '@' || r == '.' {
+			out.WriteString("<!-- -->")
+		}
+		writeEntities(&out, string(r))
+	}
+	out.WriteString("</a>")
+	return template.HTML(out.String()), nil
+}
+
+// writeEntities writes every rune of s as a numeric character reference,
+// alternating between decimal and hex.
+func writeEntities(out *strings.Builder, s string) {
+	for _, r := range s {
+		if (out.Len()+int(r))%2 == 0 {
+			fmt.Fprintf(out, "&#%d;", r)
+		} else {
+			fmt.Fprintf(out, "&#x%x;", r)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package tmpls_test
+
+import (
+	"html"
+	"strings"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestContactLinks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should link email addresses",
+			src:      `{{ mailtoLink "sales@example.com" }}`,
+			expected: `<a href="mailto:sales@example.com">sales@example.com</a>`,
+		},
+		{
+			name:     "should show labels",
+			src:      `{{ mailtoLink "sales@example.com?subject=Hi" "Contact sales" }}`,
+			expected: `<a href="mailto:sales@example.com?subject=Hi">Contact sales</a>`,
+		},
+		{
+			name:     "should replace invalid email addresses",
+			src:      `{{ mailtoLink "javascript:alert(1)" }}`,
+			expected: `<a href="#ZgotmplZ">javascript:alert(1)</a>`,
+		},
+		{
+			name:     "should link phone numbers",
+			src:      `{{ telLink "+1 (555) 010-0199" }}`,
+			expected: `<a href="tel:+15550100199">+1 (555) 010-0199</a>`,
+		},
+		{
+			name:     "should replace invalid phone numbers",
+			src:      `{{ telLink "555-CALL-NOW" "Call" }}`,
+			expected: `<a href="#ZgotmplZ">Call</a>`,
+		},
+		{
+			name:        "should fail on more than one label",
+			src:         `{{ telLink "555" "a" "b" }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{}, test.src, nil)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.ContainsAny(output, "@:+") {
+				t.Fatalf("expected %s to be encoded", output)
+			}
+			decoded := html.UnescapeString(strings.ReplaceAll(output, "<!-- -->", ""))
+			if decoded != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, decoded)
+			}
+		})
+	}
+}
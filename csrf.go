@@ -0,0 +1,62 @@
+package tmpls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+)
+
+// CSRFProvider returns the CSRF token for a request, e.g. from gorilla/csrf
+// or nosurf.
+type CSRFProvider interface {
+	Token(r *http.Request) string
+}
+
+// CSRFFunc adapts an ordinary function, such as csrf.Token from gorilla/csrf
+// or nosurf.Token, to a CSRFProvider.
+type CSRFFunc func(r *http.Request) string
+
+func (f CSRFFunc) Token(r *http.Request) string {
+	return f(r)
+}
+
+const defaultCSRFFieldName = "csrf_token"
+
+func (t *Templates) csrfToken(ctx context.Context) any {
+	return func() (string, error) {
+		return t.token(ctx)
+	}
+}
+
+func (t *Templates) csrfField(ctx context.Context) any {
+	return func() (template.HTML, error) {
+		token, err := t.token(ctx)
+		if err != nil {
+			return "", err
+		}
+		name := t.config.CSRFFieldName
+		if name == "" {
+			name = defaultCSRFFieldName
+		}
+		return template.HTML(fmt.Sprintf(
+			`<input type="hidden" name="%s" value="%s">`,
+			template.HTMLEscapeString(name),
+			template.HTMLEscapeString(token),
+		)), nil
+	}
+}
+
+// token fails rather than rendering a form without protection.
+func (t *Templates) token(ctx context.Context) (string, error) {
+	bindToRequest(ctx)
+	if t.config.CSRF == nil {
+		return "", errors.New("CSRF tokens require Config.CSRF")
+	}
+	rc := RenderContextFrom(ctx)
+	if rc == nil || rc.Request == nil {
+		return "", errors.New("CSRF tokens require a RenderContext with a Request")
+	}
+	return t.config.CSRF.Token(rc.Request), nil
+}
@@ -0,0 +1,93 @@
+package tmpls_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestCSRF(t *testing.T) {
+	t.Parallel()
+
+	provider := tmpls.CSRFFunc(func(r *http.Request) string {
+		return r.Header.Get("X-Token")
+	})
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("X-Token", `a"b`)
+	withRequest := tmpls.WithRenderContext(
+		context.Background(),
+		&tmpls.RenderContext{Request: request},
+	)
+
+	tests := []struct {
+		name        string
+		config      tmpls.Config
+		ctx         context.Context
+		src         string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should render hidden field",
+			config:   tmpls.Config{CSRF: provider},
+			ctx:      withRequest,
+			src:      `<form>{{ csrfField }}</form>`,
+			expected: `<form><input type="hidden" name="csrf_token" value="a&#34;b"></form>`,
+		},
+		{
+			name:     "should render hidden field with configured name",
+			config:   tmpls.Config{CSRF: provider, CSRFFieldName: "gorilla.csrf.Token"},
+			ctx:      withRequest,
+			src:      `{{ csrfField }}`,
+			expected: `<input type="hidden" name="gorilla.csrf.Token" value="a&#34;b">`,
+		},
+		{
+			name:     "should render token",
+			config:   tmpls.Config{CSRF: provider},
+			ctx:      withRequest,
+			src:      `<meta name="csrf-token" content="{{ csrfToken }}">`,
+			expected: `<meta name="csrf-token" content="a&#34;b">`,
+		},
+		{
+			name:        "should fail without provider",
+			ctx:         withRequest,
+			src:         `{{ csrfField }}`,
+			expectError: true,
+		},
+		{
+			name:        "should fail without request",
+			config:      tmpls.Config{CSRF: provider},
+			ctx:         context.Background(),
+			src:         `{{ csrfField }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, test.config, test.src)
+			output, err := templates.ExecuteContext(
+				test.ctx,
+				"page.html.tmpl",
+				"page.html.tmpl",
+				nil,
+			)
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
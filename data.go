@@ -0,0 +1,76 @@
+package tmpls
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+)
+
+// dict builds a map from key-value pairs, for passing several values to a
+// partial:
+//
+//	{{ template "card" dict "Title" .Title "Body" .Body }}
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict requires key-value pairs but got %d arguments", len(pairs))
+	}
+	m := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict keys must be strings but got %T", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// list builds a slice from its arguments.
+func list(items ...any) []any {
+	if items == nil {
+		return []any{}
+	}
+	return items
+}
+
+// merge returns a new map with the entries of every map, later maps taking
+// precedence. Maps must have string keys; nil maps are skipped.
+func merge(maps ...any) (map[string]any, error) {
+	merged := map[string]any{}
+	for _, m := range maps {
+		if err := copyMap(merged, m); err != nil {
+			return nil, fmt.Errorf("merge: %w", err)
+		}
+	}
+	return merged, nil
+}
+
+// set returns a copy of m with key set to value, leaving m untouched since it
+// may be shared with other executions.
+func set(m any, key string, value any) (map[string]any, error) {
+	copied := map[string]any{}
+	if err := copyMap(copied, m); err != nil {
+		return nil, fmt.Errorf("set: %w", err)
+	}
+	copied[key] = value
+	return copied, nil
+}
+
+func copyMap(dst map[string]any, src any) error {
+	switch m := src.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		maps.Copy(dst, m)
+		return nil
+	}
+	value := reflect.ValueOf(src)
+	if value.Kind() != reflect.Map || value.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("expected a map with string keys but got %T", src)
+	}
+	iter := value.MapRange()
+	for iter.Next() {
+		dst[iter.Key().String()] = iter.Value().Interface()
+	}
+	return nil
+}
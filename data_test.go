@@ -0,0 +1,88 @@
+package tmpls_test
+
+import (
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestDataFuncs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should build dict",
+			src:      `{{ $d := dict "a" 1 "b" "two" }}{{ $d.a }} {{ $d.b }}`,
+			expected: "1 two",
+		},
+		{
+			name:     "should build empty dict",
+			src:      `{{ len dict }}`,
+			expected: "0",
+		},
+		{
+			name:        "should fail for odd dict arguments",
+			src:         `{{ dict "a" 1 "b" }}`,
+			expectError: true,
+		},
+		{
+			name:        "should fail for non string dict keys",
+			src:         `{{ dict 1 "a" }}`,
+			expectError: true,
+		},
+		{
+			name:     "should build list",
+			src:      `{{ range list "a" 2 true }}[{{ . }}]{{ end }}{{ len list }}`,
+			expected: "[a][2][true]0",
+		},
+		{
+			name:     "should merge maps with later precedence",
+			src:      `{{ $m := merge . nil (dict "b" 3 "c" 4) }}{{ $m.a }}{{ $m.b }}{{ $m.c }}`,
+			data:     map[string]int{"a": 1, "b": 2},
+			expected: "134",
+		},
+		{
+			name:        "should fail to merge non maps",
+			src:         `{{ merge . }}`,
+			data:        []string{"a"},
+			expectError: true,
+		},
+		{
+			name:     "should set on a copy",
+			src:      `{{ $s := set . "a" 2 }}{{ $s.a }}{{ .a }}`,
+			data:     map[string]any{"a": 1},
+			expected: "21",
+		},
+		{
+			name: "should pass composed data to partials",
+			src: `{{ define "p" }}{{ .Title }}:{{ range .Items }}{{ . }}{{ end }}{{ end }}` +
+				`{{ template "p" dict "Title" "t" "Items" (list 1 2) }}`,
+			expected: "t:12",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{}, test.src, test.data)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package tmpls
+
+import "reflect"
+
+// isEmpty reports whether value is nil, a zero value, or an empty array,
+// slice, map, channel or string. Non-nil pointers are never empty, so a
+// pointer to 0 is distinguishable from a missing value.
+func isEmpty(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Chan, reflect.String:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// defaultValue returns value, or fallback if value is empty:
+//
+//	{{ .Name | default "Anonymous" }}
+func defaultValue(fallback any, value any) any {
+	if isEmpty(value) {
+		return fallback
+	}
+	return value
+}
+
+// coalesce returns the first non-empty value, or nil.
+func coalesce(values ...any) any {
+	for _, value := range values {
+		if !isEmpty(value) {
+			return value
+		}
+	}
+	return nil
+}
+
+// ternary returns ifTrue if condition is set and otherwise ifFalse:
+//
+//	{{ .Done | ternary "done" "pending" }}
+func ternary(ifTrue any, ifFalse any, condition bool) any {
+	if condition {
+		return ifTrue
+	}
+	return ifFalse
+}
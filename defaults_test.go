@@ -0,0 +1,83 @@
+package tmpls_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestDefaultFuncs(t *testing.T) {
+	t.Parallel()
+
+	zero := 0
+	tests := []struct {
+		name     string
+		src      string
+		data     any
+		expected string
+	}{
+		{
+			name:     "should default nil",
+			src:      `{{ .Missing | default "x" }}`,
+			data:     map[string]any{},
+			expected: "x",
+		},
+		{
+			name: "should default zero values",
+			src: `{{ .S | default "s" }}{{ .I | default 1 }}{{ .B | default "b" }}` +
+				`{{ .T | default "t" }}`,
+			data:     map[string]any{"S": "", "I": 0, "B": false, "T": time.Time{}},
+			expected: "s1bt",
+		},
+		{
+			name:     "should default empty collections",
+			src:      `{{ .L | default "l" }}{{ .M | default "m" }}`,
+			data:     map[string]any{"L": []int{}, "M": map[string]int{}},
+			expected: "lm",
+		},
+		{
+			name: "should keep non empty values",
+			src: `{{ .S | default "s" }}{{ .I | default 1 }}{{ .B | default "b" }}` +
+				`{{ len (.L | default "l") }}`,
+			data:     map[string]any{"S": "v", "I": -1, "B": true, "L": []int{1, 2}},
+			expected: "v-1true2",
+		},
+		{
+			name:     "should keep pointer to zero",
+			src:      `{{ printf "%T" (.P | default "p") }}`,
+			data:     map[string]any{"P": &zero},
+			expected: "*int",
+		},
+		{
+			name:     "should coalesce to first non empty value",
+			src:      `{{ coalesce .A .B .C "d" }}`,
+			data:     map[string]any{"A": "", "B": 0, "C": "c"},
+			expected: "c",
+		},
+		{
+			name:     "should coalesce to nil",
+			src:      `{{ if coalesce .A "" 0 }}set{{ else }}unset{{ end }}`,
+			data:     map[string]any{},
+			expected: "unset",
+		},
+		{
+			name:     "should pick by condition",
+			src:      `{{ true | ternary "yes" "no" }} {{ ternary "yes" "no" false }}`,
+			expected: "yes no",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{}, test.src, test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package tmpls
+
+import (
+	"html/template"
+	"strings"
+	"unicode"
+)
+
+// maxDiffCells bounds the table diff fills, beyond which the whole text is
+// shown as replaced rather than spending quadratic time and memory.
+const maxDiffCells = 1 << 22
+
+// diff renders a word-level diff of before and after, with removed words in
+// del elements and added words in ins elements, for audit logs and revision
+// histories:
+//
+//	{{ diff .Before.Body .After.Body }}
+//
+// Whitespace is kept as it is, so the result reads like after with the
+// changes marked.
+func diff(before string, after string) template.HTML {
+	a, b := diffTokens(before), diffTokens(after)
+	var s strings.Builder
+	write := func(tag string, tokens []string) {
+		if len(tokens) == 0 {
+			return
+		}
+		if tag != "" {
+			s.WriteString("<" + tag + ">")
+		}
+		for _, token := range tokens {
+			s.WriteString(template.HTMLEscapeString(token))
+		}
+		if tag != "" {
+			s.WriteString("</" + tag + ">")
+		}
+	}
+	// trim the common prefix and suffix, which is most of a typical edit
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	write("", a[:prefix])
+	a, b, tail := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], a[len(a)-suffix:]
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		write("del", a)
+		write("ins", b)
+		write("", tail)
+		return template.HTML(s.String())
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var deleted, inserted []string
+	flush := func() {
+		write("del", deleted)
+		write("ins", inserted)
+		deleted, inserted = deleted[:0], inserted[:0]
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			flush()
+			write("", a[i:i+1])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			deleted = append(deleted, a[i])
+			i++
+		default:
+			inserted = append(inserted, b[j])
+			j++
+		}
+	}
+	flush()
+	write("", tail)
+	return template.HTML(s.String())
+}
+
+// diffTokens splits s into alternating runs of whitespace and other
+// characters.
+func diffTokens(s string) []string {
+	var tokens []string
+	start, space := 0, false
+	for i, r := range s {
+		if i > start && unicode.IsSpace(r) != space {
+			tokens = append(tokens, s[start:i])
+			start = i
+		}
+		space = unicode.IsSpace(r)
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
@@ -0,0 +1,81 @@
+package tmpls_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected string
+	}{
+		{
+			name:     "should render equal text as is",
+			before:   "same text",
+			after:    "same text",
+			expected: "same text",
+		},
+		{
+			name:     "should mark replaced words",
+			before:   "the quick brown fox",
+			after:    "the slow brown fox",
+			expected: "the <del>quick</del><ins>slow</ins> brown fox",
+		},
+		{
+			name:     "should mark added and removed words",
+			before:   "a b c",
+			after:    "a c d",
+			expected: "a <del>b </del>c<ins> d</ins>",
+		},
+		{
+			name:   "should escape text",
+			before: "<b>old</b>",
+			after:  "<b>new</b> & more",
+			expected: "<del>&lt;b&gt;old&lt;/b&gt;</del>" +
+				"<ins>&lt;b&gt;new&lt;/b&gt; &amp; more</ins>",
+		},
+		{
+			name:     "should mark everything when one side is empty",
+			after:    "all new",
+			expected: "<ins>all new</ins>",
+		},
+		{
+			name:     "should keep unicode whitespace",
+			before:   "a b",
+			after:    "a c",
+			expected: "a <del>b</del><ins>c</ins>",
+		},
+		{
+			name:   "should replace long changes whole",
+			before: "x " + strings.Repeat("a ", 3000) + "y",
+			after:  "x " + strings.Repeat("b ", 3000) + "y",
+			expected: "x <del>" + strings.Repeat("a ", 2999) + "a</del><ins>" +
+				strings.Repeat("b ", 2999) + "b</ins> y",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(
+				t,
+				tmpls.Config{},
+				`{{ diff .Before .After }}`,
+				map[string]string{"Before": test.before, "After": test.after},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
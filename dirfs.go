@@ -0,0 +1,28 @@
+package tmpls
+
+import (
+	"io/fs"
+	"os"
+)
+
+// DirFS is os.DirFS for a directory Watch can observe.
+func DirFS(dir string) fs.FS {
+	return dirFS{FS: os.DirFS(dir), dir: dir}
+}
+
+type dirFS struct {
+	fs.FS
+	dir string
+}
+
+func (d dirFS) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(d.FS, name)
+}
+
+func (d dirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(d.FS, name)
+}
+
+func (d dirFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(d.FS, name)
+}
@@ -0,0 +1,16 @@
+// Package tmpls is a lightweight, thread-safe tool for working with go html
+// templates.
+//
+// # Build profiles
+//
+// The core package only depends on the standard library and compiles for
+// js/wasm, wasip1 and TinyGo, so the same templates can be rendered for
+// client-side previews. Optional subsystems with heavier dependencies, such
+// as filesystem watching and metrics, are excluded from those targets and
+// from any build using the tmpls_lite tag. Their files are constrained with
+//
+//	//go:build !tmpls_lite && !js && !wasip1 && !tinygo
+//
+// and where the API must stay available, a counterpart file with the
+// inverse constraint provides a stub returning ErrUnsupported.
+package tmpls
@@ -0,0 +1,39 @@
+// Package echotmpls renders tmpls templates from Echo handlers.
+package echotmpls
+
+import (
+	"io"
+	"path"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/labstack/echo/v4"
+)
+
+// Renderer is an echo.Renderer executing templates for c.Render. Names are
+// template files executed as their own glob with their base name, e.g.
+// "pages/home.html.tmpl", unless Glob is set, in which case they name
+// templates in it. Executions get the request's RenderContext, nonce and
+// Content-Security-Policy header, as with Templates.RenderRequest.
+type Renderer struct {
+	Templates *tmpls.Templates
+	Glob      string
+}
+
+var _ echo.Renderer = (*Renderer)(nil)
+
+func (r *Renderer) Render(w io.Writer, name string, data any, c echo.Context) error {
+	ctx, err := r.Templates.RequestContext(c.Response().Header(), c.Request())
+	if err != nil {
+		return err
+	}
+	glob, template := name, path.Base(name)
+	if r.Glob != "" {
+		glob, template = r.Glob, name
+	}
+	output, err := r.Templates.ExecuteContext(ctx, glob, template, data)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, output)
+	return err
+}
@@ -0,0 +1,96 @@
+package echotmpls_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/fivethirty/tmpls/echotmpls"
+	"github.com/labstack/echo/v4"
+)
+
+func TestRenderer(t *testing.T) {
+	t.Parallel()
+
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: fstest.MapFS{
+				"common/empty.html.tmpl": &fstest.MapFile{},
+				"pages/home.html.tmpl": &fstest.MapFile{
+					Data: []byte(`{{ define "title" }}Home{{ end }}` +
+						`<p class="{{ active "/home" }}">{{ . }}</p>` +
+						`<script nonce="{{ nonce }}"></script>`),
+				},
+			},
+			CommonGlob: "common/*.html.tmpl",
+			CSP:        "script-src 'nonce-{nonce}'",
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		renderer     *echotmpls.Renderer
+		template     string
+		expected     string
+		expectStatus int
+	}{
+		{
+			name:         "should render template file",
+			renderer:     &echotmpls.Renderer{Templates: templates},
+			template:     "pages/home.html.tmpl",
+			expected:     `<p class="active">hi</p><script nonce="{nonce}"></script>`,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "should render template from glob",
+			renderer:     &echotmpls.Renderer{Templates: templates, Glob: "pages/*.html.tmpl"},
+			template:     "title",
+			expected:     `Home`,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "should return error",
+			renderer:     &echotmpls.Renderer{Templates: templates},
+			template:     "pages/missing.html.tmpl",
+			expectStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			e := echo.New()
+			e.Renderer = test.renderer
+			e.GET("/home", func(c echo.Context) error {
+				return c.Render(http.StatusOK, test.template, "hi")
+			})
+			recorder := httptest.NewRecorder()
+			e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/home", nil))
+			if recorder.Code != test.expectStatus {
+				t.Fatalf("expected status %d but got %d", test.expectStatus, recorder.Code)
+			}
+			if test.expectStatus != http.StatusOK {
+				return
+			}
+			csp := recorder.Header().Get("Content-Security-Policy")
+			matches := regexp.MustCompile(`^script-src 'nonce-([A-Za-z0-9_-]{22})'$`).
+				FindStringSubmatch(csp)
+			if matches == nil {
+				t.Fatalf("expected nonce policy but got %s", csp)
+			}
+			expected := strings.ReplaceAll(test.expected, tmpls.NoncePlaceholder, matches[1])
+			if recorder.Body.String() != expected {
+				t.Fatalf("expected %s but got %s", expected, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Package email renders transactional mail from tmpls templates. Each message
+// is a pair of templates sharing a name, e.g. emails/welcome.html.tmpl and
+// emails/welcome.txt.tmpl, executed with the same data, so the text
+// alternative is written by hand instead of derived from the HTML:
+//
+//	msg, err := email.Render(ctx, templates, "emails/welcome", user)
+//	if err != nil {
+//		return err
+//	}
+//	contentType, body, err := msg.Multipart()
+//
+// The text template is parsed with tmpls.TextEngine, so it isn't
+// HTML-escaped, and both share the caching and pooling of the Templates.
+package email
+
+import (
+	"bytes"
+	"context"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"path"
+
+	"github.com/fivethirty/tmpls"
+)
+
+// Message is a rendered email.
+type Message struct {
+	HTML string
+	Text string
+}
+
+// Render executes name+".html.tmpl" and name+".txt.tmpl" with data, each as
+// its own glob, returning both bodies.
+func Render(
+	ctx context.Context,
+	templates *tmpls.Templates,
+	name string,
+	data any,
+) (*Message, error) {
+	var msg Message
+	for _, part := range []struct {
+		ext  string
+		body *string
+	}{
+		{ext: ".html.tmpl", body: &msg.HTML},
+		{ext: ".txt.tmpl", body: &msg.Text},
+	} {
+		glob := name + part.ext
+		output, err := templates.ExecuteContext(ctx, glob, path.Base(glob), data)
+		if err != nil {
+			return nil, err
+		}
+		*part.body = output
+	}
+	return &msg, nil
+}
+
+// Multipart returns m as a multipart/alternative MIME body, with the text
+// part first as RFC 2046 requires, and the Content-Type header to send it
+// with. Both parts are quoted-printable encoded UTF-8.
+func (m *Message) Multipart() (contentType string, body []byte, err error) {
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+	for _, part := range []struct {
+		mediaType string
+		body      string
+	}{
+		{mediaType: "text/plain", body: m.Text},
+		{mediaType: "text/html", body: m.HTML},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.mediaType+"; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return "", nil, err
+		}
+		encoder := quotedprintable.NewWriter(w)
+		if _, err := encoder.Write([]byte(part.body)); err != nil {
+			return "", nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", nil, err
+	}
+	contentType = mime.FormatMediaType(
+		"multipart/alternative",
+		map[string]string{"boundary": writer.Boundary()},
+	)
+	return contentType, buffer.Bytes(), nil
+}
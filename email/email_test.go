@@ -0,0 +1,140 @@
+package email_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/fivethirty/tmpls/email"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: fstest.MapFS{
+				"emails/welcome.html.tmpl": &fstest.MapFile{
+					Data: []byte(`{{ template "signature" }}<p>Hi {{ .Name }}</p>`),
+				},
+				"emails/welcome.txt.tmpl": &fstest.MapFile{
+					Data: []byte(`{{ template "signature" }}Hi {{ .Name }}`),
+				},
+				"emails/html-only.html.tmpl": &fstest.MapFile{
+					Data: []byte(`<p>Hi</p>`),
+				},
+				"common/signature.html.tmpl": &fstest.MapFile{
+					Data: []byte(`{{ define "signature" }}<i>Acme</i> {{ end }}`),
+				},
+				"common/signature.txt.tmpl": &fstest.MapFile{
+					Data: []byte(`{{ define "signature" }}-- Acme {{ end }}`),
+				},
+			},
+			CommonGlob: "common/*",
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		template    string
+		expected    email.Message
+		expectError bool
+	}{
+		{
+			name:     "should render both bodies",
+			template: "emails/welcome",
+			expected: email.Message{
+				HTML: "<i>Acme</i> <p>Hi Tom &amp; Jerry</p>",
+				Text: "-- Acme Hi Tom & Jerry",
+			},
+		},
+		{
+			name:        "should fail without a text template",
+			template:    "emails/html-only",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			msg, err := email.Render(
+				context.Background(),
+				templates,
+				test.template,
+				map[string]string{"Name": "Tom & Jerry"},
+			)
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *msg != test.expected {
+				t.Fatalf("expected %+v but got %+v", test.expected, *msg)
+			}
+		})
+	}
+}
+
+func TestMultipart(t *testing.T) {
+	t.Parallel()
+
+	msg := email.Message{
+		HTML: `<p style="color: red">Café</p>`,
+		Text: "Café " + strings.Repeat("long ", 20),
+	}
+	contentType, body, err := msg.Multipart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative but got %s", mediaType)
+	}
+
+	expected := []struct {
+		contentType string
+		body        string
+	}{
+		{contentType: "text/plain; charset=utf-8", body: msg.Text},
+		{contentType: "text/html; charset=utf-8", body: msg.HTML},
+	}
+	reader := multipart.NewReader(strings.NewReader(string(body)), params["boundary"])
+	for _, part := range expected {
+		p, err := reader.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.Header.Get("Content-Type"); got != part.contentType {
+			t.Fatalf("expected %s but got %s", part.contentType, got)
+		}
+		// the reader decodes quoted-printable parts
+		decoded, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(decoded) != part.body {
+			t.Fatalf("expected %s but got %s", part.body, decoded)
+		}
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Fatalf("expected %v but got %v", io.EOF, err)
+	}
+}
@@ -0,0 +1,46 @@
+package tmpls
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+)
+
+// Renderable is markup rendered by Go code, such as a templ.Component, that
+// the embed func emits without escaping it again.
+type Renderable interface {
+	Render(ctx context.Context, w io.Writer) error
+}
+
+// nodeRenderable is markup rendered without a context, such as a gomponents
+// Node.
+type nodeRenderable interface {
+	Render(w io.Writer) error
+}
+
+// embed renders a Renderable, or a gomponents-style node, with the
+// execution's context:
+//
+//	{{ embed .Sidebar }}
+func embed(ctx context.Context) any {
+	return func(value any) (template.HTML, error) {
+		var buffer bytes.Buffer
+		switch v := value.(type) {
+		case nil:
+			return "", nil
+		case Renderable:
+			if err := v.Render(ctx, &buffer); err != nil {
+				return "", err
+			}
+		case nodeRenderable:
+			if err := v.Render(&buffer); err != nil {
+				return "", err
+			}
+		default:
+			return "", fmt.Errorf("embed: %T has no Render method", value)
+		}
+		return template.HTML(buffer.String()), nil
+	}
+}
@@ -0,0 +1,92 @@
+package tmpls_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+// greeting renders like a templ.Component.
+type greeting string
+
+func (g greeting) Render(ctx context.Context, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "<b>%s, %s</b>", g, tmpls.RenderContextFrom(ctx).Locale)
+	return err
+}
+
+// node renders like a gomponents Node.
+type node string
+
+func (n node) Render(w io.Writer) error {
+	if n == "" {
+		return errors.New("empty node")
+	}
+	_, err := io.WriteString(w, "<i>"+string(n)+"</i>")
+	return err
+}
+
+func TestEmbed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should embed context component",
+			data:     greeting("hello"),
+			expected: "<p><b>hello, en</b></p>",
+		},
+		{
+			name:     "should embed node",
+			data:     node("x"),
+			expected: "<p><i>x</i></p>",
+		},
+		{
+			name:     "should embed nothing for nil",
+			expected: "<p></p>",
+		},
+		{
+			name:        "should return render errors",
+			data:        node(""),
+			expectError: true,
+		},
+		{
+			name:        "should fail for other values",
+			data:        "<b>",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, tmpls.Config{}, `<p>{{ embed . }}</p>`)
+			ctx := tmpls.WithRenderContext(context.Background(), &tmpls.RenderContext{Locale: "en"})
+			output, err := templates.ExecuteContext(
+				ctx,
+				"page.html.tmpl",
+				"page.html.tmpl",
+				test.data,
+			)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package tmpls
+
+import "regexp"
+
+// emojiShortcodes are the built-in shortcodes of the emoji func, named as on
+// GitHub and Slack.
+var emojiShortcodes = map[string]string{
+	"+1":                    "👍",
+	"-1":                    "👎",
+	"100":                   "💯",
+	"bug":                   "🐛",
+	"check":                 "✔️",
+	"clap":                  "👏",
+	"confused":              "😕",
+	"cry":                   "😢",
+	"eyes":                  "👀",
+	"fire":                  "🔥",
+	"grin":                  "😁",
+	"heart":                 "❤️",
+	"heart_eyes":            "😍",
+	"hourglass":             "⌛",
+	"joy":                   "😂",
+	"laughing":              "😆",
+	"lock":                  "🔒",
+	"memo":                  "📝",
+	"ok_hand":               "👌",
+	"party":                 "🥳",
+	"point_right":           "👉",
+	"pray":                  "🙏",
+	"question":              "❓",
+	"raised_hands":          "🙌",
+	"rocket":                "🚀",
+	"see_no_evil":           "🙈",
+	"slightly_smiling_face": "🙂",
+	"smile":                 "😄",
+	"smiley":                "😃",
+	"sob":                   "😭",
+	"sparkles":              "✨",
+	"star":                  "⭐",
+	"sunglasses":            "😎",
+	"tada":                  "🎉",
+	"thinking":              "🤔",
+	"thumbsdown":            "👎",
+	"thumbsup":              "👍",
+	"warning":               "⚠️",
+	"wave":                  "👋",
+	"white_check_mark":      "✅",
+	"wink":                  "😉",
+	"x":                     "❌",
+	"zap":                   "⚡",
+}
+
+var shortcodePattern = regexp.MustCompile(`:([a-z0-9_+-]+):`)
+
+// emoji replaces :shortcodes: in s with their emoji, from Config.Emoji and
+// then the built-in ones such as :tada: and :+1:, leaving unknown ones as
+// they are:
+//
+//	{{ emoji .Comment }}
+func (t *Templates) emoji(s string) string {
+	return shortcodePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := match[1 : len(match)-1]
+		if e, ok := t.config.Emoji[name]; ok {
+			return e
+		}
+		if e, ok := emojiShortcodes[name]; ok {
+			return e
+		}
+		return match
+	})
+}
@@ -0,0 +1,50 @@
+package tmpls_test
+
+import (
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestEmoji(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		config   tmpls.Config
+		value    string
+		expected string
+	}{
+		{
+			name:     "should replace built-in shortcodes",
+			value:    "Shipped :tada: :+1:",
+			expected: "Shipped 🎉 👍",
+		},
+		{
+			name: "should replace custom shortcodes",
+			config: tmpls.Config{
+				Emoji: map[string]string{"party_parrot": "🦜", "tada": "🥳"},
+			},
+			value:    ":party_parrot: :tada:",
+			expected: "🦜 🥳",
+		},
+		{
+			name:     "should leave unknown shortcodes as they are",
+			value:    ":not_an_emoji: at 10:30:45",
+			expected: ":not_an_emoji: at 10:30:45",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, test.config, `{{ emoji . }}`, test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
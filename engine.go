@@ -0,0 +1,331 @@
+package tmpls
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+)
+
+// textExt is the extension of files parsed with TextEngine by default.
+const textExt = ".txt.tmpl"
+
+// Engine parses templates for a template language, so alternatives to
+// html/template share the caching, pooling and logging of Templates.
+type Engine interface {
+	// Parse parses files from fsys into a new template set with funcs
+	// available. Files are in override order, later definitions replacing
+	// earlier ones, and each file's own template is named by its base name.
+	// Funcs of type ContextFunc read the execution's context; engines that
+	// can't bind them per execution should call BindContextFuncs with
+	// context.Background.
+	Parse(fsys fs.FS, files []string, funcs map[string]any) (Executor, error)
+}
+
+// Executor executes named templates from a parsed set. It must be safe for
+// concurrent use. *html/template.Template and *text/template.Template both
+// implement it.
+type Executor interface {
+	ExecuteTemplate(w io.Writer, name string, data any) error
+}
+
+// ContextExecutor is an Executor that binds ContextFuncs to the context of
+// each execution. Templates.ExecuteContext uses it when available.
+type ContextExecutor interface {
+	Executor
+	ExecuteTemplateContext(ctx context.Context, w io.Writer, name string, data any) error
+}
+
+// ContextFunc builds a template func for one execution from its context, for
+// funcs reading request-scoped state such as the RenderContext.
+type ContextFunc func(ctx context.Context) any
+
+// BindContextFuncs returns funcs with every ContextFunc replaced by the func
+// it builds for ctx.
+func BindContextFuncs(ctx context.Context, funcs map[string]any) map[string]any {
+	bound := make(map[string]any, len(funcs))
+	for name, fn := range funcs {
+		if contextFunc, ok := fn.(ContextFunc); ok {
+			fn = contextFunc(ctx)
+		}
+		bound[name] = fn
+	}
+	return bound
+}
+
+// HTMLEngine parses templates with html/template. It is used for every file
+// not matched by Config.Engines.
+type HTMLEngine struct {
+	// SourceComments marks output with comments naming the template and file
+	// it came from, see Config.SourceComments.
+	SourceComments bool
+	// MissingKeyError fails executions indexing a map with a missing key,
+	// see Config.MissingKeyError.
+	MissingKeyError bool
+	// CountIncludes counts template and block actions for metered
+	// executions, see Resources.Includes. Engines chosen by default set it
+	// for globs with Quotas, and for all once ExecuteMetered is called.
+	CountIncludes bool
+}
+
+func (e HTMLEngine) Parse(fsys fs.FS, files []string, funcs map[string]any) (Executor, error) {
+	tmpl := template.New("").Funcs(BindContextFuncs(context.Background(), funcs))
+	if e.MissingKeyError {
+		tmpl.Option("missingkey=error")
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		src := string(data)
+		if e.SourceComments {
+			if src, err = annotate(file, src); err != nil {
+				return nil, err
+			}
+		}
+		if e.CountIncludes {
+			if src, err = countIncludes(file, src); err != nil {
+				return nil, err
+			}
+		}
+		src, err = preprocess(file, src)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tmpl.New(path.Base(file)).Parse(src); err != nil {
+			return nil, err
+		}
+	}
+	executor := &htmlExecutor{prototype: tmpl, contextFuncs: map[string]ContextFunc{}}
+	for name, fn := range funcs {
+		if contextFunc, ok := fn.(ContextFunc); ok {
+			executor.contextFuncs[name] = contextFunc
+		}
+	}
+	return executor, nil
+}
+
+// htmlExecutor executes clones of a parsed html/template set, since funcs
+// can only be rebound for each execution on a set no other execution is
+// using. Clones are pooled so each is only escaped once.
+type htmlExecutor struct {
+	prototype    *template.Template
+	contextFuncs map[string]ContextFunc
+	clones       sync.Pool
+	// paths caches resolvedPaths by template name.
+	paths sync.Map
+}
+
+func (e *htmlExecutor) ExecuteTemplate(w io.Writer, name string, data any) error {
+	return e.ExecuteTemplateContext(context.Background(), w, name, data)
+}
+
+func (e *htmlExecutor) ExecuteTemplateContext(
+	ctx context.Context,
+	w io.Writer,
+	name string,
+	data any,
+) error {
+	// funcs from ExecuteWithFuncs get a fresh clone, kept out of the pool
+	overrides := funcsFrom(ctx)
+	clone := e.clone
+	if overrides != nil {
+		clone = e.prototype.Clone
+	}
+	tmpl, err := clone()
+	if err != nil {
+		return err
+	}
+	if overrides == nil {
+		defer e.clones.Put(tmpl)
+	}
+	if executionFrom(ctx) == nil {
+		ctx = withExecution(ctx)
+	}
+	ctx = withExecutor(ctx, tmpl)
+	ctx = context.WithValue(ctx, setKey{}, ContextExecutor(e))
+	funcs := make(template.FuncMap, len(e.contextFuncs)+len(overrides))
+	for name, contextFunc := range e.contextFuncs {
+		funcs[name] = contextFunc(ctx)
+	}
+	for name, fn := range BindContextFuncs(ctx, overrides) {
+		funcs[name] = fn
+	}
+	err = tmpl.Funcs(funcs).ExecuteTemplate(contextWriter{ctx: ctx, w: w}, name, data)
+	// async blocks are streamed while the clone is still reserved for them
+	if async := executionFrom(ctx).async; async != nil {
+		if err != nil {
+			async.discard()
+			return err
+		}
+		async.finish(ctx, tmpl, w)
+	}
+	return err
+}
+
+// Template returns an html/template set bound to context.Background, for
+// tools such as tmplsgen that inspect the parsed templates.
+func (e *htmlExecutor) Template() (*template.Template, error) {
+	return e.clone()
+}
+
+func (e *htmlExecutor) clone() (*template.Template, error) {
+	if tmpl, ok := e.clones.Get().(*template.Template); ok {
+		return tmpl, nil
+	}
+	return e.prototype.Clone()
+}
+
+// TextEngine parses templates with text/template, for output that isn't HTML
+// such as email bodies and config files, which html/template would escape. It
+// is used for files ending in ".txt.tmpl" not matched by Config.Engines.
+type TextEngine struct {
+	// MissingKeyError fails executions indexing a map with a missing key,
+	// see Config.MissingKeyError.
+	MissingKeyError bool
+	// CountIncludes counts template and block actions for metered
+	// executions, see Resources.Includes. Engines chosen by default set it
+	// for globs with Quotas, and for all once ExecuteMetered is called.
+	CountIncludes bool
+}
+
+func (e TextEngine) Parse(fsys fs.FS, files []string, funcs map[string]any) (Executor, error) {
+	tmpl := texttemplate.New("").Funcs(BindContextFuncs(context.Background(), funcs))
+	if e.MissingKeyError {
+		tmpl.Option("missingkey=error")
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		src := string(data)
+		if e.CountIncludes {
+			if src, err = countIncludes(file, src); err != nil {
+				return nil, err
+			}
+		}
+		src, err = preprocess(file, src)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tmpl.New(path.Base(file)).Parse(src); err != nil {
+			return nil, err
+		}
+	}
+	executor := &textExecutor{prototype: tmpl, contextFuncs: map[string]ContextFunc{}}
+	for name, fn := range funcs {
+		if contextFunc, ok := fn.(ContextFunc); ok {
+			executor.contextFuncs[name] = contextFunc
+		}
+	}
+	return executor, nil
+}
+
+// textExecutor executes clones of a parsed text/template set with
+// ContextFuncs bound to each execution. Without escaping to cache, clones
+// aren't pooled.
+type textExecutor struct {
+	prototype    *texttemplate.Template
+	contextFuncs map[string]ContextFunc
+}
+
+func (e *textExecutor) ExecuteTemplate(w io.Writer, name string, data any) error {
+	return e.ExecuteTemplateContext(context.Background(), w, name, data)
+}
+
+func (e *textExecutor) ExecuteTemplateContext(
+	ctx context.Context,
+	w io.Writer,
+	name string,
+	data any,
+) error {
+	tmpl, err := e.prototype.Clone()
+	if err != nil {
+		return err
+	}
+	if executionFrom(ctx) == nil {
+		ctx = withExecution(ctx)
+	}
+	ctx = withExecutor(ctx, tmpl)
+	ctx = context.WithValue(ctx, setKey{}, ContextExecutor(e))
+	funcs := make(texttemplate.FuncMap, len(e.contextFuncs))
+	for name, contextFunc := range e.contextFuncs {
+		funcs[name] = contextFunc(ctx)
+	}
+	return tmpl.Funcs(funcs).ExecuteTemplate(contextWriter{ctx: ctx, w: w}, name, data)
+}
+
+// engineFor returns the engine for name and the Config.Engines extension
+// selecting it, preferring the longest matching extension. Other names use
+// TextEngine if they end in ".txt.tmpl" and HTMLEngine otherwise.
+func (t *Templates) engineFor(name string) (Engine, string) {
+	strictness := t.strictness(name)
+	// counting rewrites the source, so it is skipped until it can apply
+	countIncludes := strictness.Quotas != nil || t.metering.Load()
+	var engine Engine = HTMLEngine{
+		SourceComments:  t.config.SourceComments,
+		MissingKeyError: strictness.MissingKeyError,
+		CountIncludes:   countIncludes,
+	}
+	selected := ""
+	for ext, e := range t.config.Engines {
+		if strings.HasSuffix(name, ext) && len(ext) > len(selected) {
+			engine, selected = e, ext
+		}
+	}
+	if selected == "" && strings.HasSuffix(name, textExt) {
+		return TextEngine{
+			MissingKeyError: strictness.MissingKeyError,
+			CountIncludes:   countIncludes,
+		}, textExt
+	}
+	return engine, selected
+}
+
+// files expands CommonGlob, ComponentsGlob and glob into the files parsed for
+// glob. Shared files belonging to a different engine than glob are skipped.
+func (t *Templates) files(fsys fs.FS, glob string) ([]string, error) {
+	_, ext := t.engineFor(glob)
+	var files []string
+	// shared files go first so they can be overridden
+	patterns := []struct {
+		glob     string
+		optional bool
+	}{
+		{glob: t.config.CommonGlob},
+		// an empty components directory is fine
+		{glob: t.config.ComponentsGlob, optional: true},
+		{glob: glob},
+	}
+	for i, pattern := range patterns {
+		if pattern.optional && pattern.glob == "" {
+			continue
+		}
+		matches, err := fs.Glob(fsys, pattern.glob)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 && !pattern.optional {
+			return nil, fmt.Errorf(
+				"pattern matches no files: %#q: %w",
+				pattern.glob,
+				fs.ErrNotExist,
+			)
+		}
+		for _, match := range matches {
+			if i == len(patterns)-1 {
+				files = append(files, match)
+			} else if _, matchExt := t.engineFor(match); matchExt == ext {
+				files = append(files, match)
+			}
+		}
+	}
+	return files, nil
+}
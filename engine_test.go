@@ -0,0 +1,233 @@
+package tmpls_test
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"path"
+	"testing"
+	"testing/fstest"
+	texttemplate "text/template"
+
+	"github.com/fivethirty/tmpls"
+)
+
+type textEngine struct{}
+
+func (textEngine) Parse(
+	fsys fs.FS,
+	files []string,
+	funcs map[string]any,
+) (tmpls.Executor, error) {
+	tmpl := texttemplate.New("").Funcs(tmpls.BindContextFuncs(context.Background(), funcs))
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tmpl.New(path.Base(file)).Parse(string(data)); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+func TestEngines(t *testing.T) {
+	t.Parallel()
+
+	enginesFS := fstest.MapFS{
+		"page.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ template "greeting" . }}`),
+		},
+		"page.txt.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ template "greeting" . }}`),
+		},
+		"common/greeting.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ define "greeting" }}<p>{{ .Text }}</p>{{ end }}`),
+		},
+		"common/greeting.txt.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ define "greeting" }}{{ .Text }}{{ end }}`),
+		},
+	}
+
+	tmpls, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: enginesFS,
+			CommonGlob:  "common/*",
+			Engines: map[string]tmpls.Engine{
+				".txt.tmpl": textEngine{},
+			},
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		glob     string
+		expected string
+	}{
+		{
+			name:     "should use html engine by default",
+			glob:     "page.html.tmpl",
+			expected: "<p>Tom &amp; Jerry</p>",
+		},
+		{
+			name:     "should use engine selected by extension",
+			glob:     "page.txt.tmpl",
+			expected: "Tom & Jerry",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := tmpls.Execute(test.glob, test.glob, templateData{Text: "Tom & Jerry"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestMissingKeyError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		missingKeyError bool
+		src             string
+		expected        string
+		expectError     bool
+	}{
+		{
+			name:     "should render missing keys by default",
+			src:      `{{ .title }}`,
+			expected: "",
+		},
+		{
+			name:            "should fail on missing keys",
+			missingKeyError: true,
+			src:             `{{ .title }}`,
+			expectError:     true,
+		},
+		{
+			name:            "should fail on missing keys of dicts",
+			missingKeyError: true,
+			src:             `{{ (dict "Title" "hi").title }}`,
+			expectError:     true,
+		},
+		{
+			name:            "should render present keys",
+			missingKeyError: true,
+			src:             `{{ .Title }}`,
+			expected:        "hi",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(
+				t,
+				tmpls.Config{MissingKeyError: test.missingKeyError},
+				test.src,
+				map[string]string{"Title": "hi"},
+			)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestTextEngine(t *testing.T) {
+	t.Parallel()
+
+	textFS := fstest.MapFS{
+		"mail.txt.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ template "greeting" . }} ({{ slugify .Title }})`),
+		},
+		"common/greeting.txt.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ define "greeting" }}Hi {{ .Name }}{{ end }}`),
+		},
+		"common/greeting.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ define "greeting" }}<p>{{ .Name }}</p>{{ end }}`),
+		},
+		"strict.txt.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ .title }}`),
+		},
+	}
+
+	tests := []struct {
+		name        string
+		config      tmpls.Config
+		glob        string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should parse .txt.tmpl files with text/template",
+			glob:     "mail.txt.tmpl",
+			data:     map[string]any{"Name": "Tom & Jerry", "Title": "Cat & Mouse"},
+			expected: "Hi Tom & Jerry (cat-mouse)",
+		},
+		{
+			name: "should prefer engines from the config",
+			config: tmpls.Config{
+				Engines: map[string]tmpls.Engine{".tmpl": tmpls.HTMLEngine{}},
+			},
+			glob:     "mail.txt.tmpl",
+			data:     map[string]any{"Name": "Tom & Jerry", "Title": "Cat & Mouse"},
+			expected: "Hi Tom &amp; Jerry (cat-mouse)",
+		},
+		{
+			name:        "should fail on missing keys",
+			config:      tmpls.Config{MissingKeyError: true},
+			glob:        "strict.txt.tmpl",
+			data:        map[string]any{},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			config := test.config
+			config.TemplatesFS = textFS
+			config.CommonGlob = "common/*"
+			templates, err := tmpls.New(config, slog.Default())
+			if err != nil {
+				t.Fatal(err)
+			}
+			output, err := templates.Execute(test.glob, test.glob, test.data)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package tmpls
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	texttemplate "text/template"
+)
+
+// ErrUnsupported is returned by optional subsystems excluded from the build,
+// see the package documentation on build profiles.
+var ErrUnsupported = errors.New("tmpls: unsupported in this build")
+
+// ErrorKind separates template problems from data problems.
+type ErrorKind int
+
+const (
+	// ParseError means templates could not be loaded, parsed or escaped, or a
+	// template name is undefined - usually a deploy issue.
+	ParseError ErrorKind = iota + 1
+	// ExecError means a template failed while executing against its data -
+	// usually a runtime issue.
+	ExecError
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ParseError:
+		return "parse"
+	case ExecError:
+		return "exec"
+	default:
+		return "unknown"
+	}
+}
+
+// Error wraps every error returned while parsing or executing templates. The
+// underlying html/template or text/template error is available through
+// errors.As.
+type Error struct {
+	Kind     ErrorKind
+	Glob     string
+	Template string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	if e.Template == "" {
+		return fmt.Sprintf("tmpls: %s error in %q: %v", e.Kind, e.Glob, e.Err)
+	}
+	return fmt.Sprintf("tmpls: %s error in %q (%s): %v", e.Kind, e.Template, e.Glob, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsParseError reports whether err is an *Error of kind ParseError.
+func IsParseError(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == ParseError
+}
+
+// IsExecError reports whether err is an *Error of kind ExecError.
+func IsExecError(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == ExecError
+}
+
+func parseError(glob string, err error) error {
+	return &Error{Kind: ParseError, Glob: glob, Err: err}
+}
+
+// executeError classifies an error from executing a template. Only
+// text/template's ExecError stems from the data; escaping failures and
+// undefined template names are problems with the templates themselves.
+func executeError(glob string, templateName string, err error) error {
+	kind := ParseError
+	var execErr texttemplate.ExecError
+	var quotaErr *QuotaError
+	if errors.As(err, &execErr) || errors.As(err, &quotaErr) {
+		kind = ExecError
+	}
+	return &Error{Kind: kind, Glob: glob, Template: templateName, Err: err}
+}
+
+// ErrorData is the data passed to Config.ErrorTemplate.
+type ErrorData struct {
+	Err      error
+	Glob     string
+	Template string
+	Data     any
+}
+
+// executeErrorTemplate replaces the partial output of a failed execution
+// with Config.ErrorTemplate. The original error is logged, and only returned
+// if the error template fails too.
+func (t *Templates) executeErrorTemplate(
+	ctx context.Context,
+	tmpl Executor,
+	buffer *bytes.Buffer,
+	glob string,
+	templateName string,
+	data any,
+	err error,
+) error {
+	buffer.Reset()
+	errorData := ErrorData{Err: err, Glob: glob, Template: templateName, Data: data}
+	if fallbackErr := executeTemplate(
+		ctx,
+		tmpl,
+		buffer,
+		t.config.ErrorTemplate,
+		errorData,
+	); fallbackErr != nil {
+		return errors.Join(err, executeError(glob, t.config.ErrorTemplate, fallbackErr))
+	}
+	t.log().Error(
+		"Template execution failed, rendered error template",
+		"error", err,
+		"errorTemplate", t.config.ErrorTemplate,
+	)
+	return nil
+}
@@ -0,0 +1,174 @@
+package tmpls_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+	texttemplate "text/template"
+
+	"github.com/fivethirty/tmpls"
+)
+
+var errorsFS = fstest.MapFS{
+	"broken.html.tmpl": &fstest.MapFile{
+		Data: []byte(`{{ if }}`),
+	},
+	"field.html.tmpl": &fstest.MapFile{
+		Data: []byte(`{{ .Missing }}`),
+	},
+	"func.html.tmpl": &fstest.MapFile{
+		Data: []byte(`{{ randAlpha -1 }}`),
+	},
+	"common/empty.html.tmpl": &fstest.MapFile{},
+}
+
+func TestErrorKinds(t *testing.T) {
+	t.Parallel()
+
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: errorsFS,
+			CommonGlob:  "common/*.html.tmpl",
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		glob            string
+		template        string
+		expectParse     bool
+		expectExecError bool
+	}{
+		{
+			name:        "should classify syntax errors as parse errors",
+			glob:        "broken.html.tmpl",
+			template:    "broken.html.tmpl",
+			expectParse: true,
+		},
+		{
+			name:        "should classify missing globs as parse errors",
+			glob:        "missing.html.tmpl",
+			template:    "missing.html.tmpl",
+			expectParse: true,
+		},
+		{
+			name:        "should classify undefined templates as parse errors",
+			glob:        "field.html.tmpl",
+			template:    "undefined.html.tmpl",
+			expectParse: true,
+		},
+		{
+			name:            "should classify bad data as exec errors",
+			glob:            "field.html.tmpl",
+			template:        "field.html.tmpl",
+			expectExecError: true,
+		},
+		{
+			name:            "should classify func errors as exec errors",
+			glob:            "func.html.tmpl",
+			template:        "func.html.tmpl",
+			expectExecError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := templates.Execute(test.glob, test.template, struct{}{})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := tmpls.IsParseError(err); got != test.expectParse {
+				t.Fatalf("expected IsParseError=%v, got %v", test.expectParse, err)
+			}
+			if got := tmpls.IsExecError(err); got != test.expectExecError {
+				t.Fatalf("expected IsExecError=%v, got %v", test.expectExecError, err)
+			}
+			var execErr texttemplate.ExecError
+			if got := errors.As(err, &execErr); got != test.expectExecError {
+				t.Fatalf("expected wrapped ExecError=%v, got %v", test.expectExecError, err)
+			}
+		})
+	}
+}
+
+func TestErrorTemplate(t *testing.T) {
+	t.Parallel()
+
+	fallbackFS := fstest.MapFS{
+		"page.html.tmpl": &fstest.MapFile{
+			Data: []byte(`before {{ .Missing }} after`),
+		},
+		"broken.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ if }}`),
+		},
+		"common/error.html.tmpl": &fstest.MapFile{
+			Data: []byte(
+				`{{ define "error" }}failed {{ .Template }}{{ end }}` +
+					`{{ define "broken-error" }}{{ .Nope }}{{ end }}`,
+			),
+		},
+	}
+
+	tests := []struct {
+		name          string
+		errorTemplate string
+		glob          string
+		expected      string
+		expectError   bool
+	}{
+		{
+			name:          "should render error template on exec error",
+			errorTemplate: "error",
+			glob:          "page.html.tmpl",
+			expected:      "failed page.html.tmpl",
+		},
+		{
+			name:          "should return error when error template fails",
+			errorTemplate: "broken-error",
+			glob:          "page.html.tmpl",
+			expectError:   true,
+		},
+		{
+			name:          "should not render error template on parse error",
+			errorTemplate: "error",
+			glob:          "broken.html.tmpl",
+			expectError:   true,
+		},
+		{
+			name:        "should return error without error template",
+			glob:        "page.html.tmpl",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS:   fallbackFS,
+					CommonGlob:    "common/*.html.tmpl",
+					ErrorTemplate: test.errorTemplate,
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			output, err := templates.Execute(test.glob, test.glob, struct{}{})
+			if test.expectError != (err != nil) {
+				t.Fatalf("expectError=%v, got %v", test.expectError, err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package tmpls
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+)
+
+type funcsKey struct{}
+
+// ExecuteWithFuncs is ExecuteContext with funcs replacing funcs of the same
+// name for this execution only, e.g. helpers closing over request state:
+//
+//	templates.ExecuteWithFuncs(ctx, glob, name, data, template.FuncMap{
+//		"can": func(action string) bool { return policy.Allows(user, action) },
+//	})
+//
+// Templates are parsed before funcs are known, so each must also be in
+// Config.Funcs or be built in, e.g. with a placeholder. The execution uses a
+// fresh clone of the cached template set, leaving the cache untouched. Only
+// HTMLEngine templates support it.
+func (t *Templates) ExecuteWithFuncs(
+	ctx context.Context,
+	glob string,
+	templateName string,
+	data any,
+	funcs template.FuncMap,
+) (string, error) {
+	if err := checkFuncs(funcs); err != nil {
+		return "", err
+	}
+	declared := t.builtinFuncs()
+	for name := range funcs {
+		if _, ok := declared[name]; !ok {
+			return "", fmt.Errorf("Funcs: %q isn't in Config.Funcs or built in", name)
+		}
+	}
+	buffer := t.buffers.Get().(*bytes.Buffer)
+	defer func() {
+		buffer.Reset()
+		t.buffers.Put(buffer)
+	}()
+	ctx = context.WithValue(withExecution(ctx), funcsKey{}, funcs)
+	if err := t.execute(ctx, buffer, glob, templateName, data); err != nil {
+		return "", err
+	}
+	if t.config.TrackUsage {
+		t.recordUsage(glob, templateName)
+	}
+	return buffer.String(), nil
+}
+
+// funcsFrom returns the funcs passed to ExecuteWithFuncs, or nil.
+func funcsFrom(ctx context.Context) template.FuncMap {
+	funcs, _ := ctx.Value(funcsKey{}).(template.FuncMap)
+	return funcs
+}
@@ -0,0 +1,94 @@
+package tmpls_test
+
+import (
+	"context"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestExecuteWithFuncs(t *testing.T) {
+	t.Parallel()
+
+	templates := newTemplates(
+		t,
+		tmpls.Config{
+			Funcs: template.FuncMap{"user": func() string { return "nobody" }},
+		},
+		`{{ user }} {{ within 1s }}{{ user }}{{ end }} {{ uuid }}`,
+	)
+	execute := func(funcs template.FuncMap) (string, error) {
+		return templates.ExecuteWithFuncs(
+			context.Background(),
+			"page.html.tmpl",
+			"page.html.tmpl",
+			nil,
+			funcs,
+		)
+	}
+
+	tests := []struct {
+		name        string
+		funcs       template.FuncMap
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "should use funcs for the execution",
+			funcs: template.FuncMap{
+				"user": func() string { return "ada" },
+				"uuid": func() string { return "id" },
+			},
+			expected: "ada ada id",
+		},
+		{
+			name: "should bind context funcs",
+			funcs: template.FuncMap{
+				"user": tmpls.ContextFunc(func(ctx context.Context) any {
+					return func() string { return "grace" }
+				}),
+				"uuid": func() string { return "id" },
+			},
+			expected: "grace grace id",
+		},
+		{
+			name:        "should fail on funcs templates can't call",
+			funcs:       template.FuncMap{"undeclared": func() string { return "" }},
+			expectError: true,
+		},
+		{
+			name:        "should fail on invalid funcs",
+			funcs:       template.FuncMap{"user": "ada"},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, err := execute(test.funcs)
+			if test.expectError {
+				if err == nil {
+					t.Fatalf("expected error but got %s", output)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+
+	// the cached set keeps its funcs
+	output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "nobody nobody "; !strings.HasPrefix(output, expected) {
+		t.Fatalf("expected %s but got %s", expected, output)
+	}
+}
@@ -0,0 +1,67 @@
+package tmpls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Part is one template executed by ExecuteMany.
+type Part struct {
+	// Key names the part's output. Defaults to Template.
+	Key      string
+	Glob     string
+	Template string
+	Data     any
+}
+
+func (p Part) key() string {
+	if p.Key == "" {
+		return p.Template
+	}
+	return p.Key
+}
+
+// ExecuteMany executes several templates for one request, such as the
+// fragments of an htmx out-of-band swap or the blocks of an email, returning
+// their output by key. With concurrent set, parts execute in parallel. Every
+// part is executed even if some fail; their errors are joined and the output
+// of the parts that succeeded is still returned.
+func (t *Templates) ExecuteMany(
+	ctx context.Context,
+	parts []Part,
+	concurrent bool,
+) (map[string]string, error) {
+	seen := make(map[string]bool, len(parts))
+	for _, part := range parts {
+		if seen[part.key()] {
+			return nil, fmt.Errorf("duplicate part key %q", part.key())
+		}
+		seen[part.key()] = true
+	}
+	outputs := make([]string, len(parts))
+	errs := make([]error, len(parts))
+	if concurrent {
+		var wg sync.WaitGroup
+		wg.Add(len(parts))
+		for i, part := range parts {
+			go func() {
+				defer wg.Done()
+				outputs[i], errs[i] = t.ExecuteContext(ctx, part.Glob, part.Template, part.Data)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i, part := range parts {
+			outputs[i], errs[i] = t.ExecuteContext(ctx, part.Glob, part.Template, part.Data)
+		}
+	}
+	result := make(map[string]string, len(parts))
+	for i, part := range parts {
+		if errs[i] == nil {
+			result[part.key()] = outputs[i]
+		}
+	}
+	return result, errors.Join(errs...)
+}
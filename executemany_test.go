@@ -0,0 +1,84 @@
+package tmpls_test
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestExecuteMany(t *testing.T) {
+	t.Parallel()
+
+	src := `{{ define "count" }}<span id="count">{{ . }}</span>{{ end }}` +
+		`{{ define "item" }}<li>{{ . }}</li>{{ end }}`
+
+	tests := []struct {
+		name        string
+		parts       []tmpls.Part
+		concurrent  bool
+		expected    map[string]string
+		expectError bool
+	}{
+		{
+			name: "should execute parts by key",
+			parts: []tmpls.Part{
+				{Glob: "page.html.tmpl", Template: "count", Data: 2},
+				{Key: "first", Glob: "page.html.tmpl", Template: "item", Data: "<a>"},
+				{Key: "second", Glob: "page.html.tmpl", Template: "item", Data: "b"},
+			},
+			expected: map[string]string{
+				"count":  `<span id="count">2</span>`,
+				"first":  `<li>&lt;a&gt;</li>`,
+				"second": `<li>b</li>`,
+			},
+		},
+		{
+			name: "should execute parts concurrently",
+			parts: []tmpls.Part{
+				{Glob: "page.html.tmpl", Template: "count", Data: 2},
+				{Glob: "page.html.tmpl", Template: "item", Data: "a"},
+			},
+			concurrent: true,
+			expected: map[string]string{
+				"count": `<span id="count">2</span>`,
+				"item":  `<li>a</li>`,
+			},
+		},
+		{
+			name: "should return successful parts with errors",
+			parts: []tmpls.Part{
+				{Glob: "page.html.tmpl", Template: "count", Data: 2},
+				{Glob: "page.html.tmpl", Template: "missing"},
+			},
+			concurrent: true,
+			expected: map[string]string{
+				"count": `<span id="count">2</span>`,
+			},
+			expectError: true,
+		},
+		{
+			name: "should fail for duplicate keys",
+			parts: []tmpls.Part{
+				{Glob: "page.html.tmpl", Template: "item", Data: "a"},
+				{Glob: "page.html.tmpl", Template: "item", Data: "b"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, tmpls.Config{}, src)
+			output, err := templates.ExecuteMany(context.Background(), test.parts, test.concurrent)
+			if test.expectError != (err != nil) {
+				t.Fatalf("expected error=%v but got %v", test.expectError, err)
+			}
+			if !maps.Equal(output, test.expected) {
+				t.Fatalf("expected %v but got %v", test.expected, output)
+			}
+		})
+	}
+}
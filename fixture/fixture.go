@@ -0,0 +1,155 @@
+// Package fixture loads sample data kept next to templates. A template's
+// fixture is a sibling file named after it plus a decoder extension, e.g.
+// page.html.tmpl.json, and is used by the preview server, the tmpls CLI and
+// golden tests. Further variants insert a name before the extension, e.g.
+// page.html.tmpl.empty.json.
+package fixture
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Decoder decodes a fixture file's contents into v.
+type Decoder func(data []byte, v any) error
+
+var (
+	mu       sync.RWMutex
+	decoders = map[string]Decoder{".json": json.Unmarshal}
+)
+
+// Register makes fixtures ending in ext, e.g. ".yaml", decode with decode.
+// JSON is registered by default.
+func Register(ext string, decode Decoder) {
+	mu.Lock()
+	defer mu.Unlock()
+	decoders[ext] = decode
+}
+
+// Load decodes the fixture for template. ok is false if it has none.
+func Load(fsys fs.FS, template string) (any, bool, error) {
+	mu.RLock()
+	exts := make([]string, 0, len(decoders))
+	for ext := range decoders {
+		exts = append(exts, ext)
+	}
+	mu.RUnlock()
+	slices.Sort(exts)
+
+	for _, ext := range exts {
+		file := template + ext
+		contents, err := fs.ReadFile(fsys, file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		data, err := Decode(file, contents)
+		if err != nil {
+			return nil, false, err
+		}
+		return data, true, nil
+	}
+	return nil, false, nil
+}
+
+// Decode decodes contents with the decoder registered for file's extension.
+func Decode(file string, contents []byte) (any, error) {
+	_, decode := decoder(file)
+	if decode == nil {
+		return nil, fmt.Errorf("no fixture decoder registered for %s", file)
+	}
+	var data any
+	if err := decode(contents, &data); err != nil {
+		return nil, fmt.Errorf("decoding fixture %s: %w", file, err)
+	}
+	return data, nil
+}
+
+// IsFixture reports whether file has a registered fixture extension, so
+// listings of templates can skip it.
+func IsFixture(file string) bool {
+	_, decode := decoder(file)
+	return decode != nil
+}
+
+// Variant is one named set of sample data for a template. The fixture
+// page.html.tmpl.json is the variant named "default" and
+// page.html.tmpl.empty.json is named "empty".
+type Variant struct {
+	Name string
+	Data any
+}
+
+// DefaultVariant names the data in a template's plain fixture.
+const DefaultVariant = "default"
+
+// Variants decodes every fixture for template, default first and the rest
+// sorted by name.
+func Variants(fsys fs.FS, template string) ([]Variant, error) {
+	entries, err := fs.ReadDir(fsys, path.Dir(template))
+	if err != nil {
+		return nil, err
+	}
+	var variants []Variant
+	for _, entry := range entries {
+		file := path.Join(path.Dir(template), entry.Name())
+		rest, ok := strings.CutPrefix(file, template)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		ext, _ := decoder(file)
+		name, ok := strings.CutSuffix(rest, ext)
+		if ext == "" || !ok {
+			continue
+		}
+		switch {
+		case name == "":
+			name = DefaultVariant
+		case strings.HasPrefix(name, "."):
+			name = name[1:]
+		default:
+			continue
+		}
+		contents, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		data, err := Decode(file, contents)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, Variant{Name: name, Data: data})
+	}
+	slices.SortFunc(variants, func(a, b Variant) int {
+		if (a.Name == DefaultVariant) != (b.Name == DefaultVariant) {
+			if a.Name == DefaultVariant {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return variants, nil
+}
+
+// decoder returns the longest registered extension file ends in and its
+// decoder.
+func decoder(file string) (string, Decoder) {
+	mu.RLock()
+	defer mu.RUnlock()
+	var match string
+	for ext := range decoders {
+		if strings.HasSuffix(file, ext) && len(ext) > len(match) {
+			match = ext
+		}
+	}
+	return match, decoders[match]
+}
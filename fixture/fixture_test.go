@@ -0,0 +1,138 @@
+package fixture_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls/fixture"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	fixture.Register(".kv", func(data []byte, v any) error {
+		key, value, _ := strings.Cut(strings.TrimSpace(string(data)), "=")
+		*v.(*any) = map[string]any{key: value}
+		return nil
+	})
+
+	fsys := fstest.MapFS{
+		"page.html.tmpl":        &fstest.MapFile{},
+		"page.html.tmpl.json":   &fstest.MapFile{Data: []byte(`{"Title": "Home", "Count": 2}`)},
+		"other.html.tmpl":       &fstest.MapFile{},
+		"other.html.tmpl.kv":    &fstest.MapFile{Data: []byte("Title=Other\n")},
+		"broken.html.tmpl.json": &fstest.MapFile{Data: []byte(`{`)},
+	}
+
+	tests := []struct {
+		name        string
+		template    string
+		expected    any
+		expectOK    bool
+		expectError bool
+	}{
+		{
+			name:     "should decode json fixture",
+			template: "page.html.tmpl",
+			expected: map[string]any{"Title": "Home", "Count": float64(2)},
+			expectOK: true,
+		},
+		{
+			name:     "should decode fixture with registered decoder",
+			template: "other.html.tmpl",
+			expected: map[string]any{"Title": "Other"},
+			expectOK: true,
+		},
+		{
+			name:     "should report missing fixture",
+			template: "missing.html.tmpl",
+		},
+		{
+			name:        "should return decode error",
+			template:    "broken.html.tmpl",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			data, ok, err := fixture.Load(fsys, test.template)
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != test.expectOK {
+				t.Fatalf("expected %t but got %t", test.expectOK, ok)
+			}
+			if !reflect.DeepEqual(data, test.expected) {
+				t.Fatalf("expected %v but got %v", test.expected, data)
+			}
+		})
+	}
+}
+
+func TestIsFixture(t *testing.T) {
+	t.Parallel()
+
+	if !fixture.IsFixture("page.html.tmpl.json") {
+		t.Fatal("expected json file to be a fixture")
+	}
+	if fixture.IsFixture("page.html.tmpl") {
+		t.Fatal("expected template not to be a fixture")
+	}
+}
+
+func TestVariants(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"components/card.html.tmpl":            &fstest.MapFile{},
+		"components/card.html.tmpl.json":       &fstest.MapFile{Data: []byte(`"default"`)},
+		"components/card.html.tmpl.long.json":  &fstest.MapFile{Data: []byte(`"long"`)},
+		"components/card.html.tmpl.empty.json": &fstest.MapFile{Data: []byte(`""`)},
+		"components/card.html.tmplx.json":      &fstest.MapFile{Data: []byte(`"other"`)},
+		"components/cards.html.tmpl.json":      &fstest.MapFile{Data: []byte(`"other"`)},
+		"components/plain.html.tmpl":           &fstest.MapFile{},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected []fixture.Variant
+	}{
+		{
+			name:     "should list default first then by name",
+			template: "components/card.html.tmpl",
+			expected: []fixture.Variant{
+				{Name: fixture.DefaultVariant, Data: "default"},
+				{Name: "empty", Data: ""},
+				{Name: "long", Data: "long"},
+			},
+		},
+		{
+			name:     "should list no variants without fixtures",
+			template: "components/plain.html.tmpl",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			variants, err := fixture.Variants(fsys, test.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(variants, test.expected) {
+				t.Fatalf("expected %v but got %v", test.expected, variants)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package tmpls
+
+import "context"
+
+// Flash is a one-time message, such as a success or error banner.
+type Flash struct {
+	Kind    string
+	Message string
+}
+
+// FlashProvider pulls the flash messages for the request carried by ctx,
+// usually removing them from the session so they show only once.
+type FlashProvider interface {
+	Flashes(ctx context.Context) ([]Flash, error)
+}
+
+// FlashFunc adapts an ordinary function to a FlashProvider.
+type FlashFunc func(ctx context.Context) ([]Flash, error)
+
+func (f FlashFunc) Flashes(ctx context.Context) ([]Flash, error) {
+	return f(ctx)
+}
+
+// flash pulls messages at most once per execution, so layouts and pages can
+// both call it without losing messages.
+func (t *Templates) flash(ctx context.Context) any {
+	return func() ([]Flash, error) {
+		if t.config.Flash == nil {
+			return nil, nil
+		}
+		execution := executionFrom(ctx)
+		if execution == nil {
+			return t.config.Flash.Flashes(ctx)
+		}
+		execution.flashOnce.Do(func() {
+			execution.flashes, execution.flashErr = t.config.Flash.Flashes(ctx)
+		})
+		return execution.flashes, execution.flashErr
+	}
+}
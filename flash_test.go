@@ -0,0 +1,79 @@
+package tmpls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+type sessionKey struct{}
+
+// sessionFlashes pops flashes from a session map carried by the context.
+func sessionFlashes(ctx context.Context) ([]tmpls.Flash, error) {
+	session, _ := ctx.Value(sessionKey{}).(map[string][]tmpls.Flash)
+	flashes := session["flashes"]
+	delete(session, "flashes")
+	return flashes, nil
+}
+
+func TestFlash(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		provider    tmpls.FlashProvider
+		src         string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should render flashes once per execution",
+			provider: tmpls.FlashFunc(sessionFlashes),
+			src: `{{ range flash }}<p class="{{ .Kind }}">{{ .Message }}</p>{{ end }}` +
+				`{{ len flash }}`,
+			expected: `<p class="success">Saved</p><p class="error">&lt;oops&gt;</p>2`,
+		},
+		{
+			name:     "should render nothing without provider",
+			src:      `{{ range flash }}{{ .Message }}{{ end }}`,
+			expected: "",
+		},
+		{
+			name: "should return provider error",
+			provider: tmpls.FlashFunc(func(context.Context) ([]tmpls.Flash, error) {
+				return nil, errors.New("session unavailable")
+			}),
+			src:         `{{ range flash }}{{ .Message }}{{ end }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, tmpls.Config{Flash: test.provider}, test.src)
+			session := map[string][]tmpls.Flash{
+				"flashes": {
+					{Kind: "success", Message: "Saved"},
+					{Kind: "error", Message: "<oops>"},
+				},
+			}
+			ctx := context.WithValue(context.Background(), sessionKey{}, session)
+			output, err := templates.ExecuteContext(ctx, "page.html.tmpl", "page.html.tmpl", nil)
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
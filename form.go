@@ -0,0 +1,121 @@
+package tmpls
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Form carries a submitted form's values and validation errors back to the
+// template redisplaying it, for the input and fieldErrors funcs.
+type Form struct {
+	Values url.Values
+	// Errors holds the validation messages of each field.
+	Errors map[string][]string
+}
+
+// NewForm returns a Form holding the values posted in r.
+func NewForm(r *http.Request) (*Form, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	return &Form{Values: r.PostForm}, nil
+}
+
+// AddError records a validation message for field.
+func (f *Form) AddError(field string, message string) {
+	if f.Errors == nil {
+		f.Errors = map[string][]string{}
+	}
+	f.Errors[field] = append(f.Errors[field], message)
+}
+
+// Valid reports whether no errors were recorded.
+func (f *Form) Valid() bool {
+	return f == nil || len(f.Errors) == 0
+}
+
+// Value returns the submitted value of field, or "".
+func (f *Form) Value(field string) string {
+	if f == nil {
+		return ""
+	}
+	return f.Values.Get(field)
+}
+
+// FieldErrors returns the validation messages of field.
+func (f *Form) FieldErrors(field string) []string {
+	if f == nil {
+		return nil
+	}
+	return f.Errors[field]
+}
+
+// input renders an input for field, prefilled with its submitted value and
+// marked invalid if it has errors. Further attributes are given as pairs,
+// as for attrs:
+//
+//	{{ input .Form "email" "type" "email" "required" true }}
+//
+// Passwords are never prefilled, and checkboxes and radios are checked if
+// their value was submitted. A nil form renders an empty input.
+func input(form *Form, field string, pairs ...any) (template.HTML, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("input requires name-value pairs but got %d arguments", len(pairs))
+	}
+	options := map[string]any{"type": "text", "id": field}
+	var rest []any
+	for i := 0; i < len(pairs); i += 2 {
+		switch name, _ := pairs[i].(string); name {
+		case "type", "id", "value":
+			options[name] = pairs[i+1]
+		default:
+			rest = append(rest, pairs[i], pairs[i+1])
+		}
+	}
+	typ := fmt.Sprint(options["type"])
+	built := []any{"type", typ, "name", field, "id", options["id"]}
+	switch typ {
+	case "password":
+	case "checkbox", "radio":
+		value, ok := options["value"]
+		if !ok {
+			value = "on"
+		}
+		submitted := form != nil && slices.Contains(form.Values[field], fmt.Sprint(value))
+		built = append(built, "value", value, "checked", submitted)
+	default:
+		value, ok := options["value"]
+		if submitted := form.Value(field); submitted != "" || !ok {
+			value = submitted
+		}
+		built = append(built, "value", value)
+	}
+	if len(form.FieldErrors(field)) > 0 {
+		built = append(built, "aria-invalid", "true", "aria-describedby", field+"-errors")
+	}
+	attributes, err := attrs(append(built, rest...)...)
+	if err != nil {
+		return "", fmt.Errorf("input: %w", err)
+	}
+	return template.HTML("<input " + string(attributes) + ">"), nil
+}
+
+// fieldErrors renders the validation messages of field as a list referenced
+// by its input's aria-describedby, or nothing if it has none.
+func fieldErrors(form *Form, field string) template.HTML {
+	messages := form.FieldErrors(field)
+	if len(messages) == 0 {
+		return ""
+	}
+	var s strings.Builder
+	fmt.Fprintf(&s, `<ul id="%s" class="field-errors">`, template.HTMLEscapeString(field+"-errors"))
+	for _, message := range messages {
+		fmt.Fprintf(&s, `<li>%s</li>`, template.HTMLEscapeString(message))
+	}
+	s.WriteString(`</ul>`)
+	return template.HTML(s.String())
+}
@@ -0,0 +1,115 @@
+package tmpls_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestNewForm(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest("POST", "/signup?ignored=1", strings.NewReader("email=a%40b.c"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	form, err := tmpls.NewForm(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.Value("email") != "a@b.c" || form.Value("ignored") != "" {
+		t.Fatalf("expected posted values but got %v", form.Values)
+	}
+	if !form.Valid() {
+		t.Fatal("expected valid form")
+	}
+	form.AddError("email", "taken")
+	if form.Valid() || form.FieldErrors("email")[0] != "taken" {
+		t.Fatalf("expected error but got %v", form.Errors)
+	}
+}
+
+func TestFormFuncs(t *testing.T) {
+	t.Parallel()
+
+	form := &tmpls.Form{
+		Values: url.Values{
+			"email":    {`a"b@c.d`},
+			"password": {"secret"},
+			"terms":    {"on"},
+			"plan":     {"pro"},
+		},
+		Errors: map[string][]string{"email": {"is invalid", "<is> taken"}},
+	}
+
+	tests := []struct {
+		name        string
+		src         string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "should prefill and mark invalid",
+			src:  `{{ input . "email" "type" "email" "required" true }}`,
+			data: form,
+			expected: `<input type="email" name="email" id="email" value="a&#34;b@c.d" ` +
+				`aria-invalid="true" aria-describedby="email-errors" required>`,
+		},
+		{
+			name:     "should not prefill passwords",
+			src:      `{{ input . "password" "type" "password" }}`,
+			data:     form,
+			expected: `<input type="password" name="password" id="password">`,
+		},
+		{
+			name: "should check submitted options",
+			src: `{{ input . "terms" "type" "checkbox" }}` +
+				`{{ input . "plan" "type" "radio" "value" "pro" "id" "plan-pro" }}` +
+				`{{ input . "plan" "type" "radio" "value" "free" "id" "plan-free" }}`,
+			data: form,
+			expected: `<input type="checkbox" name="terms" id="terms" value="on" checked>` +
+				`<input type="radio" name="plan" id="plan-pro" value="pro" checked>` +
+				`<input type="radio" name="plan" id="plan-free" value="free">`,
+		},
+		{
+			name:     "should render empty input without form",
+			src:      `{{ input .Form "name" "value" "default" }}`,
+			data:     map[string]any{},
+			expected: `<input type="text" name="name" id="name" value="default">`,
+		},
+		{
+			name: "should render field errors",
+			src:  `{{ fieldErrors . "email" }}{{ fieldErrors . "password" }}`,
+			data: form,
+			expected: `<ul id="email-errors" class="field-errors">` +
+				`<li>is invalid</li><li>&lt;is&gt; taken</li></ul>`,
+		},
+		{
+			name:        "should fail for unsafe attributes",
+			src:         `{{ input . "email" "onfocus" "alert(1)" }}`,
+			data:        form,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{}, test.src, test.data)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package tmpls
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// transliterations spell lowercase Latin letters with diacritics in ASCII
+// for slugify.
+var transliterations = func() map[rune]string {
+	m := map[rune]string{}
+	for ascii, letters := range map[string]string{
+		"a":  "àáâãäåāăą",
+		"c":  "çćĉċč",
+		"d":  "ďđð",
+		"e":  "èéêëēĕėęě",
+		"g":  "ĝğġģ",
+		"h":  "ĥħ",
+		"i":  "ìíîïĩīĭįı",
+		"j":  "ĵ",
+		"k":  "ķ",
+		"l":  "ĺļľŀł",
+		"n":  "ñńņňŉ",
+		"o":  "òóôõöøōŏő",
+		"r":  "ŕŗř",
+		"s":  "śŝşš",
+		"t":  "ţťŧ",
+		"u":  "ùúûüũūŭůűų",
+		"w":  "ŵ",
+		"y":  "ýÿŷ",
+		"z":  "źżž",
+		"ae": "æ",
+		"oe": "œ",
+		"ss": "ß",
+		"th": "þ",
+	} {
+		for _, letter := range letters {
+			m[letter] = ascii
+		}
+	}
+	return m
+}()
+
+// germanTransliterations replace the umlauts for German locales, where
+// "Müller" is spelled "Mueller" without them.
+var germanTransliterations = map[rune]string{'ä': "ae", 'ö': "oe", 'ü': "ue"}
+
+// localeLanguage returns the language of the RenderContext's locale, e.g.
+// "pt" for "pt-BR".
+func localeLanguage(ctx context.Context) string {
+	rc := RenderContextFrom(ctx)
+	if rc == nil {
+		return ""
+	}
+	language, _, _ := strings.Cut(strings.ReplaceAll(rc.Locale, "_", "-"), "-")
+	return strings.ToLower(language)
+}
+
+// localeCase returns the case mapping of the RenderContext's locale, which
+// differs from the default for Turkish and Azeri dotted and dotless i.
+func localeCase(ctx context.Context) unicode.SpecialCase {
+	switch localeLanguage(ctx) {
+	case "tr", "az":
+		return unicode.TurkishCase
+	default:
+		return nil
+	}
+}
+
+// slugify returns s as a URL slug, lowercased in the RenderContext's locale
+// with Latin diacritics spelled in ASCII, and runs of anything but letters
+// and digits replaced by single hyphens:
+//
+//	<a href="/posts/{{ slugify .Title }}">
+//
+// Letters of other scripts are kept.
+func slugify(ctx context.Context) any {
+	return func(s string) string {
+		german := localeLanguage(ctx) == "de"
+		var out strings.Builder
+		hyphen := false
+		for _, r := range strings.ToLowerSpecial(localeCase(ctx), s) {
+			spelled, ok := germanTransliterations[r]
+			if !ok || !german {
+				spelled, ok = transliterations[r]
+			}
+			switch {
+			case ok:
+			case unicode.IsLetter(r) || unicode.IsDigit(r):
+				spelled = string(r)
+			default:
+				hyphen = out.Len() > 0
+				continue
+			}
+			if hyphen {
+				out.WriteByte('-')
+				hyphen = false
+			}
+			out.WriteString(spelled)
+		}
+		return out.String()
+	}
+}
+
+// initials returns the uppercased first letters of the first and last words
+// of name, e.g. "AL" for "Ada King Lovelace", for avatars. Words not starting
+// with a letter are skipped.
+func initials(ctx context.Context) any {
+	return func(name string) string {
+		var letters []rune
+		for word := range strings.FieldsSeq(name) {
+			if r := []rune(word)[0]; unicode.IsLetter(r) {
+				letters = append(letters, r)
+			}
+		}
+		if len(letters) > 2 {
+			letters = []rune{letters[0], letters[len(letters)-1]}
+		}
+		return strings.ToUpperSpecial(localeCase(ctx), string(letters))
+	}
+}
+
+// mask hides all but the last visible characters of s behind four asterisks,
+// whatever its length, so neither the hidden part nor its length shows:
+//
+//	{{ mask 4 .CardNumber }}
+//
+// renders "****1234". Values no longer than visible are masked entirely.
+func mask(visible int, s string) string {
+	runes := []rune(s)
+	if visible <= 0 || len(runes) <= visible {
+		return "****"
+	}
+	return "****" + string(runes[len(runes)-visible:])
+}
@@ -0,0 +1,94 @@
+package tmpls_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestFormatFuncs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		locale   string
+		src      string
+		data     any
+		expected string
+	}{
+		{
+			name:     "should slugify",
+			src:      `{{ slugify . }}`,
+			data:     "  Hello, World! -- Go 1.24  ",
+			expected: "hello-world-go-1-24",
+		},
+		{
+			name:     "should transliterate diacritics",
+			src:      `{{ slugify . }}`,
+			data:     "Crème Brûlée à la Straße",
+			expected: "creme-brulee-a-la-strasse",
+		},
+		{
+			name:     "should spell umlauts for german locales",
+			locale:   "de-AT",
+			src:      `{{ slugify . }}`,
+			data:     "Müller Österreich",
+			expected: "mueller-oesterreich",
+		},
+		{
+			name:     "should keep letters of other scripts",
+			src:      `{{ slugify . }}`,
+			data:     "Привет мир",
+			expected: "привет-мир",
+		},
+		{
+			name:     "should lowercase turkish dotted capitals",
+			locale:   "tr",
+			src:      `{{ slugify . }}`,
+			data:     "İSTANBUL",
+			expected: "istanbul",
+		},
+		{
+			name: "should return initials of first and last words",
+			src: `{{ initials "ada king lovelace" }} {{ initials "Grace" }} ` +
+				`{{ initials " (x) " }}.`,
+			expected: "AL G .",
+		},
+		{
+			name:     "should uppercase initials in the locale",
+			locale:   "tr_TR",
+			src:      `{{ initials "ilkay" }}`,
+			expected: "İ",
+		},
+		{
+			name:     "should mask all but the last characters",
+			src:      `{{ mask 4 . }} {{ mask 4 "123" }} {{ mask 0 . }} {{ mask 2 "übel" }}`,
+			data:     "4111111111111111",
+			expected: "****1111 **** **** ****el",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, tmpls.Config{}, test.src)
+			ctx := tmpls.WithRenderContext(
+				context.Background(),
+				&tmpls.RenderContext{Locale: test.locale},
+			)
+			output, err := templates.ExecuteContext(
+				ctx,
+				"page.html.tmpl",
+				"page.html.tmpl",
+				test.data,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
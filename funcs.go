@@ -0,0 +1,244 @@
+package tmpls
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"go/token"
+	"html/template"
+	"io"
+	mathrand "math/rand/v2"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Deterministic holds the fixed values built-in funcs return when
+// Config.Deterministic is set.
+type Deterministic struct {
+	// Now is returned by the now func unless Config.Clock is set. Defaults to
+	// 2000-01-01T00:00:00Z.
+	Now time.Time
+	// Location is used in place of the machine's local time zone. Defaults
+	// to UTC.
+	Location *time.Location
+	// Seed seeds the source used by random funcs unless Config.Rand is set.
+	Seed uint64
+}
+
+var defaultDeterministicNow = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func (t *Templates) builtinFuncs() template.FuncMap {
+	funcs := template.FuncMap{
+		"now":               t.clock.Now,
+		"date":              t.date,
+		"uuid":              t.uuid,
+		"randAlpha":         t.randAlpha,
+		"metaTags":          t.metaTags,
+		"breadcrumbs":       Breadcrumbs.HTML,
+		"breadcrumbsJSONLD": Breadcrumbs.JSONLD,
+		"props":             props,
+		"dict":              dict,
+		"list":              list,
+		"merge":             merge,
+		"set":               set,
+		"default":           defaultValue,
+		"coalesce":          coalesce,
+		"ternary":           ternary,
+		"humanBytes":        humanBytes,
+		"humanDuration":     humanDuration,
+		"timeAgo":           t.timeAgo,
+		"truncateHTML":      truncateHTML,
+		"truncate":          truncate,
+		"emoji":             t.emoji,
+		"classes":           classes,
+		"when":              when,
+		"attrs":             attrs,
+		"url":               t.url,
+		"qs":                qs,
+		"pagination":        Paginator.HTML,
+		"table":             Table.HTML,
+		"input":             input,
+		"fieldErrors":       fieldErrors,
+		"json":              jsonScript,
+		"dataAttr":          dataAttr,
+		"safeURL":           t.safeURL,
+		"safeMailto":        t.safeMailto,
+		"sourceComment":     sourceComment,
+		"buildInfo":         func() BuildInfo { return t.build },
+		"buildVersion":      func() string { return t.build.Version },
+		"env":               t.env,
+		"chunk":             chunk,
+		"batch":             batch,
+		"groupBy":           groupBy,
+		"sortBy":            sortBy,
+		"first":             first,
+		"last":              last,
+		"indexOf":           indexOf,
+		"contains":          contains,
+		"add":               add,
+		"sub":               sub,
+		"mul":               mul,
+		"div":               div,
+		"mod":               mod,
+		"percent":           percent,
+		"mask":              mask,
+		"lighten":           lighten,
+		"darken":            darken,
+		"contrastRatio":     contrastRatio,
+		"contrastText":      contrastText,
+		"qrCode":            t.qrCode,
+		"avatar":            t.avatar,
+		"jsonLD":            jsonLD,
+		"diff":              diff,
+		"highlight":         t.highlight,
+		"mailtoLink":        t.mailtoLink,
+		"telLink":           t.telLink,
+	}
+	for name, fn := range t.contextFuncs() {
+		funcs[name] = fn
+	}
+	for name, fn := range t.config.Funcs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// checkFuncs reports funcs text/template would panic on, so New can fail
+// instead.
+func checkFuncs(funcs template.FuncMap) error {
+	for name, fn := range funcs {
+		if !token.IsIdentifier(name) {
+			return fmt.Errorf("Funcs: invalid name %q", name)
+		}
+		typ := reflect.TypeOf(fn)
+		if typ == nil || typ.Kind() != reflect.Func {
+			return fmt.Errorf("Funcs: %q is %T, not a func", name, fn)
+		}
+		switch {
+		case typ.NumOut() == 1:
+		case typ.NumOut() == 2 && typ.Out(1) == errorType:
+		default:
+			return fmt.Errorf("Funcs: %q must return a value and optional error", name)
+		}
+	}
+	return nil
+}
+
+// Clock is the source of the current time for built-in funcs.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts an ordinary function to a Clock.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+func defaultClock(d *Deterministic) Clock {
+	if d == nil {
+		return ClockFunc(time.Now)
+	}
+	now := d.Now
+	if now.IsZero() {
+		now = defaultDeterministicNow
+	}
+	return ClockFunc(func() time.Time {
+		return now
+	})
+}
+
+// date formats value, a time.Time or *time.Time, with layout in the local
+// time zone.
+func (t *Templates) date(layout string, value any) (string, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.In(t.location()).Format(layout), nil
+	case *time.Time:
+		if v == nil {
+			return "", nil
+		}
+		return v.In(t.location()).Format(layout), nil
+	default:
+		return "", fmt.Errorf("date: unsupported type %T", value)
+	}
+}
+
+func (t *Templates) location() *time.Location {
+	d := t.config.Deterministic
+	if d == nil {
+		return time.Local
+	}
+	if d.Location == nil {
+		return time.UTC
+	}
+	return d.Location
+}
+
+func defaultRand(d *Deterministic) io.Reader {
+	if d == nil {
+		return rand.Reader
+	}
+	var seed [32]byte
+	binary.LittleEndian.PutUint64(seed[:], d.Seed)
+	return &lockedReader{reader: mathrand.NewChaCha8(seed)}
+}
+
+// lockedReader makes a reader safe for concurrent use.
+type lockedReader struct {
+	mu     sync.Mutex
+	reader io.Reader
+}
+
+func (r *lockedReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reader.Read(p)
+}
+
+// uuid returns a random (version 4) UUID.
+func (t *Templates) uuid() (string, error) {
+	var b [16]byte
+	if _, err := io.ReadFull(t.random, b[:]); err != nil {
+		return "", fmt.Errorf("uuid: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	var out [36]byte
+	hex.Encode(out[0:8], b[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], b[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], b[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], b[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], b[10:])
+	return string(out[:]), nil
+}
+
+const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// randAlpha returns n random ASCII letters.
+func (t *Templates) randAlpha(n int) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("randAlpha: negative length %d", n)
+	}
+	out := make([]byte, 0, n)
+	buf := make([]byte, n)
+	for len(out) < n {
+		if _, err := io.ReadFull(t.random, buf); err != nil {
+			return "", fmt.Errorf("randAlpha: %w", err)
+		}
+		for _, b := range buf {
+			// reject values that would bias the modulo
+			if int(b) < 256-256%len(alphabet) && len(out) < n {
+				out = append(out, alphabet[int(b)%len(alphabet)])
+			}
+		}
+	}
+	return string(out), nil
+}
@@ -0,0 +1,316 @@
+package tmpls_test
+
+import (
+	"context"
+	"html/template"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+// newTemplates returns Templates with src as the single page template
+// page.html.tmpl and config's other fields.
+func newTemplates(t *testing.T, config tmpls.Config, src string) *tmpls.Templates {
+	t.Helper()
+	config.TemplatesFS = fstest.MapFS{
+		"page.html.tmpl":         &fstest.MapFile{Data: []byte(src)},
+		"common/empty.html.tmpl": &fstest.MapFile{},
+	}
+	config.CommonGlob = "common/*.html.tmpl"
+	templates, err := tmpls.New(config, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return templates
+}
+
+// render executes src as a single page template with config's other fields.
+func render(t *testing.T, config tmpls.Config, src string, data any) (string, error) {
+	t.Helper()
+	return newTemplates(t, config, src).Execute("page.html.tmpl", "page.html.tmpl", data)
+}
+
+func TestDeterministic(t *testing.T) {
+	t.Parallel()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		deterministic *tmpls.Deterministic
+		src           string
+		data          any
+		expected      string
+	}{
+		{
+			name:          "should return default fixed time",
+			deterministic: &tmpls.Deterministic{},
+			src:           `{{ now | date "2006-01-02T15:04:05Z07:00" }}`,
+			expected:      "2000-01-01T00:00:00Z",
+		},
+		{
+			name: "should return configured fixed time",
+			deterministic: &tmpls.Deterministic{
+				Now: time.Date(2024, time.March, 5, 10, 30, 0, 0, time.UTC),
+			},
+			src:      `{{ now | date "Jan 2 15:04" }}`,
+			expected: "Mar 5 10:30",
+		},
+		{
+			name: "should format dates in configured location",
+			deterministic: &tmpls.Deterministic{
+				Location: berlin,
+			},
+			src:      `{{ date "15:04 MST" . }}`,
+			data:     time.Date(2024, time.July, 1, 12, 0, 0, 0, time.UTC),
+			expected: "14:00 CEST",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(
+				t,
+				tmpls.Config{Deterministic: test.deterministic},
+				test.src,
+				test.data,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestDateErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := render(t, tmpls.Config{}, `{{ date "2006" . }}`, "not a time"); err == nil {
+		t.Fatal("expected error for unsupported type")
+	}
+}
+
+func TestClock(t *testing.T) {
+	t.Parallel()
+
+	fixed := time.Date(2024, time.March, 5, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		config   tmpls.Config
+		expected string
+	}{
+		{
+			name: "should use configured clock",
+			config: tmpls.Config{
+				Clock: tmpls.ClockFunc(func() time.Time { return fixed }),
+			},
+			expected: "2024-03-05 10:30",
+		},
+		{
+			name: "should prefer configured clock over deterministic time",
+			config: tmpls.Config{
+				Clock:         tmpls.ClockFunc(func() time.Time { return fixed }),
+				Deterministic: &tmpls.Deterministic{},
+			},
+			expected: "2024-03-05 10:30",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, test.config, `{{ now | date "2006-01-02 15:04" }}`, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestRandomFuncs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  tmpls.Config
+		src     string
+		pattern string
+		stable  bool
+	}{
+		{
+			name:    "should generate uuid",
+			src:     `{{ uuid }}`,
+			pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		},
+		{
+			name:    "should generate random letters",
+			src:     `{{ randAlpha 12 }}`,
+			pattern: `^[a-zA-Z]{12}$`,
+		},
+		{
+			name:    "should generate stable uuid when deterministic",
+			config:  tmpls.Config{Deterministic: &tmpls.Deterministic{Seed: 42}},
+			src:     `{{ uuid }}`,
+			pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+			stable:  true,
+		},
+		{
+			name:    "should generate stable letters from configured source",
+			config:  tmpls.Config{Rand: strings.NewReader(strings.Repeat("\x00\x01", 64))},
+			src:     `{{ randAlpha 4 }}`,
+			pattern: `^abab$`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			first, err := render(t, test.config, test.src, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !regexp.MustCompile(test.pattern).MatchString(first) {
+				t.Fatalf("expected %s to match %s", first, test.pattern)
+			}
+			if !test.stable {
+				return
+			}
+			second, err := render(t, test.config, test.src, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first != second {
+				t.Fatalf("expected %s but got %s", first, second)
+			}
+		})
+	}
+}
+
+func TestRandAlphaErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := render(t, tmpls.Config{}, `{{ randAlpha -1 }}`, nil); err == nil {
+		t.Fatal("expected error for negative length")
+	}
+}
+
+func TestCustomFuncs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		funcs    template.FuncMap
+		src      string
+		locale   string
+		expected string
+	}{
+		{
+			name:     "should call custom funcs",
+			funcs:    template.FuncMap{"shout": strings.ToUpper},
+			src:      `{{ shout "hi" }}`,
+			expected: "HI",
+		},
+		{
+			name: "should replace built-in funcs",
+			funcs: template.FuncMap{
+				"uuid": func() string { return "fixed" },
+			},
+			src:      `{{ uuid }}`,
+			expected: "fixed",
+		},
+		{
+			name: "should bind context funcs",
+			funcs: template.FuncMap{
+				"greeting": tmpls.ContextFunc(func(ctx context.Context) any {
+					return func() string {
+						if tmpls.RenderContextFrom(ctx).Locale == "de" {
+							return "Hallo"
+						}
+						return "Hello"
+					}
+				}),
+			},
+			src:      `{{ greeting }}`,
+			locale:   "de",
+			expected: "Hallo",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, tmpls.Config{Funcs: test.funcs}, test.src)
+			ctx := tmpls.WithRenderContext(
+				context.Background(),
+				&tmpls.RenderContext{Locale: test.locale},
+			)
+			output, err := templates.ExecuteContext(
+				ctx,
+				"page.html.tmpl",
+				"page.html.tmpl",
+				nil,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestInvalidCustomFuncs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		funcs template.FuncMap
+	}{
+		{
+			name:  "should reject non funcs",
+			funcs: template.FuncMap{"answer": 42},
+		},
+		{
+			name:  "should reject invalid names",
+			funcs: template.FuncMap{"not-valid": strings.ToUpper},
+		},
+		{
+			name:  "should reject funcs without results",
+			funcs: template.FuncMap{"nothing": func() {}},
+		},
+		{
+			name:  "should reject second results other than errors",
+			funcs: template.FuncMap{"pair": func() (string, string) { return "", "" }},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tmpls.New(
+				tmpls.Config{TemplatesFS: fstest.MapFS{}, Funcs: test.funcs},
+				slog.Default(),
+			)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
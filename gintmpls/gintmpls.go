@@ -0,0 +1,77 @@
+// Package gintmpls renders tmpls templates from Gin handlers.
+package gintmpls
+
+import (
+	"context"
+	"net/http"
+	"path"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// HTMLRender is a render.HTMLRender executing templates for c.HTML. Names
+// are template files executed as their own glob with their base name, e.g.
+// "pages/home.html.tmpl", unless Glob is set, in which case they name
+// templates in it.
+//
+// Gin doesn't pass the request to HTMLRender, so funcs reading the
+// RenderContext, such as nonce and csrfField, need HTML instead.
+type HTMLRender struct {
+	Templates *tmpls.Templates
+	Glob      string
+}
+
+var _ render.HTMLRender = (*HTMLRender)(nil)
+
+func (r *HTMLRender) Instance(name string, data any) render.Render {
+	return &htmlRender{templates: r, name: name, data: data}
+}
+
+// HTML executes the template name for c's request and writes it with status,
+// like c.HTML but with the request's RenderContext, nonce and
+// Content-Security-Policy header, as with Templates.RenderRequest. Errors are
+// also added to c.
+func (r *HTMLRender) HTML(c *gin.Context, status int, name string, data any) error {
+	ctx, err := r.Templates.RequestContext(c.Writer.Header(), c.Request)
+	if err != nil {
+		return c.Error(err)
+	}
+	output, err := r.execute(ctx, name, data)
+	if err != nil {
+		return c.Error(err)
+	}
+	c.Data(status, "text/html; charset=utf-8", []byte(output))
+	return nil
+}
+
+func (r *HTMLRender) execute(ctx context.Context, name string, data any) (string, error) {
+	glob, template := name, path.Base(name)
+	if r.Glob != "" {
+		glob, template = r.Glob, name
+	}
+	return r.Templates.ExecuteContext(ctx, glob, template, data)
+}
+
+type htmlRender struct {
+	templates *HTMLRender
+	name      string
+	data      any
+}
+
+func (r *htmlRender) Render(w http.ResponseWriter) error {
+	output, err := r.templates.execute(context.Background(), r.name, r.data)
+	if err != nil {
+		return err
+	}
+	r.WriteContentType(w)
+	_, err = w.Write([]byte(output))
+	return err
+}
+
+func (r *htmlRender) WriteContentType(w http.ResponseWriter) {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+}
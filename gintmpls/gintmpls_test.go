@@ -0,0 +1,121 @@
+package gintmpls_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/fivethirty/tmpls/gintmpls"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHTMLRender(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: fstest.MapFS{
+				"common/empty.html.tmpl": &fstest.MapFile{},
+				"pages/home.html.tmpl": &fstest.MapFile{
+					Data: []byte(`{{ define "title" }}Home{{ end }}` +
+						`<p class="{{ active "/home" }}">{{ . }}</p>` +
+						`<script nonce="{{ nonce }}"></script>`),
+				},
+			},
+			CommonGlob: "common/*.html.tmpl",
+			CSP:        "script-src 'nonce-{nonce}'",
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		render       *gintmpls.HTMLRender
+		handler      func(r *gintmpls.HTMLRender) gin.HandlerFunc
+		expected     string
+		expectStatus int
+	}{
+		{
+			name:   "should render template file with c.HTML",
+			render: &gintmpls.HTMLRender{Templates: templates},
+			handler: func(*gintmpls.HTMLRender) gin.HandlerFunc {
+				return func(c *gin.Context) {
+					c.HTML(http.StatusOK, "pages/home.html.tmpl", "hi")
+				}
+			},
+			expected:     `<p class="">hi</p><script nonce=""></script>`,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:   "should render template from glob",
+			render: &gintmpls.HTMLRender{Templates: templates, Glob: "pages/*.html.tmpl"},
+			handler: func(*gintmpls.HTMLRender) gin.HandlerFunc {
+				return func(c *gin.Context) {
+					c.HTML(http.StatusCreated, "title", nil)
+				}
+			},
+			expected:     `Home`,
+			expectStatus: http.StatusCreated,
+		},
+		{
+			name:   "should render with request",
+			render: &gintmpls.HTMLRender{Templates: templates},
+			handler: func(r *gintmpls.HTMLRender) gin.HandlerFunc {
+				return func(c *gin.Context) {
+					_ = r.HTML(c, http.StatusOK, "pages/home.html.tmpl", "hi")
+				}
+			},
+			expected:     `<p class="active">hi</p><script nonce="{nonce}"></script>`,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:   "should add errors to context",
+			render: &gintmpls.HTMLRender{Templates: templates},
+			handler: func(r *gintmpls.HTMLRender) gin.HandlerFunc {
+				return func(c *gin.Context) {
+					if err := r.HTML(c, http.StatusOK, "pages/missing.html.tmpl", nil); err != nil {
+						c.String(http.StatusInternalServerError, "%d", len(c.Errors))
+					}
+				}
+			},
+			expected:     `1`,
+			expectStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			engine := gin.New()
+			engine.HTMLRender = test.render
+			engine.GET("/home", test.handler(test.render))
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/home", nil))
+			if recorder.Code != test.expectStatus {
+				t.Fatalf("expected status %d but got %d", test.expectStatus, recorder.Code)
+			}
+			expected := test.expected
+			if strings.Contains(expected, tmpls.NoncePlaceholder) {
+				csp := recorder.Header().Get("Content-Security-Policy")
+				matches := regexp.MustCompile(`^script-src 'nonce-([A-Za-z0-9_-]{22})'$`).
+					FindStringSubmatch(csp)
+				if matches == nil {
+					t.Fatalf("expected nonce policy but got %s", csp)
+				}
+				expected = strings.ReplaceAll(expected, tmpls.NoncePlaceholder, matches[1])
+			}
+			if recorder.Body.String() != expected {
+				t.Fatalf("expected %s but got %s", expected, recorder.Body.String())
+			}
+		})
+	}
+}
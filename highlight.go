@@ -0,0 +1,124 @@
+package tmpls
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Highlighter syntax-highlights code for the highlight func. Wrap a library
+// such as chroma with HighlighterFunc:
+//
+//	tmpls.HighlighterFunc(func(w io.Writer, code, language string) error {
+//		return quick.Highlight(w, code, language, "html", "github")
+//	})
+type Highlighter interface {
+	// Highlight writes code in language as HTML.
+	Highlight(w io.Writer, code string, language string) error
+}
+
+// HighlighterFunc adapts an ordinary function to a Highlighter.
+type HighlighterFunc func(w io.Writer, code string, language string) error
+
+func (f HighlighterFunc) Highlight(w io.Writer, code string, language string) error {
+	return f(w, code, language)
+}
+
+var languagePattern = regexp.MustCompile(`^[A-Za-z0-9_+#.-]*$`)
+
+// highlight renders code in language with Config.Highlighter, whose output is
+// sanitized so only markup for highlighting can get through:
+//
+//	{{ highlight .Code "go" }}
+//
+// Without a Highlighter, the code is rendered escaped in
+// <pre><code class="language-go"> for highlighting in the browser.
+func (t *Templates) highlight(code string, language string) (template.HTML, error) {
+	if !languagePattern.MatchString(language) {
+		return "", fmt.Errorf("highlight: invalid language %q", language)
+	}
+	if t.config.Highlighter == nil {
+		class := ""
+		if language != "" {
+			class = ` class="language-` + template.HTMLEscapeString(language) + `"`
+		}
+		return template.HTML(
+			"<pre><code" + class + ">" + template.HTMLEscapeString(code) + "</code></pre>",
+		), nil
+	}
+	var out bytes.Buffer
+	if err := t.config.Highlighter.Highlight(&out, code, language); err != nil {
+		return "", fmt.Errorf("highlight: %w", err)
+	}
+	return template.HTML(sanitizeHighlighted(out.String())), nil
+}
+
+var (
+	// highlightTags are the elements highlighters emit, e.g. chroma's
+	// tables of line numbers.
+	highlightTags = map[string]bool{
+		"pre": true, "code": true, "span": true, "div": true,
+		"table": true, "tbody": true, "tr": true, "td": true,
+	}
+	tagPattern = regexp.MustCompile(
+		`^<(/?)([a-zA-Z]+)((?:\s+[a-zA-Z-]+\s*=\s*"[^"<>]*")*)\s*(/?)>`,
+	)
+	attrPattern   = regexp.MustCompile(`([a-zA-Z-]+)\s*=\s*"([^"]*)"`)
+	classPattern  = regexp.MustCompile(`^[A-Za-z0-9_ -]*$`)
+	stylePattern  = regexp.MustCompile(`^[A-Za-z0-9#:;,.%\s-]*$`)
+	entityPattern = regexp.MustCompile(`^&(?:[A-Za-z][A-Za-z0-9]*|#[0-9]+|#[xX][0-9a-fA-F]+);`)
+)
+
+// sanitizeHighlighted keeps the tags of highlightTags with class and style
+// attributes, dropping other attributes and styles with functions such as
+// url(), and escapes everything else.
+func sanitizeHighlighted(src string) string {
+	var out strings.Builder
+	for len(src) > 0 {
+		switch src[0] {
+		case '<':
+			if tag := tagPattern.FindStringSubmatch(src); tag != nil &&
+				highlightTags[strings.ToLower(tag[2])] {
+				out.WriteString("<" + tag[1] + strings.ToLower(tag[2]))
+				if tag[1] == "" {
+					writeHighlightAttrs(&out, tag[3])
+				}
+				out.WriteString(">")
+				src = src[len(tag[0]):]
+				continue
+			}
+			out.WriteString("&lt;")
+		case '>':
+			out.WriteString("&gt;")
+		case '&':
+			if entity := entityPattern.FindString(src); entity != "" {
+				out.WriteString(entity)
+				src = src[len(entity):]
+				continue
+			}
+			out.WriteString("&amp;")
+		case '"':
+			out.WriteString("&#34;")
+		default:
+			out.WriteByte(src[0])
+		}
+		src = src[1:]
+	}
+	return out.String()
+}
+
+func writeHighlightAttrs(out *strings.Builder, attrs string) {
+	for _, attr := range attrPattern.FindAllStringSubmatch(attrs, -1) {
+		name, value := strings.ToLower(attr[1]), attr[2]
+		switch {
+		case name == "class" && classPattern.MatchString(value):
+		case name == "style" && stylePattern.MatchString(value):
+		default:
+			continue
+		}
+		fmt.Fprintf(out, ` %s="%s"`, name, value)
+	}
+}
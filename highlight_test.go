@@ -0,0 +1,94 @@
+package tmpls_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestHighlight(t *testing.T) {
+	t.Parallel()
+
+	highlighter := func(html string, err error) tmpls.Highlighter {
+		return tmpls.HighlighterFunc(func(w io.Writer, code string, language string) error {
+			if err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, html)
+			return err
+		})
+	}
+
+	tests := []struct {
+		name        string
+		config      tmpls.Config
+		language    string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should leave highlighting to the browser by default",
+			language: "go",
+			expected: `<pre><code class="language-go">x := &lt;-ch</code></pre>`,
+		},
+		{
+			name: "should keep highlighting markup",
+			config: tmpls.Config{Highlighter: highlighter(
+				`<pre class="chroma" style="color:#f8f8f2;background-color:#272822">`+
+					`<code><span class="kd">x</span> := &lt;-ch</code></pre>`,
+				nil,
+			)},
+			language: "go",
+			expected: `<pre class="chroma" style="color:#f8f8f2;background-color:#272822">` +
+				`<code><span class="kd">x</span> := &lt;-ch</code></pre>`,
+		},
+		{
+			name: "should escape other markup",
+			config: tmpls.Config{Highlighter: highlighter(
+				`<span onclick="alert(1)" class="x">a</span><script>alert(1)</script>`+
+					`<img src=x onerror=alert(1)>&bogus <span style="background:url(x)">`,
+				nil,
+			)},
+			language: "go",
+			expected: `<span class="x">a</span>&lt;script&gt;alert(1)&lt;/script&gt;` +
+				`&lt;img src=x onerror=alert(1)&gt;&amp;bogus <span>`,
+		},
+		{
+			name:        "should fail on highlighter errors",
+			config:      tmpls.Config{Highlighter: highlighter("", errors.New("no lexer"))},
+			language:    "go",
+			expectError: true,
+		},
+		{
+			name:        "should fail on invalid languages",
+			language:    `go"><script>`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(
+				t,
+				test.config,
+				`{{ highlight "x := <-ch" . }}`,
+				test.language,
+			)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package tmpls
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NoncePlaceholder is replaced by the request's nonce in Config.CSP.
+const NoncePlaceholder = "{nonce}"
+
+// RenderRequest executes a template for r and writes it with status. A fresh
+// nonce is generated for each call and both set in the Content-Security-Policy
+// header built from Config.CSP and exposed to the nonce func, so the two
+// always match. The RenderContext carried by r, if any, is copied with the
+// request and nonce added. Nothing is written if execution fails. With
+// Config.ServerTiming set, the render time is added to the Server-Timing
+// header. With Config.ResponseCache set, 200 responses carry an ETag and may
+// be served from the cache or answered with 304 Not Modified.
+func (t *Templates) RenderRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	glob string,
+	template string,
+	data any,
+) error {
+	ctx, nonce, err := t.requestContext(r)
+	if err != nil {
+		return err
+	}
+	ctx = withExecution(ctx)
+	start := time.Now()
+	key := t.responseKey(r, status, glob, template, data)
+	if cached, ok := t.cachedResponse(key); ok {
+		return t.writeCached(ctx, w, r, cached, nonce, start)
+	}
+
+	buffer := t.buffers.Get().(*bytes.Buffer)
+	defer func() {
+		buffer.Reset()
+		t.buffers.Put(buffer)
+	}()
+	if err := t.execute(ctx, buffer, glob, template, data); err != nil {
+		return err
+	}
+	if t.config.TrackUsage {
+		t.recordUsage(glob, template)
+	}
+
+	if key != "" {
+		output := bytes.Clone(buffer.Bytes())
+		return t.writeCached(ctx, w, r, t.cacheResponse(key, output), nonce, start)
+	}
+	t.writeHeader(ctx, w, status, nonce, start)
+	_, err = buffer.WriteTo(w)
+	return err
+}
+
+// Render executes a template and writes it with status, setting the
+// Content-Type to HTML unless already set. The output is buffered, so nothing
+// is written if execution fails and the caller can still send an error page.
+// Use RenderRequest instead for the Content-Security-Policy nonce and request
+// funcs.
+func (t *Templates) Render(
+	w http.ResponseWriter,
+	status int,
+	glob string,
+	template string,
+	data any,
+) error {
+	buffer := t.buffers.Get().(*bytes.Buffer)
+	defer func() {
+		buffer.Reset()
+		t.buffers.Put(buffer)
+	}()
+	ctx := withExecution(context.Background())
+	if err := t.execute(ctx, buffer, glob, template, data); err != nil {
+		return err
+	}
+	if t.config.TrackUsage {
+		t.recordUsage(glob, template)
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+	w.WriteHeader(status)
+	_, err := buffer.WriteTo(w)
+	return err
+}
+
+// writeCached writes cached with its ETag, or just 304 Not Modified if r
+// already has it.
+func (t *Templates) writeCached(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	cached *cachedResponse,
+	nonce string,
+	start time.Time,
+) error {
+	w.Header().Set("ETag", cached.etag)
+	if etagMatches(r, cached.etag) {
+		t.writeHeader(ctx, w, http.StatusNotModified, nonce, start)
+		return nil
+	}
+	t.writeHeader(ctx, w, http.StatusOK, nonce, start)
+	_, err := w.Write(cached.output)
+	return err
+}
+
+// writeHeader writes status with the headers for an execution that started
+// at start.
+func (t *Templates) writeHeader(
+	ctx context.Context,
+	w http.ResponseWriter,
+	status int,
+	nonce string,
+	start time.Time,
+) {
+	header := w.Header()
+	t.setCSP(header, nonce)
+	if t.config.ServerTiming {
+		setServerTiming(header, executionFrom(ctx), time.Since(start))
+	}
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "text/html; charset=utf-8")
+	}
+	w.WriteHeader(status)
+}
+
+// RequestContext returns the context RenderRequest executes with, for
+// frameworks that write responses themselves: r's context carrying a copy of
+// its RenderContext, if any, with r and a fresh nonce added. The matching
+// Content-Security-Policy header is set on header.
+func (t *Templates) RequestContext(header http.Header, r *http.Request) (context.Context, error) {
+	ctx, nonce, err := t.requestContext(r)
+	if err != nil {
+		return nil, err
+	}
+	t.setCSP(header, nonce)
+	return ctx, nil
+}
+
+func (t *Templates) requestContext(r *http.Request) (context.Context, string, error) {
+	nonce, err := t.newNonce()
+	if err != nil {
+		return nil, "", err
+	}
+	rc := &RenderContext{}
+	if existing := RenderContextFrom(r.Context()); existing != nil {
+		*rc = *existing
+	}
+	rc.Request = r
+	rc.Nonce = nonce
+	return WithRenderContext(r.Context(), rc), nonce, nil
+}
+
+func (t *Templates) setCSP(header http.Header, nonce string) {
+	if t.config.CSP != "" {
+		header.Set(
+			"Content-Security-Policy",
+			strings.ReplaceAll(t.config.CSP, NoncePlaceholder, nonce),
+		)
+	}
+}
+
+func (t *Templates) newNonce() (string, error) {
+	var b [16]byte
+	if _, err := io.ReadFull(t.random, b[:]); err != nil {
+		return "", fmt.Errorf("nonce: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,359 @@
+package tmpls_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestRenderRequest(t *testing.T) {
+	t.Parallel()
+
+	templates := newTemplates(
+		t,
+		tmpls.Config{CSP: "script-src 'nonce-{nonce}'"},
+		`<html lang="{{ locale }}"><script nonce="{{ nonce }}"></script>{{ . }}</html>`,
+	)
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request = request.WithContext(tmpls.WithRenderContext(
+		context.Background(),
+		&tmpls.RenderContext{Locale: "en"},
+	))
+
+	nonces := map[string]bool{}
+	for range 2 {
+		recorder := httptest.NewRecorder()
+		err := templates.RenderRequest(
+			recorder,
+			request,
+			http.StatusCreated,
+			"page.html.tmpl",
+			"page.html.tmpl",
+			"body",
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if recorder.Code != http.StatusCreated {
+			t.Fatalf("expected %d but got %d", http.StatusCreated, recorder.Code)
+		}
+		contentType := recorder.Header().Get("Content-Type")
+		if contentType != "text/html; charset=utf-8" {
+			t.Fatalf("expected text/html; charset=utf-8 but got %s", contentType)
+		}
+		csp := recorder.Header().Get("Content-Security-Policy")
+		matches := regexp.MustCompile(`^script-src 'nonce-([A-Za-z0-9_-]{22})'$`).
+			FindStringSubmatch(csp)
+		if matches == nil {
+			t.Fatalf("expected nonce policy but got %s", csp)
+		}
+		nonce := matches[1]
+		expected := `<html lang="en"><script nonce="` + nonce + `"></script>body</html>`
+		if recorder.Body.String() != expected {
+			t.Fatalf("expected %s but got %s", expected, recorder.Body.String())
+		}
+		nonces[nonce] = true
+	}
+	if len(nonces) != 2 {
+		t.Fatal("expected a fresh nonce per request")
+	}
+}
+
+func TestRenderRequestError(t *testing.T) {
+	t.Parallel()
+
+	templates := newTemplates(t, tmpls.Config{CSP: "default-src 'self'"}, `{{ .Missing }}`)
+	recorder := httptest.NewRecorder()
+	err := templates.RenderRequest(
+		recorder,
+		httptest.NewRequest(http.MethodGet, "/", nil),
+		http.StatusOK,
+		"page.html.tmpl",
+		"page.html.tmpl",
+		"not a struct",
+	)
+	if !tmpls.IsExecError(err) {
+		t.Fatalf("expected exec error but got %v", err)
+	}
+	if len(recorder.Header()) != 0 || recorder.Body.Len() != 0 {
+		t.Fatal("expected nothing written")
+	}
+}
+
+func TestRequestContext(t *testing.T) {
+	t.Parallel()
+
+	templates := newTemplates(
+		t,
+		tmpls.Config{CSP: "script-src 'nonce-{nonce}'"},
+		`<script nonce="{{ nonce }}"></script>{{ renderContext.Request.URL.Path }}`,
+	)
+	header := http.Header{}
+	ctx, err := templates.RequestContext(header, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := templates.ExecuteContext(ctx, "page.html.tmpl", "page.html.tmpl", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := tmpls.RenderContextFrom(ctx).Nonce
+	if csp := header.Get("Content-Security-Policy"); csp != "script-src 'nonce-"+nonce+"'" {
+		t.Fatalf("expected policy for nonce %s but got %s", nonce, csp)
+	}
+	if expected := `<script nonce="` + nonce + `"></script>/a`; output != expected {
+		t.Fatalf("expected %s but got %s", expected, output)
+	}
+}
+
+func TestServerTiming(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		serverTiming bool
+		expected     []*regexp.Regexp
+	}{
+		{
+			name:         "should report parse on cache miss then hit",
+			serverTiming: true,
+			expected: []*regexp.Regexp{
+				regexp.MustCompile(`^tmpls-render;dur=[0-9.]+, tmpls-cache;desc=miss, ` +
+					`tmpls-parse;dur=[0-9.]+$`),
+				regexp.MustCompile(`^tmpls-render;dur=[0-9.]+, tmpls-cache;desc=hit$`),
+			},
+		},
+		{
+			name: "should not report by default",
+			expected: []*regexp.Regexp{
+				regexp.MustCompile(`^$`),
+				regexp.MustCompile(`^$`),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, tmpls.Config{ServerTiming: test.serverTiming}, `x`)
+			for _, expected := range test.expected {
+				recorder := httptest.NewRecorder()
+				if err := templates.RenderRequest(
+					recorder,
+					httptest.NewRequest(http.MethodGet, "/", nil),
+					http.StatusOK,
+					"page.html.tmpl",
+					"page.html.tmpl",
+					nil,
+				); err != nil {
+					t.Fatal(err)
+				}
+				if timing := recorder.Header().Get("Server-Timing"); !expected.MatchString(timing) {
+					t.Fatalf("expected %s but got %s", expected, timing)
+				}
+			}
+		})
+	}
+}
+
+// renderCounter counts how often its Render method is called by a template,
+// while its JSON encoding, and so its data hash, stays the same.
+type renderCounter struct {
+	calls *atomic.Int64
+}
+
+func (c renderCounter) Render() int64 {
+	return c.calls.Add(1)
+}
+
+func TestResponseCache(t *testing.T) {
+	t.Parallel()
+
+	type request struct {
+		ifNoneMatch    bool
+		expectedStatus int
+		expectedBody   string
+	}
+
+	key := func(*http.Request, any) (string, bool) {
+		return "counter", true
+	}
+
+	tests := []struct {
+		name          string
+		config        tmpls.Config
+		requests      []request
+		expectedCalls int64
+	}{
+		{
+			name:   "should serve cached output and answer matching etags with 304",
+			config: tmpls.Config{ResponseCache: &tmpls.ResponseCache{Key: key}},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "1"},
+				{expectedStatus: http.StatusOK, expectedBody: "1"},
+				{ifNoneMatch: true, expectedStatus: http.StatusNotModified},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name: "should execute again once expired",
+			config: tmpls.Config{
+				ResponseCache: &tmpls.ResponseCache{TTL: time.Nanosecond, Key: key},
+			},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "1"},
+				{expectedStatus: http.StatusOK, expectedBody: "2"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name:   "should not cache without a key",
+			config: tmpls.Config{ResponseCache: &tmpls.ResponseCache{}},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "1"},
+				{expectedStatus: http.StatusOK, expectedBody: "2"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name: "should not cache pages with nonces",
+			config: tmpls.Config{
+				CSP:           "script-src 'nonce-{nonce}'",
+				ResponseCache: &tmpls.ResponseCache{Key: key},
+			},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "1"},
+				{expectedStatus: http.StatusOK, expectedBody: "2"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name: "should not cache by default",
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "1"},
+				{ifNoneMatch: true, expectedStatus: http.StatusOK, expectedBody: "2"},
+			},
+			expectedCalls: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, test.config, `{{ .Render }}`)
+			data := renderCounter{calls: &atomic.Int64{}}
+			etag := `"none"`
+			for _, req := range test.requests {
+				recorder := httptest.NewRecorder()
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				if req.ifNoneMatch {
+					r.Header.Set("If-None-Match", `"other", W/`+etag)
+				}
+				if err := templates.RenderRequest(
+					recorder,
+					r,
+					http.StatusOK,
+					"page.html.tmpl",
+					"page.html.tmpl",
+					data,
+				); err != nil {
+					t.Fatal(err)
+				}
+				if recorder.Code != req.expectedStatus {
+					t.Fatalf("expected %d but got %d", req.expectedStatus, recorder.Code)
+				}
+				if body := recorder.Body.String(); body != req.expectedBody {
+					t.Fatalf("expected %s but got %s", req.expectedBody, body)
+				}
+				if header := recorder.Header().Get("ETag"); header != "" {
+					etag = header
+				}
+			}
+			if calls := data.calls.Load(); calls != test.expectedCalls {
+				t.Fatalf("expected %d but got %d", test.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                string
+		src                 string
+		contentType         string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+		expectError         bool
+	}{
+		{
+			name:                "should write status, content type and output",
+			src:                 `<p>{{ . }}</p>`,
+			expectedStatus:      http.StatusCreated,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        "<p>hi</p>",
+		},
+		{
+			name:                "should keep a content type already set",
+			src:                 `{{ . }}`,
+			contentType:         "text/plain",
+			expectedStatus:      http.StatusCreated,
+			expectedContentType: "text/plain",
+			expectedBody:        "hi",
+		},
+		{
+			name:        "should write nothing on error",
+			src:         `{{ .Missing }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, tmpls.Config{}, test.src)
+			recorder := httptest.NewRecorder()
+			if test.contentType != "" {
+				recorder.Header().Set("Content-Type", test.contentType)
+			}
+			err := templates.Render(
+				recorder,
+				http.StatusCreated,
+				"page.html.tmpl",
+				"page.html.tmpl",
+				"hi",
+			)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				if recorder.Code != http.StatusOK || len(recorder.Header()) != 0 ||
+					recorder.Body.Len() != 0 {
+					t.Fatal("expected nothing written")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if recorder.Code != test.expectedStatus {
+				t.Fatalf("expected %d but got %d", test.expectedStatus, recorder.Code)
+			}
+			if contentType := recorder.Header().Get("Content-Type"); contentType !=
+				test.expectedContentType {
+				t.Fatalf("expected %s but got %s", test.expectedContentType, contentType)
+			}
+			if body := recorder.Body.String(); body != test.expectedBody {
+				t.Fatalf("expected %s but got %s", test.expectedBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package tmpls
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var byteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// humanBytes formats a byte count with binary units, e.g. 1536 as "1.5 KiB".
+func humanBytes(value any) (string, error) {
+	n, err := toFloat(value)
+	if err != nil {
+		return "", fmt.Errorf("humanBytes: %w", err)
+	}
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	unit := 0
+	for n >= 1024 && unit < len(byteUnits)-1 {
+		n /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%s%d B", sign, int64(n)), nil
+	}
+	return sign + strconv.FormatFloat(n, 'f', 1, 64) + " " + byteUnits[unit], nil
+}
+
+func toFloat(value any) (float64, error) {
+	v := reflect.ValueOf(value)
+	switch {
+	case v.CanInt():
+		return float64(v.Int()), nil
+	case v.CanUint():
+		return float64(v.Uint()), nil
+	case v.CanFloat():
+		return v.Float(), nil
+	default:
+		return 0, fmt.Errorf("unsupported type %T", value)
+	}
+}
+
+var durationUnits = []struct {
+	duration time.Duration
+	suffix   string
+}{
+	{24 * time.Hour, "d"},
+	{time.Hour, "h"},
+	{time.Minute, "m"},
+	{time.Second, "s"},
+}
+
+// humanDuration formats d with its two largest units, e.g. "2d 3h" or "45s".
+// Durations under a second are shown in milliseconds.
+func humanDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	if d < time.Second {
+		return sign + strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+	}
+	var parts []string
+	for _, unit := range durationUnits {
+		if d < unit.duration && len(parts) == 0 {
+			continue
+		}
+		parts = append(parts, strconv.FormatInt(int64(d/unit.duration), 10)+unit.suffix)
+		d %= unit.duration
+		if len(parts) == 2 {
+			break
+		}
+	}
+	// drop a trailing zero unit, e.g. "2h 0m"
+	if len(parts) == 2 && strings.HasPrefix(parts[1], "0") {
+		parts = parts[:1]
+	}
+	return sign + strings.Join(parts, " ")
+}
+
+var agoUnits = []struct {
+	duration time.Duration
+	name     string
+}{
+	{365 * 24 * time.Hour, "year"},
+	{30 * 24 * time.Hour, "month"},
+	{7 * 24 * time.Hour, "week"},
+	{24 * time.Hour, "day"},
+	{time.Hour, "hour"},
+	{time.Minute, "minute"},
+}
+
+// timeAgo describes value, a time.Time or *time.Time, relative to the clock,
+// e.g. "5 minutes ago" or "in 2 days".
+func (t *Templates) timeAgo(value any) (string, error) {
+	var then time.Time
+	switch v := value.(type) {
+	case time.Time:
+		then = v
+	case *time.Time:
+		if v == nil {
+			return "", nil
+		}
+		then = *v
+	default:
+		return "", fmt.Errorf("timeAgo: unsupported type %T", value)
+	}
+	d := t.clock.Now().Sub(then)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d < time.Minute {
+		return "just now", nil
+	}
+	for _, unit := range agoUnits {
+		if d < unit.duration {
+			continue
+		}
+		n := int64(d / unit.duration)
+		description := fmt.Sprintf("%d %s", n, unit.name)
+		if n != 1 {
+			description += "s"
+		}
+		if future {
+			return "in " + description, nil
+		}
+		return description + " ago", nil
+	}
+	return "just now", nil
+}
@@ -0,0 +1,97 @@
+package tmpls_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestHumanize(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.March, 5, 10, 30, 0, 0, time.UTC)
+	config := tmpls.Config{Clock: tmpls.ClockFunc(func() time.Time { return now })}
+
+	tests := []struct {
+		name        string
+		src         string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "should format bytes",
+			src: `{{ humanBytes 0 }}|{{ humanBytes 1023 }}|{{ humanBytes 1536 }}|` +
+				`{{ humanBytes . }}`,
+			data:     uint64(5 << 30),
+			expected: "0 B|1023 B|1.5 KiB|5.0 GiB",
+		},
+		{
+			name:     "should format negative bytes",
+			src:      `{{ humanBytes -2048 }}`,
+			expected: "-2.0 KiB",
+		},
+		{
+			name:        "should fail for non numeric bytes",
+			src:         `{{ humanBytes "1" }}`,
+			expectError: true,
+		},
+		{
+			name: "should format durations",
+			src:  `{{ range . }}{{ humanDuration . }}|{{ end }}`,
+			data: []time.Duration{
+				0,
+				850 * time.Millisecond,
+				45 * time.Second,
+				time.Hour + 30*time.Second,
+				51*time.Hour + 20*time.Minute,
+				-90 * time.Second,
+			},
+			expected: "0ms|850ms|45s|1h|2d 3h|-1m 30s|",
+		},
+		{
+			name: "should format relative times",
+			src:  `{{ range . }}{{ timeAgo . }}|{{ end }}`,
+			data: []time.Time{
+				now.Add(-30 * time.Second),
+				now.Add(-time.Minute),
+				now.Add(-5 * time.Hour),
+				now.Add(-8 * 24 * time.Hour),
+				now.Add(-400 * 24 * time.Hour),
+				now.Add(2*24*time.Hour + time.Minute),
+			},
+			expected: "just now|1 minute ago|5 hours ago|1 week ago|1 year ago|in 2 days|",
+		},
+		{
+			name:     "should format nil time as empty",
+			src:      `[{{ timeAgo . }}]`,
+			data:     (*time.Time)(nil),
+			expected: "[]",
+		},
+		{
+			name:        "should fail for non time",
+			src:         `{{ timeAgo "yesterday" }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, config, test.src, test.data)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
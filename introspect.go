@@ -0,0 +1,143 @@
+package tmpls
+
+import (
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"text/template/parse"
+)
+
+// TemplateFile describes a template file in TemplatesFS, e.g. for an admin
+// page listing the template inventory.
+type TemplateFile struct {
+	Path string
+	// Shared is set for files matched by CommonGlob or ComponentsGlob.
+	Shared bool
+	// Defines are the templates the file defines, sorted by name, including
+	// its own, named by its base name, unless it only holds defines.
+	Defines []DefinedTemplate
+	// Err is the file's parse error, in which case Defines is empty.
+	Err error
+}
+
+// DefinedTemplate is a template defined by a file.
+type DefinedTemplate struct {
+	Name string
+	// References are the templates it calls with the template action, in
+	// order of appearance without duplicates. Templates rendered by funcs
+	// such as component aren't included.
+	References []string
+}
+
+// List returns every ".tmpl" file in TemplatesFS, sorted by path. Files are
+// parsed without funcs, so those that fail to parse have Err set rather
+// than failing List.
+func (t *Templates) List() ([]TemplateFile, error) {
+	fsys := t.state.Load().fsys
+	var files []TemplateFile
+	err := fs.WalkDir(fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() || !strings.HasSuffix(file, ".tmpl") {
+			return err
+		}
+		files = append(files, t.describe(fsys, file))
+		return nil
+	})
+	return files, err
+}
+
+// DefinedIn returns the files parsed into glob, in override order, shared
+// ones first.
+func (t *Templates) DefinedIn(glob string) ([]TemplateFile, error) {
+	fsys := t.state.Load().fsys
+	files, err := t.files(fsys, glob)
+	if err != nil {
+		return nil, parseError(glob, err)
+	}
+	described := make([]TemplateFile, 0, len(files))
+	for _, file := range files {
+		described = append(described, t.describe(fsys, file))
+	}
+	return described, nil
+}
+
+// describe parses file into a TemplateFile.
+func (t *Templates) describe(fsys fs.FS, file string) TemplateFile {
+	described := TemplateFile{Path: file, Shared: t.shared(file)}
+	trees, err := parseTrees(fsys, file)
+	if err != nil {
+		described.Err = err
+		return described
+	}
+	for name, tree := range trees {
+		if name == path.Base(file) && parse.IsEmptyTree(tree.Root) {
+			continue
+		}
+		described.Defines = append(described.Defines, DefinedTemplate{
+			Name:       name,
+			References: templateReferences(tree.Root, nil),
+		})
+	}
+	slices.SortFunc(described.Defines, func(a, b DefinedTemplate) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return described
+}
+
+// shared reports whether file is matched by CommonGlob or ComponentsGlob.
+func (t *Templates) shared(file string) bool {
+	for _, pattern := range []string{t.config.CommonGlob, t.config.ComponentsGlob} {
+		if matched, _ := path.Match(pattern, file); matched && pattern != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrees parses file into the trees of the templates it defines, without
+// checking funcs.
+func parseTrees(fsys fs.FS, file string) (map[string]*parse.Tree, error) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	src, err := preprocess(file, string(data))
+	if err != nil {
+		return nil, err
+	}
+	tree := parse.New(path.Base(file))
+	tree.Mode = parse.SkipFuncCheck
+	trees := map[string]*parse.Tree{}
+	if _, err := tree.Parse(src, "", "", trees); err != nil {
+		return nil, err
+	}
+	return trees, nil
+}
+
+// templateReferences appends the names of the templates node calls to names,
+// skipping ones already in it.
+func templateReferences(node parse.Node, names []string) []string {
+	switch n := node.(type) {
+	case *parse.TemplateNode:
+		if !slices.Contains(names, n.Name) {
+			names = append(names, n.Name)
+		}
+	case *parse.ListNode:
+		if n == nil {
+			return names
+		}
+		for _, child := range n.Nodes {
+			names = templateReferences(child, names)
+		}
+	case *parse.IfNode:
+		names = templateReferences(n.List, names)
+		names = templateReferences(n.ElseList, names)
+	case *parse.RangeNode:
+		names = templateReferences(n.List, names)
+		names = templateReferences(n.ElseList, names)
+	case *parse.WithNode:
+		names = templateReferences(n.List, names)
+		names = templateReferences(n.ElseList, names)
+	}
+	return names
+}
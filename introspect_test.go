@@ -0,0 +1,88 @@
+package tmpls_test
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestIntrospection(t *testing.T) {
+	t.Parallel()
+
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: fstest.MapFS{
+				"common/layout.html.tmpl": &fstest.MapFile{
+					Data: []byte(`{{ define "layout" }}{{ template "nav" }}` +
+						`{{ if . }}{{ template "content" . }}{{ end }}` +
+						`{{ template "nav" }}{{ end }}{{ define "nav" }}<nav>{{ end }}`),
+				},
+				"common/mail.txt.tmpl": &fstest.MapFile{
+					Data: []byte(`{{ define "signature" }}--{{ end }}`),
+				},
+				"page.html.tmpl": &fstest.MapFile{
+					Data: []byte(`{{ template "layout" . }}{{ define "content" }}hi{{ end }}`),
+				},
+				"broken.html.tmpl": &fstest.MapFile{Data: []byte(`{{ if }}`)},
+				"script.js":        &fstest.MapFile{Data: []byte(`{{ if }}`)},
+			},
+			CommonGlob: "common/*",
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layout := tmpls.TemplateFile{
+		Path:   "common/layout.html.tmpl",
+		Shared: true,
+		Defines: []tmpls.DefinedTemplate{
+			{Name: "layout", References: []string{"nav", "content"}},
+			{Name: "nav"},
+		},
+	}
+	page := tmpls.TemplateFile{
+		Path: "page.html.tmpl",
+		Defines: []tmpls.DefinedTemplate{
+			{Name: "content"},
+			{Name: "page.html.tmpl", References: []string{"layout"}},
+		},
+	}
+
+	files, err := templates.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 4 || files[0].Path != "broken.html.tmpl" || files[0].Err == nil {
+		t.Fatalf("expected broken.html.tmpl to fail first of 4 files but got %+v", files)
+	}
+	expected := []tmpls.TemplateFile{
+		layout,
+		{
+			Path:    "common/mail.txt.tmpl",
+			Shared:  true,
+			Defines: []tmpls.DefinedTemplate{{Name: "signature"}},
+		},
+		page,
+	}
+	if !reflect.DeepEqual(files[1:], expected) {
+		t.Fatalf("expected %+v but got %+v", expected, files[1:])
+	}
+
+	set, err := templates.DefinedIn("page.html.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []tmpls.TemplateFile{layout, page}
+	if !reflect.DeepEqual(set, expected) {
+		t.Fatalf("expected %+v but got %+v", expected, set)
+	}
+
+	if _, err := templates.DefinedIn("missing.html.tmpl"); !tmpls.IsParseError(err) {
+		t.Fatalf("expected parse error but got %v", err)
+	}
+}
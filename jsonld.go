@@ -0,0 +1,185 @@
+package tmpls
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"time"
+)
+
+// StructuredData is a schema.org object rendered by the jsonLD func.
+// Article, Product and Breadcrumbs implement it.
+type StructuredData interface {
+	// StructuredData returns the object's properties, including its
+	// "@type", failing if required ones are missing.
+	StructuredData() (map[string]any, error)
+}
+
+// jsonLD renders data as a <script type="application/ld+json"> element for
+// search engines:
+//
+//	{{ jsonLD .Article }}
+//
+// Values other than StructuredData are marshalled as they are and must have
+// an "@type". json.Marshal escapes <, > and &, so the script can't be closed
+// early.
+func jsonLD(data any) (template.HTML, error) {
+	var object map[string]any
+	if structured, ok := data.(StructuredData); ok {
+		var err error
+		if object, err = structured.StructuredData(); err != nil {
+			return "", fmt.Errorf("jsonLD: %w", err)
+		}
+	} else {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return "", fmt.Errorf("jsonLD: %w", err)
+		}
+		if err := json.Unmarshal(encoded, &object); err != nil || object["@type"] == nil {
+			return "", fmt.Errorf("jsonLD: %T is not an object with an @type", data)
+		}
+	}
+	object["@context"] = "https://schema.org"
+	encoded, err := json.Marshal(object)
+	if err != nil {
+		return "", fmt.Errorf("jsonLD: %w", err)
+	}
+	return template.HTML(`<script type="application/ld+json">` + string(encoded) + `</script>`), nil
+}
+
+// Person is an author of an Article.
+type Person struct {
+	Name string
+	URL  string
+}
+
+// Article is a schema.org Article for the jsonLD func.
+type Article struct {
+	// Type is a more specific type such as "NewsArticle" or "BlogPosting".
+	// Defaults to "Article".
+	Type string
+	// Headline is required.
+	Headline      string
+	Description   string
+	URL           string
+	Image         []string
+	Author        []Person
+	DatePublished time.Time
+	DateModified  time.Time
+}
+
+func (a Article) StructuredData() (map[string]any, error) {
+	if a.Headline == "" {
+		return nil, errors.New("Article: Headline is required")
+	}
+	object := map[string]any{"@type": a.Type, "headline": a.Headline}
+	if a.Type == "" {
+		object["@type"] = "Article"
+	}
+	setNonZero(object, "description", a.Description)
+	setNonZero(object, "url", a.URL)
+	if len(a.Image) > 0 {
+		object["image"] = a.Image
+	}
+	if len(a.Author) > 0 {
+		authors := make([]map[string]any, len(a.Author))
+		for i, author := range a.Author {
+			if author.Name == "" {
+				return nil, fmt.Errorf("Article: Author %d: Name is required", i)
+			}
+			authors[i] = map[string]any{"@type": "Person", "name": author.Name}
+			setNonZero(authors[i], "url", author.URL)
+		}
+		object["author"] = authors
+	}
+	if !a.DatePublished.IsZero() {
+		object["datePublished"] = a.DatePublished.Format(time.RFC3339)
+	}
+	if !a.DateModified.IsZero() {
+		object["dateModified"] = a.DateModified.Format(time.RFC3339)
+	}
+	return object, nil
+}
+
+// Offer is the price of a Product.
+type Offer struct {
+	// Price is a decimal such as "19.99", and is required.
+	Price string
+	// Currency is an ISO 4217 code such as "EUR", and is required.
+	Currency string
+	// Availability is a schema.org ItemAvailability URL such as
+	// "https://schema.org/InStock".
+	Availability string
+	URL          string
+}
+
+// Product is a schema.org Product for the jsonLD func.
+type Product struct {
+	// Name is required.
+	Name        string
+	Description string
+	Image       []string
+	SKU         string
+	Brand       string
+	// Offers are required, as search engines only show products with a
+	// price.
+	Offers []Offer
+}
+
+func (p Product) StructuredData() (map[string]any, error) {
+	if p.Name == "" {
+		return nil, errors.New("Product: Name is required")
+	}
+	if len(p.Offers) == 0 {
+		return nil, errors.New("Product: Offers are required")
+	}
+	object := map[string]any{"@type": "Product", "name": p.Name}
+	setNonZero(object, "description", p.Description)
+	setNonZero(object, "sku", p.SKU)
+	if len(p.Image) > 0 {
+		object["image"] = p.Image
+	}
+	if p.Brand != "" {
+		object["brand"] = map[string]any{"@type": "Brand", "name": p.Brand}
+	}
+	offers := make([]map[string]any, len(p.Offers))
+	for i, offer := range p.Offers {
+		if offer.Price == "" || offer.Currency == "" {
+			return nil, fmt.Errorf("Product: Offer %d: Price and Currency are required", i)
+		}
+		offers[i] = map[string]any{
+			"@type":         "Offer",
+			"price":         offer.Price,
+			"priceCurrency": offer.Currency,
+		}
+		setNonZero(offers[i], "availability", offer.Availability)
+		setNonZero(offers[i], "url", offer.URL)
+	}
+	object["offers"] = offers
+	return object, nil
+}
+
+// StructuredData returns the trail as a BreadcrumbList. Every crumb but the
+// last needs a URL, which should be absolute.
+func (b Breadcrumbs) StructuredData() (map[string]any, error) {
+	items := make([]map[string]any, len(b))
+	for i, crumb := range b {
+		if crumb.Name == "" {
+			return nil, fmt.Errorf("Breadcrumbs: crumb %d: Name is required", i)
+		}
+		if crumb.URL == "" && i < len(b)-1 {
+			return nil, fmt.Errorf("Breadcrumbs: crumb %d: URL is required", i)
+		}
+		items[i] = map[string]any{"@type": "ListItem", "position": i + 1, "name": crumb.Name}
+		setNonZero(items[i], "item", crumb.URL)
+	}
+	return map[string]any{"@type": "BreadcrumbList", "itemListElement": items}, nil
+}
+
+// setNonZero sets key to value unless it is empty.
+func setNonZero(object map[string]any, key string, value string) {
+	if value != "" {
+		object[key] = value
+	}
+}
@@ -0,0 +1,110 @@
+package tmpls_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestJSONLD(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "should render articles",
+			data: tmpls.Article{
+				Type:          "BlogPosting",
+				Headline:      "</script><script>alert(1)</script>",
+				Author:        []tmpls.Person{{Name: "Ada", URL: "https://example.com/ada"}},
+				DatePublished: time.Date(2026, time.May, 1, 12, 0, 0, 0, time.UTC),
+			},
+			expected: `<script type="application/ld+json">{"@context":"https://schema.org",` +
+				`"@type":"BlogPosting","author":[{"@type":"Person","name":"Ada",` +
+				`"url":"https://example.com/ada"}],"datePublished":"2026-05-01T12:00:00Z",` +
+				`"headline":"\u003c/script\u003e\u003cscript\u003ealert(1)` +
+				`\u003c/script\u003e"}` +
+				`</script>`,
+		},
+		{
+			name: "should render products",
+			data: tmpls.Product{
+				Name:   "Mug",
+				Brand:  "Acme",
+				Offers: []tmpls.Offer{{Price: "8.50", Currency: "EUR"}},
+			},
+			expected: `<script type="application/ld+json">{"@context":"https://schema.org",` +
+				`"@type":"Product","brand":{"@type":"Brand","name":"Acme"},"name":"Mug",` +
+				`"offers":[{"@type":"Offer","price":"8.50","priceCurrency":"EUR"}]}</script>`,
+		},
+		{
+			name: "should render breadcrumbs",
+			data: tmpls.Breadcrumbs{
+				{Name: "Home", URL: "https://example.com/"},
+				{Name: "Mugs"},
+			},
+			expected: `<script type="application/ld+json">{"@context":"https://schema.org",` +
+				`"@type":"BreadcrumbList","itemListElement":[{"@type":"ListItem",` +
+				`"item":"https://example.com/","name":"Home","position":1},` +
+				`{"@type":"ListItem","name":"Mugs","position":2}]}</script>`,
+		},
+		{
+			name: "should render other objects with a type",
+			data: map[string]any{"@type": "Organization", "name": "Acme"},
+			expected: `<script type="application/ld+json">{"@context":"https://schema.org",` +
+				`"@type":"Organization","name":"Acme"}</script>`,
+		},
+		{
+			name:        "should fail on articles without headlines",
+			data:        tmpls.Article{Description: "no headline"},
+			expectError: true,
+		},
+		{
+			name:        "should fail on products without offers",
+			data:        tmpls.Product{Name: "Mug"},
+			expectError: true,
+		},
+		{
+			name: "should fail on offers without currencies",
+			data: tmpls.Product{
+				Name:   "Mug",
+				Offers: []tmpls.Offer{{Price: "8.50"}},
+			},
+			expectError: true,
+		},
+		{
+			name:        "should fail on breadcrumbs without urls",
+			data:        tmpls.Breadcrumbs{{Name: "Home"}, {Name: "Mugs"}},
+			expectError: true,
+		},
+		{
+			name:        "should fail on objects without a type",
+			data:        map[string]any{"name": "Acme"},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{}, `{{ jsonLD . }}`, test.data)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package tmpls
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// jsonScript marshals value into a <script type="application/json"> element
+// for frontend code to read, with an id if given:
+//
+//	{{ json .InitialState "initial-state" }}
+//
+// json.Marshal escapes <, >, &, U+2028 and U+2029, so the data can neither
+// close the script early nor break JavaScript parsing it.
+func jsonScript(value any, id ...string) (template.HTML, error) {
+	if len(id) > 1 {
+		return "", fmt.Errorf("json takes at most one id but got %d", len(id))
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("json: %w", err)
+	}
+	tag := `<script type="application/json">`
+	if len(id) == 1 {
+		tag = fmt.Sprintf(
+			`<script type="application/json" id="%s">`,
+			template.HTMLEscapeString(id[0]),
+		)
+	}
+	return template.HTML(tag + string(data) + `</script>`), nil
+}
+
+var dataAttrNamePattern = regexp.MustCompile(`^[a-z][-a-z0-9]*$`)
+
+// dataAttr marshals value into a data-* attribute for web components to read
+// when they hydrate, for use inside a tag:
+//
+//	<date-picker {{ dataAttr "config" .PickerConfig }}></date-picker>
+//
+// renders data-config="…", whose dataset.config is the JSON. The attribute
+// is quoted and its value escaped, so quotes and ampersands in the data
+// survive, and the name may include the data- prefix.
+func dataAttr(name string, value any) (template.HTMLAttr, error) {
+	name = strings.TrimPrefix(name, "data-")
+	if !dataAttrNamePattern.MatchString(name) {
+		return "", fmt.Errorf("dataAttr: invalid name %q", name)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("dataAttr: %w", err)
+	}
+	return template.HTMLAttr(
+		`data-` + name + `="` + template.HTMLEscapeString(string(data)) + `"`,
+	), nil
+}
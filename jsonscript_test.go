@@ -0,0 +1,125 @@
+package tmpls_test
+
+import (
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestJSONScript(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should embed json",
+			src:      `{{ json . }}`,
+			data:     map[string]any{"count": 1, "tags": []string{"a"}},
+			expected: `<script type="application/json">{"count":1,"tags":["a"]}</script>`,
+		},
+		{
+			name: "should escape script breaking characters",
+			src:  `{{ json . "state" }}`,
+			data: map[string]string{"html": "</script>", "js": "a\u2028b&"},
+			expected: `<script type="application/json" id="state">` +
+				`{"html":"\u003c/script\u003e","js":"a\u2028b\u0026"}</script>`,
+		},
+		{
+			name:     "should escape id",
+			src:      `{{ json nil "a\"b" }}`,
+			expected: `<script type="application/json" id="a&#34;b">null</script>`,
+		},
+		{
+			name:        "should fail for unmarshalable data",
+			src:         `{{ json . }}`,
+			data:        map[string]any{"fn": func() {}},
+			expectError: true,
+		},
+		{
+			name:        "should fail for several ids",
+			src:         `{{ json . "a" "b" }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{}, test.src, test.data)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestDataAttr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "should embed json in a data attribute",
+			src:  `<x-map {{ dataAttr "config" . }}></x-map>`,
+			data: map[string]any{"zoom": 3, "tags": []string{"a"}},
+			expected: `<x-map data-config="{&#34;tags&#34;:[&#34;a&#34;],&#34;zoom&#34;:3}">` +
+				`</x-map>`,
+		},
+		{
+			name: "should escape quotes and ampersands",
+			src:  `<x-card {{ dataAttr "data-props" . }}></x-card>`,
+			data: map[string]string{"title": `Tom's "Cat" & Jerry</x-card>`},
+			expected: `<x-card data-props="{&#34;title&#34;:&#34;Tom&#39;s \&#34;Cat\&#34; ` +
+				`\u0026 Jerry\u003c/x-card\u003e&#34;}"></x-card>`,
+		},
+		{
+			name:        "should fail for invalid names",
+			src:         `<x-map {{ dataAttr "on click" . }}></x-map>`,
+			expectError: true,
+		},
+		{
+			name:        "should fail for unmarshalable data",
+			src:         `<x-map {{ dataAttr "config" . }}></x-map>`,
+			data:        map[string]any{"fn": func() {}},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{}, test.src, test.data)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
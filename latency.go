@@ -0,0 +1,101 @@
+package tmpls
+
+import (
+	"cmp"
+	"math/bits"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Latency reports how long successful executions of a template from a glob
+// took, for ranking templates worth optimizing or compiling ahead of time. It
+// is only recorded when Config.TrackLatency is set. Percentiles are the upper
+// bounds of power-of-two buckets, so they are within a factor of two.
+type Latency struct {
+	Glob     string
+	Template string
+	Count    int64
+	Mean     time.Duration
+	P50      time.Duration
+	P90      time.Duration
+	P99      time.Duration
+	Max      time.Duration
+}
+
+// latencyBuckets holds durations up to 2^40ns, about 18 minutes.
+const latencyBuckets = 41
+
+// histogram counts durations in buckets whose upper bounds are powers of two
+// nanoseconds.
+type histogram struct {
+	mu      sync.Mutex
+	count   int64
+	total   time.Duration
+	max     time.Duration
+	buckets [latencyBuckets]int64
+}
+
+func (h *histogram) record(d time.Duration) {
+	i := min(bits.Len64(uint64(max(d, 0))), latencyBuckets-1)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.count++
+	h.total += d
+	h.max = max(h.max, d)
+	h.buckets[i]++
+}
+
+// percentile returns the upper bound of the bucket holding the q quantile,
+// capped at the largest duration seen.
+func (h *histogram) percentile(q float64) time.Duration {
+	rank := int64(q * float64(h.count))
+	var seen int64
+	for i, n := range h.buckets {
+		seen += n
+		if seen > rank {
+			return min(time.Duration(1)<<i, h.max)
+		}
+	}
+	return h.max
+}
+
+// Latency returns a snapshot of recorded execution latencies, slowest mean
+// first. It is empty unless Config.TrackLatency is set.
+func (t *Templates) Latency() []Latency {
+	var latency []Latency
+	t.latency.Range(func(key, value any) bool {
+		k := key.(usageKey)
+		h := value.(*histogram)
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.count == 0 {
+			// stored by recordLatency but not yet recorded
+			return true
+		}
+		latency = append(latency, Latency{
+			Glob:     k.glob,
+			Template: k.template,
+			Count:    h.count,
+			Mean:     h.total / time.Duration(h.count),
+			P50:      h.percentile(0.5),
+			P90:      h.percentile(0.9),
+			P99:      h.percentile(0.99),
+			Max:      h.max,
+		})
+		return true
+	})
+	slices.SortFunc(latency, func(a, b Latency) int {
+		return cmp.Compare(b.Mean, a.Mean)
+	})
+	return latency
+}
+
+func (t *Templates) recordLatency(glob string, template string, d time.Duration) {
+	key := usageKey{glob: glob, template: template}
+	value, ok := t.latency.Load(key)
+	if !ok {
+		value, _ = t.latency.LoadOrStore(key, &histogram{})
+	}
+	value.(*histogram).record(d)
+}
@@ -0,0 +1,95 @@
+package tmpls_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+// slowFaults is a tmpls.FaultInjector delaying executions of one glob.
+type slowFaults struct {
+	glob  string
+	delay time.Duration
+}
+
+func (f slowFaults) BeforeParse(string) error {
+	return nil
+}
+
+func (f slowFaults) BeforeExecute(_ context.Context, glob string, _ string) error {
+	if glob == f.glob {
+		time.Sleep(f.delay)
+	}
+	return nil
+}
+
+func TestLatency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		trackLatency bool
+		expected     []string
+	}{
+		{
+			name:         "should record latency slowest first when enabled",
+			trackLatency: true,
+			expected:     []string{"slow.html.tmpl", "fast.html.tmpl"},
+		},
+		{
+			name: "should not record latency when disabled",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: fstest.MapFS{
+						"common/empty.html.tmpl": &fstest.MapFile{},
+						"fast.html.tmpl":         &fstest.MapFile{Data: []byte("fast")},
+						"slow.html.tmpl":         &fstest.MapFile{Data: []byte("slow")},
+					},
+					CommonGlob:   "common/*.html.tmpl",
+					Faults:       slowFaults{glob: "slow.html.tmpl", delay: 10 * time.Millisecond},
+					TrackLatency: test.trackLatency,
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for range 3 {
+				for _, glob := range []string{"fast.html.tmpl", "slow.html.tmpl"} {
+					if _, err := templates.Execute(glob, glob, nil); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			latency := templates.Latency()
+			if len(latency) != len(test.expected) {
+				t.Fatalf("expected %d templates but got %v", len(test.expected), latency)
+			}
+			for i, l := range latency {
+				if l.Glob != test.expected[i] || l.Template != test.expected[i] {
+					t.Fatalf("expected %s but got %v", test.expected[i], l)
+				}
+				if l.Count != 3 {
+					t.Fatalf("expected 3 executions but got %d", l.Count)
+				}
+				if l.P50 > l.P90 || l.P90 > l.P99 || l.P99 > l.Max || l.Mean > l.Max {
+					t.Fatalf("expected ordered percentiles but got %v", l)
+				}
+			}
+			if test.trackLatency && latency[0].P50 < 10*time.Millisecond {
+				t.Fatalf("expected P50 of at least 10ms but got %v", latency[0].P50)
+			}
+		})
+	}
+}
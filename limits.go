@@ -0,0 +1,265 @@
+package tmpls
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DataLimits bounds the data passed to Execute so a pathological payload
+// can't produce an enormous render. Zero fields are unlimited.
+type DataLimits struct {
+	// MaxLen limits the length of each slice, array and map.
+	MaxLen int
+	// MaxDepth limits how deeply structs, slices, arrays and maps nest.
+	MaxDepth int
+	// MaxStringBytes limits the total size of all strings.
+	MaxStringBytes int
+}
+
+// DataLimitError reports which DataLimits field the data exceeded and where.
+type DataLimitError struct {
+	Limit string
+	Path  string
+	Value int
+	Max   int
+}
+
+func (e *DataLimitError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "."
+	}
+	return fmt.Sprintf("data exceeds %s at %s: %d > %d", e.Limit, path, e.Value, e.Max)
+}
+
+type dataChecker struct {
+	limits      DataLimits
+	stringBytes int
+	visited     map[visit]bool
+}
+
+type visit struct {
+	typ reflect.Type
+	ptr uintptr
+	// len tells apart slices sharing a backing array, since a longer one
+	// holds elements a shorter one didn't
+	len int
+}
+
+func (l DataLimits) check(data any) error {
+	c := dataChecker{limits: l, visited: map[visit]bool{}}
+	return c.check(reflect.ValueOf(data), 0)
+}
+
+func (c *dataChecker) check(v reflect.Value, depth int) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Pointer && c.seen(v) {
+			return nil
+		}
+		return c.check(v.Elem(), depth)
+	case reflect.String:
+		c.stringBytes += v.Len()
+		if c.limits.MaxStringBytes > 0 && c.stringBytes > c.limits.MaxStringBytes {
+			return &DataLimitError{
+				Limit: "MaxStringBytes",
+				Value: c.stringBytes,
+				Max:   c.limits.MaxStringBytes,
+			}
+		}
+		return nil
+	case reflect.Struct:
+		if err := c.checkDepth(depth + 1); err != nil {
+			return err
+		}
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			// templates can only reach exported fields
+			if !field.IsExported() {
+				continue
+			}
+			if err := c.check(v.Field(i), depth+1); err != nil {
+				return prependPath(err, "."+field.Name)
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if v.Kind() != reflect.Array && (v.IsNil() || c.seen(v)) {
+			return nil
+		}
+		if err := c.checkDepth(depth + 1); err != nil {
+			return err
+		}
+		if c.limits.MaxLen > 0 && v.Len() > c.limits.MaxLen {
+			return &DataLimitError{Limit: "MaxLen", Value: v.Len(), Max: c.limits.MaxLen}
+		}
+		if v.Kind() == reflect.Map {
+			iter := v.MapRange()
+			for iter.Next() {
+				if err := c.check(iter.Value(), depth+1); err != nil {
+					return prependPath(err, "["+fmt.Sprint(iter.Key())+"]")
+				}
+			}
+			return nil
+		}
+		for i := range v.Len() {
+			if err := c.check(v.Index(i), depth+1); err != nil {
+				return prependPath(err, "["+strconv.Itoa(i)+"]")
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (c *dataChecker) checkDepth(depth int) error {
+	if c.limits.MaxDepth > 0 && depth > c.limits.MaxDepth {
+		return &DataLimitError{Limit: "MaxDepth", Value: depth, Max: c.limits.MaxDepth}
+	}
+	return nil
+}
+
+// seen reports whether v was already checked, guarding against cycles.
+func (c *dataChecker) seen(v reflect.Value) bool {
+	key := visit{typ: v.Type(), ptr: v.Pointer()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+	if c.visited[key] {
+		return true
+	}
+	c.visited[key] = true
+	return false
+}
+
+func prependPath(err error, segment string) error {
+	if limitErr, ok := err.(*DataLimitError); ok {
+		limitErr.Path = segment + limitErr.Path
+	}
+	return err
+}
+
+// ParseLimits bounds the templates parsed into each glob, for templates from
+// user-editable sources such as tenant uploads, so a pathological template
+// can't exhaust memory or hold up parsing. Zero fields are unlimited.
+type ParseLimits struct {
+	// MaxFiles limits the files parsed into a glob, including shared ones.
+	MaxFiles int
+	// MaxFileSize limits the size of each file in bytes.
+	MaxFileSize int64
+	// MaxParseTime limits how long parsing a glob takes. Parsing can't be
+	// interrupted, so a glob over the limit fails once it is exceeded while
+	// its parse finishes in the background, and keeps failing without
+	// parsing again until it has.
+	MaxParseTime time.Duration
+}
+
+// ParseLimitError reports which ParseLimits field a glob exceeded, and in
+// which file for MaxFileSize.
+type ParseLimitError struct {
+	Limit string
+	File  string
+	Value int64
+	Max   int64
+}
+
+func (e *ParseLimitError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s exceeds %s: %d > %d", e.File, e.Limit, e.Value, e.Max)
+	}
+	return fmt.Sprintf("templates exceed %s: %d > %d", e.Limit, e.Value, e.Max)
+}
+
+// checkFiles checks the number and sizes of files against l.
+func (l ParseLimits) checkFiles(fsys fs.FS, files []string) error {
+	if l.MaxFiles > 0 && len(files) > l.MaxFiles {
+		return &ParseLimitError{
+			Limit: "MaxFiles",
+			Value: int64(len(files)),
+			Max:   int64(l.MaxFiles),
+		}
+	}
+	if l.MaxFileSize <= 0 {
+		return nil
+	}
+	for _, file := range files {
+		info, err := fs.Stat(fsys, file)
+		if err != nil {
+			return err
+		}
+		if info.Size() > l.MaxFileSize {
+			return &ParseLimitError{
+				Limit: "MaxFileSize",
+				File:  file,
+				Value: info.Size(),
+				Max:   l.MaxFileSize,
+			}
+		}
+	}
+	return nil
+}
+
+// parse calls parse, failing once it takes longer than MaxParseTime. Parses
+// abandoned for taking too long are kept in slow by glob until they finish,
+// so a slow glob fails straight away instead of piling up parses.
+func (l ParseLimits) parse(
+	slow *sync.Map,
+	glob string,
+	parse func() (Executor, error),
+) (Executor, error) {
+	if l.MaxParseTime <= 0 {
+		return parse()
+	}
+	if value, ok := slow.Load(glob); ok {
+		return nil, &ParseLimitError{
+			Limit: "MaxParseTime",
+			Value: int64(time.Since(value.(time.Time))),
+			Max:   int64(l.MaxParseTime),
+		}
+	}
+	type result struct {
+		executor Executor
+		err      error
+	}
+	// buffered so the parse can finish after a timeout
+	done := make(chan result, 1)
+	start := time.Now()
+	var mu sync.Mutex
+	finished, abandoned := false, false
+	go func() {
+		executor, err := parse()
+		mu.Lock()
+		finished = true
+		if abandoned {
+			slow.CompareAndDelete(glob, start)
+		}
+		mu.Unlock()
+		done <- result{executor: executor, err: err}
+	}()
+	timer := time.NewTimer(l.MaxParseTime)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.executor, r.err
+	case <-timer.C:
+		mu.Lock()
+		if !finished {
+			abandoned = true
+			slow.Store(glob, start)
+		}
+		mu.Unlock()
+		return nil, &ParseLimitError{
+			Limit: "MaxParseTime",
+			Value: int64(time.Since(start)),
+			Max:   int64(l.MaxParseTime),
+		}
+	}
+}
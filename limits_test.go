@@ -0,0 +1,255 @@
+package tmpls_test
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+type limitsNode struct {
+	Name     string
+	Children []*limitsNode
+	Next     *limitsNode
+}
+
+func TestDataLimits(t *testing.T) {
+	t.Parallel()
+
+	cyclic := &limitsNode{Name: "cycle"}
+	cyclic.Next = cyclic
+	shared := []*limitsNode{{}, {}, {}}
+
+	tests := []struct {
+		name        string
+		limits      tmpls.DataLimits
+		data        any
+		expectLimit string
+		expectPath  string
+	}{
+		{
+			name:   "should allow data within limits",
+			limits: tmpls.DataLimits{MaxLen: 2, MaxDepth: 3, MaxStringBytes: 10},
+			data:   &limitsNode{Name: "a", Children: []*limitsNode{{Name: "b"}}},
+		},
+		{
+			name:        "should reject long slices",
+			limits:      tmpls.DataLimits{MaxLen: 1},
+			data:        &limitsNode{Children: []*limitsNode{{}, {}}},
+			expectLimit: "MaxLen",
+			expectPath:  ".Children",
+		},
+		{
+			name:        "should reject large maps",
+			limits:      tmpls.DataLimits{MaxLen: 1},
+			data:        map[string]int{"a": 1, "b": 2},
+			expectLimit: "MaxLen",
+		},
+		{
+			name:        "should reject deep nesting",
+			limits:      tmpls.DataLimits{MaxDepth: 2},
+			data:        &limitsNode{Children: []*limitsNode{{Children: []*limitsNode{{}}}}},
+			expectLimit: "MaxDepth",
+			expectPath:  ".Children[0]",
+		},
+		{
+			name:        "should check slices sharing a backing array",
+			limits:      tmpls.DataLimits{MaxLen: 2},
+			data:        map[string][]*limitsNode{"a": shared[:1], "b": shared},
+			expectLimit: "MaxLen",
+			expectPath:  "[b]",
+		},
+		{
+			name:   "should not loop on cycles",
+			limits: tmpls.DataLimits{MaxStringBytes: 10},
+			data:   cyclic,
+		},
+		{
+			name:   "should reject large total strings",
+			limits: tmpls.DataLimits{MaxStringBytes: 10},
+			data: &limitsNode{
+				Name:     strings.Repeat("a", 6),
+				Children: []*limitsNode{{Name: strings.Repeat("b", 6)}},
+			},
+			expectLimit: "MaxStringBytes",
+			expectPath:  ".Children[0].Name",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := render(t, tmpls.Config{DataLimits: &test.limits}, `ok`, test.data)
+			if test.expectLimit == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var limitErr *tmpls.DataLimitError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("expected DataLimitError but got %v", err)
+			}
+			if limitErr.Limit != test.expectLimit || limitErr.Path != test.expectPath {
+				t.Fatalf(
+					"expected %s at %q but got %s at %q",
+					test.expectLimit,
+					test.expectPath,
+					limitErr.Limit,
+					limitErr.Path,
+				)
+			}
+			if !tmpls.IsExecError(err) {
+				t.Fatalf("expected exec error but got %v", err)
+			}
+		})
+	}
+}
+
+type slowEngine struct {
+	delay time.Duration
+	calls *atomic.Int64
+}
+
+func (e slowEngine) Parse(
+	fsys fs.FS,
+	files []string,
+	funcs map[string]any,
+) (tmpls.Executor, error) {
+	if e.calls != nil {
+		e.calls.Add(1)
+	}
+	time.Sleep(e.delay)
+	return tmpls.HTMLEngine{}.Parse(fsys, files, funcs)
+}
+
+func TestParseLimits(t *testing.T) {
+	t.Parallel()
+
+	limitsFS := fstest.MapFS{
+		"common/a.html.tmpl":  &fstest.MapFile{Data: []byte(`{{ define "a" }}a{{ end }}`)},
+		"common/b.html.tmpl":  &fstest.MapFile{Data: []byte(`{{ define "b" }}b{{ end }}`)},
+		"page.html.tmpl":      &fstest.MapFile{Data: []byte(`{{ template "a" }}`)},
+		"large.html.tmpl":     &fstest.MapFile{Data: []byte(strings.Repeat("x", 100))},
+		"page.slow.html.tmpl": &fstest.MapFile{Data: []byte(`slow`)},
+	}
+
+	tests := []struct {
+		name        string
+		limits      tmpls.ParseLimits
+		glob        string
+		expectLimit string
+		expectFile  string
+	}{
+		{
+			name:   "should parse templates within limits",
+			limits: tmpls.ParseLimits{MaxFiles: 3, MaxFileSize: 100, MaxParseTime: time.Minute},
+			glob:   "large.html.tmpl",
+		},
+		{
+			name:        "should fail on too many files",
+			limits:      tmpls.ParseLimits{MaxFiles: 2},
+			glob:        "page.html.tmpl",
+			expectLimit: "MaxFiles",
+		},
+		{
+			name:        "should fail on large files",
+			limits:      tmpls.ParseLimits{MaxFileSize: 99},
+			glob:        "large.html.tmpl",
+			expectLimit: "MaxFileSize",
+			expectFile:  "large.html.tmpl",
+		},
+		{
+			name:        "should fail on slow parses",
+			limits:      tmpls.ParseLimits{MaxParseTime: time.Millisecond},
+			glob:        "page.slow.html.tmpl",
+			expectLimit: "MaxParseTime",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: limitsFS,
+					CommonGlob:  "common/*.html.tmpl",
+					ParseLimits: &test.limits,
+					Engines: map[string]tmpls.Engine{
+						".slow.html.tmpl": slowEngine{delay: 100 * time.Millisecond},
+					},
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = templates.Execute(test.glob, test.glob, nil)
+			if test.expectLimit == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var limitErr *tmpls.ParseLimitError
+			if !tmpls.IsParseError(err) || !errors.As(err, &limitErr) {
+				t.Fatalf("expected parse limit error but got %v", err)
+			}
+			if limitErr.Limit != test.expectLimit || limitErr.File != test.expectFile {
+				t.Fatalf(
+					"expected %s in %q but got %s in %q",
+					test.expectLimit,
+					test.expectFile,
+					limitErr.Limit,
+					limitErr.File,
+				)
+			}
+		})
+	}
+}
+
+func TestSlowParses(t *testing.T) {
+	t.Parallel()
+
+	calls := &atomic.Int64{}
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: fstest.MapFS{
+				"page.html.tmpl":         &fstest.MapFile{Data: []byte(`slow`)},
+				"common/empty.html.tmpl": &fstest.MapFile{},
+			},
+			CommonGlob:  "common/*.html.tmpl",
+			ParseLimits: &tmpls.ParseLimits{MaxParseTime: time.Millisecond},
+			Engines: map[string]tmpls.Engine{
+				".html.tmpl": slowEngine{delay: 100 * time.Millisecond, calls: calls},
+			},
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		var limitErr *tmpls.ParseLimitError
+		_, err := templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+		if !errors.As(err, &limitErr) {
+			t.Fatalf("expected parse limit error but got %v", err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected 1 parse while the first runs but got %d", n)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if _, err := templates.Execute("page.html.tmpl", "page.html.tmpl", nil); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected 2 parses once the first finished but got %d", n)
+	}
+}
@@ -0,0 +1,54 @@
+package tmpls
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SetLogger replaces the logger, or restores slog.Default if nil. It is safe
+// to call while templates are being executed.
+func (t *Templates) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	t.logger.Store(slog.New(&levelHandler{templates: t, handler: logger.Handler()}))
+}
+
+// SetLogLevel sets the minimum level of records logged by the Templates,
+// overriding the logger's own level so it can be raised or lowered at
+// runtime, e.g. to debug during an incident. It is safe to call while
+// templates are being executed.
+func (t *Templates) SetLogLevel(level slog.Level) {
+	t.level.Set(level)
+	t.levelSet.Store(true)
+}
+
+func (t *Templates) log() *slog.Logger {
+	return t.logger.Load()
+}
+
+// levelHandler defers to the wrapped handler's level until SetLogLevel is
+// called, after which the Templates' level alone decides.
+type levelHandler struct {
+	templates *Templates
+	handler   slog.Handler
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.templates.levelSet.Load() {
+		return level >= h.templates.level.Level()
+	}
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler.Handle(ctx, record)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{templates: h.templates, handler: h.handler.WithAttrs(attrs)}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{templates: h.templates, handler: h.handler.WithGroup(name)}
+}
@@ -0,0 +1,118 @@
+package tmpls_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestSetLogLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		setDebug    bool
+		expectDebug bool
+	}{
+		{
+			name:        "should defer to logger level by default",
+			expectDebug: false,
+		},
+		{
+			name:        "should log debug after raising level",
+			setDebug:    true,
+			expectDebug: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var logs bytes.Buffer
+			tmpls, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: testFS,
+					CommonGlob:  "common/*.html.tmpl",
+				},
+				slog.New(slog.NewTextHandler(&logs, nil)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.setDebug {
+				tmpls.SetLogLevel(slog.LevelDebug)
+			}
+
+			if _, err := tmpls.Execute(
+				"test.html.tmpl",
+				"test.html.tmpl",
+				templateData{Text: "world"},
+			); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := strings.Contains(logs.String(), "level=DEBUG"); got != test.expectDebug {
+				t.Fatalf("expectDebug=%v, got logs %q", test.expectDebug, logs.String())
+			}
+		})
+	}
+}
+
+func TestSetLogger(t *testing.T) {
+	t.Parallel()
+
+	var before, after bytes.Buffer
+	tmpls, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: testFS,
+			CommonGlob:  "common/*.html.tmpl",
+		},
+		slog.New(slog.NewTextHandler(&before, nil)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpls.SetLogger(slog.New(slog.NewTextHandler(&after, nil)))
+	tmpls.SetLogLevel(slog.LevelDebug)
+
+	if _, err := tmpls.Execute(
+		"test.html.tmpl",
+		"test.html.tmpl",
+		templateData{Text: "world"},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if before.Len() != 0 {
+		t.Fatalf("expected no logs on replaced logger but got %q", before.String())
+	}
+	if after.Len() == 0 {
+		t.Fatal("expected logs on new logger")
+	}
+}
+
+func TestNilLogger(t *testing.T) {
+	t.Parallel()
+
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: testFS,
+			CommonGlob:  "common/*.html.tmpl",
+		},
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	templates.SetLogger(nil)
+	if _, err := templates.Execute(
+		"test.html.tmpl",
+		"test.html.tmpl",
+		templateData{Text: "world"},
+	); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,113 @@
+package tmpls
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Meta describes a page for the metaTags func, which renders the title,
+// description, canonical link and OpenGraph and Twitter card tags. Empty
+// fields are omitted.
+type Meta struct {
+	Title       string
+	Description string
+	// Canonical is the page's absolute canonical URL, also used as og:url.
+	Canonical string
+	// Image is an absolute URL of the page's share image.
+	Image    string
+	Type     string
+	SiteName string
+	// TwitterCard defaults to summary_large_image with an Image and summary
+	// without.
+	TwitterCard string
+	// TwitterSite is the site's @username.
+	TwitterSite string
+}
+
+// Merge returns m with the non-empty fields of override replacing its own.
+func (m Meta) Merge(override Meta) Meta {
+	merge := func(field *string, value string) {
+		if value != "" {
+			*field = value
+		}
+	}
+	merge(&m.Title, override.Title)
+	merge(&m.Description, override.Description)
+	merge(&m.Canonical, override.Canonical)
+	merge(&m.Image, override.Image)
+	merge(&m.Type, override.Type)
+	merge(&m.SiteName, override.SiteName)
+	merge(&m.TwitterCard, override.TwitterCard)
+	merge(&m.TwitterSite, override.TwitterSite)
+	return m
+}
+
+// metaTags merges overrides, lowest priority first, over Config.Meta and
+// renders the result.
+func (t *Templates) metaTags(overrides ...any) (template.HTML, error) {
+	var meta Meta
+	if t.config.Meta != nil {
+		meta = *t.config.Meta
+	}
+	for _, override := range overrides {
+		switch o := override.(type) {
+		case Meta:
+			meta = meta.Merge(o)
+		case *Meta:
+			if o != nil {
+				meta = meta.Merge(*o)
+			}
+		case nil:
+		default:
+			return "", fmt.Errorf("metaTags: unsupported type %T", override)
+		}
+	}
+	return meta.html(), nil
+}
+
+func (m Meta) html() template.HTML {
+	var b strings.Builder
+	tag := func(format string, key string, value string) {
+		if value != "" {
+			fmt.Fprintf(
+				&b,
+				format,
+				template.HTMLEscapeString(key),
+				template.HTMLEscapeString(value),
+			)
+			b.WriteString("\n")
+		}
+	}
+	name := func(key string, value string) {
+		tag(`<meta name="%s" content="%s">`, key, value)
+	}
+	property := func(key string, value string) {
+		tag(`<meta property="%s" content="%s">`, key, value)
+	}
+
+	if m.Title != "" {
+		fmt.Fprintf(&b, "<title>%s</title>\n", template.HTMLEscapeString(m.Title))
+	}
+	name("description", m.Description)
+	tag(`<link rel="%s" href="%s">`, "canonical", m.Canonical)
+	property("og:title", m.Title)
+	property("og:description", m.Description)
+	property("og:url", m.Canonical)
+	property("og:image", m.Image)
+	property("og:type", m.Type)
+	property("og:site_name", m.SiteName)
+	card := m.TwitterCard
+	if card == "" {
+		card = "summary"
+		if m.Image != "" {
+			card = "summary_large_image"
+		}
+	}
+	name("twitter:card", card)
+	name("twitter:site", m.TwitterSite)
+	name("twitter:title", m.Title)
+	name("twitter:description", m.Description)
+	name("twitter:image", m.Image)
+	return template.HTML(b.String())
+}
@@ -0,0 +1,107 @@
+package tmpls_test
+
+import (
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestMetaTags(t *testing.T) {
+	t.Parallel()
+
+	defaults := &tmpls.Meta{
+		Title:       "Shop",
+		Description: "Default description",
+		SiteName:    "Shop",
+		TwitterSite: "@shop",
+		Type:        "website",
+	}
+
+	tests := []struct {
+		name        string
+		config      tmpls.Config
+		src         string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name:   "should render defaults",
+			config: tmpls.Config{Meta: defaults},
+			src:    `{{ metaTags }}`,
+			expected: `<title>Shop</title>
+<meta name="description" content="Default description">
+<meta property="og:title" content="Shop">
+<meta property="og:description" content="Default description">
+<meta property="og:type" content="website">
+<meta property="og:site_name" content="Shop">
+<meta name="twitter:card" content="summary">
+<meta name="twitter:site" content="@shop">
+<meta name="twitter:title" content="Shop">
+<meta name="twitter:description" content="Default description">
+`,
+		},
+		{
+			name:   "should override defaults in order",
+			config: tmpls.Config{Meta: defaults},
+			src:    `{{ metaTags .Section .Page }}`,
+			data: map[string]any{
+				"Section": tmpls.Meta{Title: "Section", Type: "article"},
+				"Page": &tmpls.Meta{
+					Title:     `Tom & "Jerry"`,
+					Canonical: "https://example.com/tom",
+					Image:     "https://example.com/tom.png",
+				},
+			},
+			expected: `<title>Tom &amp; &#34;Jerry&#34;</title>
+<meta name="description" content="Default description">
+<link rel="canonical" href="https://example.com/tom">
+<meta property="og:title" content="Tom &amp; &#34;Jerry&#34;">
+<meta property="og:description" content="Default description">
+<meta property="og:url" content="https://example.com/tom">
+<meta property="og:image" content="https://example.com/tom.png">
+<meta property="og:type" content="article">
+<meta property="og:site_name" content="Shop">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:site" content="@shop">
+<meta name="twitter:title" content="Tom &amp; &#34;Jerry&#34;">
+<meta name="twitter:description" content="Default description">
+<meta name="twitter:image" content="https://example.com/tom.png">
+`,
+		},
+		{
+			name:        "should reject other types",
+			src:         `{{ metaTags "title" }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, test.config, test.src, test.data)
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestMetaMerge(t *testing.T) {
+	t.Parallel()
+
+	merged := tmpls.Meta{Title: "a", Description: "b"}.Merge(tmpls.Meta{Title: "c"})
+	expected := tmpls.Meta{Title: "c", Description: "b"}
+	if merged != expected {
+		t.Fatalf("expected %v but got %v", expected, merged)
+	}
+}
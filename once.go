@@ -0,0 +1,33 @@
+package tmpls
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+)
+
+// once renders the content of a once block only the first time key is seen
+// in an execution, so partials included many times can emit their
+// dependencies once:
+//
+//	{{ once "chart-js" }}<script src="/chart.js"></script>{{ end }}
+func once(ctx context.Context) any {
+	return func(key string, name string, dot any) (template.HTML, error) {
+		executor, execution, err := executorFrom(ctx)
+		if err != nil {
+			return "", err
+		}
+		if execution.once[key] {
+			return "", nil
+		}
+		if execution.once == nil {
+			execution.once = map[string]bool{}
+		}
+		execution.once[key] = true
+		var buffer bytes.Buffer
+		if err := executor.ExecuteTemplate(&buffer, name, dot); err != nil {
+			return "", err
+		}
+		return template.HTML(buffer.String()), nil
+	}
+}
@@ -0,0 +1,70 @@
+package tmpls_test
+
+import (
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestOnce(t *testing.T) {
+	t.Parallel()
+
+	chart := `{{ define "chart" }}{{ once "chart-js" }}<script src="/chart.js"></script>{{ end }}` +
+		`<canvas>{{ . }}</canvas>{{ end }}`
+
+	tests := []struct {
+		name     string
+		src      string
+		data     any
+		expected string
+	}{
+		{
+			name: "should emit content once per execution",
+			src:  `{{ template "chart" "a" }}{{ template "chart" "b" }}`,
+			expected: `<script src="/chart.js"></script><canvas>a</canvas>` +
+				`<canvas>b</canvas>`,
+		},
+		{
+			name:     "should emit content once in range",
+			src:      `{{ range . }}{{ once "header" }}<h1>{{ . }}</h1>{{ end }}{{ . }}{{ end }}`,
+			data:     []string{"a", "b"},
+			expected: `<h1>a</h1>ab`,
+		},
+		{
+			name:     "should track keys separately",
+			src:      `{{ once "a" }}a{{ end }}{{ once "b" }}b{{ end }}{{ once "a" }}c{{ end }}`,
+			expected: `ab`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: fstest.MapFS{
+						"common/chart.html.tmpl": &fstest.MapFile{Data: []byte(chart)},
+						"page.html.tmpl":         &fstest.MapFile{Data: []byte(test.src)},
+					},
+					CommonGlob: "common/*.html.tmpl",
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// each execution emits once blocks again
+			for range 2 {
+				output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", test.data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if output != test.expected {
+					t.Fatalf("expected %s but got %s", test.expected, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package tmpls
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Paginator describes one page of a list, rendered by the pagination func or
+// used by a partial for custom markup.
+type Paginator struct {
+	// Total is the number of items across every page.
+	Total int
+	// PerPage is the number of items on a page.
+	PerPage int
+	// Current is the current page, starting at 1.
+	Current int
+	// URL is the list's URL, usually the request's, whose query the page
+	// links keep.
+	URL *url.URL
+	// Param names the query param holding the page. Defaults to "page".
+	Param string
+}
+
+// Page is a link in a Paginator's page range. Gaps stand for skipped pages.
+type Page struct {
+	Number  int
+	URL     string
+	Current bool
+	Gap     bool
+}
+
+// NewPaginator returns a Paginator for the page of r's URL named by the
+// "page" query param, clamped to the pages available.
+func NewPaginator(r *http.Request, total int, perPage int) Paginator {
+	p := Paginator{Total: total, PerPage: perPage, URL: r.URL}
+	p.Current, _ = strconv.Atoi(r.URL.Query().Get(p.param()))
+	p.Current = max(1, min(p.Current, p.Pages()))
+	return p
+}
+
+// Pages returns the number of pages, at least 1.
+func (p Paginator) Pages() int {
+	if p.PerPage <= 0 || p.Total <= 0 {
+		return 1
+	}
+	return (p.Total + p.PerPage - 1) / p.PerPage
+}
+
+// Offset returns the index of the first item on the current page.
+func (p Paginator) Offset() int {
+	return max(0, (p.Current-1)*p.PerPage)
+}
+
+func (p Paginator) HasPrev() bool {
+	return p.Current > 1
+}
+
+func (p Paginator) HasNext() bool {
+	return p.Current < p.Pages()
+}
+
+// PrevURL returns the link to the previous page, or "" on the first.
+func (p Paginator) PrevURL() string {
+	if !p.HasPrev() {
+		return ""
+	}
+	return p.PageURL(p.Current - 1)
+}
+
+// NextURL returns the link to the next page, or "" on the last.
+func (p Paginator) NextURL() string {
+	if !p.HasNext() {
+		return ""
+	}
+	return p.PageURL(p.Current + 1)
+}
+
+// PageURL returns the link to page n, keeping the rest of the URL's query.
+// The first page drops the param for a canonical URL.
+func (p Paginator) PageURL(n int) string {
+	u := p.URL
+	if u == nil {
+		u = &url.URL{}
+	}
+	var value any = n
+	if n <= 1 {
+		value = nil
+	}
+	query, _ := setQuery(u.Query(), []any{p.param(), value})
+	// an empty link would keep the current page's query
+	if link := withQuery(u, query); link != "" {
+		return link
+	}
+	return "?"
+}
+
+// Range returns the first and last pages, and window pages either side of
+// the current one, with gaps between them.
+func (p Paginator) Range(window int) []Page {
+	pages := p.Pages()
+	var out []Page
+	for n := 1; n <= pages; n++ {
+		if n != 1 && n != pages && (n < p.Current-window || n > p.Current+window) {
+			if len(out) > 0 && !out[len(out)-1].Gap {
+				out = append(out, Page{Gap: true})
+			}
+			continue
+		}
+		out = append(out, Page{Number: n, URL: p.PageURL(n), Current: n == p.Current})
+	}
+	return out
+}
+
+// HTML renders previous and next links around the page range as a nav,
+// rendering nothing if there is a single page.
+func (p Paginator) HTML() template.HTML {
+	if p.Pages() <= 1 {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString(`<nav aria-label="Pagination"><ul>`)
+	if p.HasPrev() {
+		fmt.Fprintf(&s, `<li><a href="%s" rel="prev">Previous</a></li>`, safeHref(p.PrevURL()))
+	}
+	for _, page := range p.Range(2) {
+		switch {
+		case page.Gap:
+			s.WriteString(`<li>…</li>`)
+		case page.Current:
+			fmt.Fprintf(&s, `<li aria-current="page">%d</li>`, page.Number)
+		default:
+			fmt.Fprintf(&s, `<li><a href="%s">%d</a></li>`, safeHref(page.URL), page.Number)
+		}
+	}
+	if p.HasNext() {
+		fmt.Fprintf(&s, `<li><a href="%s" rel="next">Next</a></li>`, safeHref(p.NextURL()))
+	}
+	s.WriteString(`</ul></nav>`)
+	return template.HTML(s.String())
+}
+
+func (p Paginator) param() string {
+	if p.Param == "" {
+		return "page"
+	}
+	return p.Param
+}
@@ -0,0 +1,108 @@
+package tmpls_test
+
+import (
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestNewPaginator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		target   string
+		total    int
+		expected int
+	}{
+		{name: "should read page", target: "/items?page=3", total: 100, expected: 3},
+		{name: "should default to first page", target: "/items", total: 100, expected: 1},
+		{name: "should clamp to last page", target: "/items?page=30", total: 100, expected: 10},
+		{name: "should clamp invalid page", target: "/items?page=-2", total: 100, expected: 1},
+		{name: "should have a page when empty", target: "/items?page=2", total: 0, expected: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			p := tmpls.NewPaginator(httptest.NewRequest("GET", test.target, nil), test.total, 10)
+			if p.Current != test.expected {
+				t.Fatalf("expected page %d but got %d", test.expected, p.Current)
+			}
+		})
+	}
+}
+
+func TestPaginator(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest("GET", "/items?sort=name&page=5", nil)
+	p := tmpls.NewPaginator(r, 95, 10)
+
+	if p.Pages() != 10 || p.Offset() != 40 {
+		t.Fatalf("expected 10 pages at offset 40 but got %d at %d", p.Pages(), p.Offset())
+	}
+	if p.PrevURL() != "/items?page=4&sort=name" || p.NextURL() != "/items?page=6&sort=name" {
+		t.Fatalf("expected prev and next urls but got %s and %s", p.PrevURL(), p.NextURL())
+	}
+	if p.PageURL(1) != "/items?sort=name" {
+		t.Fatalf("expected first page without param but got %s", p.PageURL(1))
+	}
+	var numbers []int
+	for _, page := range p.Range(1) {
+		numbers = append(numbers, page.Number)
+	}
+	if expected := []int{1, 0, 4, 5, 6, 0, 10}; !slices.Equal(numbers, expected) {
+		t.Fatalf("expected range %v but got %v", expected, numbers)
+	}
+
+	first := tmpls.Paginator{Total: 30, PerPage: 10, Current: 1}
+	if first.PrevURL() != "" || first.NextURL() != "?page=2" || first.PageURL(1) != "?" {
+		t.Fatalf("expected relative urls but got %s, %s", first.NextURL(), first.PageURL(1))
+	}
+}
+
+func TestPagination(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     tmpls.Paginator
+		expected string
+	}{
+		{
+			name: "should render pages",
+			data: tmpls.Paginator{Total: 100, PerPage: 10, Current: 2},
+			expected: `<nav aria-label="Pagination"><ul>` +
+				`<li><a href="?" rel="prev">Previous</a></li>` +
+				`<li><a href="?">1</a></li>` +
+				`<li aria-current="page">2</li>` +
+				`<li><a href="?page=3">3</a></li>` +
+				`<li><a href="?page=4">4</a></li>` +
+				`<li>…</li>` +
+				`<li><a href="?page=10">10</a></li>` +
+				`<li><a href="?page=3" rel="next">Next</a></li>` +
+				`</ul></nav>`,
+		},
+		{
+			name:     "should render nothing for one page",
+			data:     tmpls.Paginator{Total: 5, PerPage: 10, Current: 1},
+			expected: ``,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{}, `{{ pagination . }}`, test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package tmpls
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"text/template/parse"
+)
+
+// resolvedPaths returns the paths in the data, such as ["Post", "Comments"]
+// for .Post.Comments, of the values passed to the resolve func by the named
+// template where dot is still the data. Templates it calls aren't followed.
+func (e *htmlExecutor) resolvedPaths(name string) [][]string {
+	if paths, ok := e.paths.Load(name); ok {
+		return paths.([][]string)
+	}
+	var paths [][]string
+	if tmpl := e.prototype.Lookup(name); tmpl != nil && tmpl.Tree != nil {
+		paths = findResolved(tmpl.Tree.Root, true, nil)
+	}
+	e.paths.Store(name, paths)
+	return paths
+}
+
+func findResolved(node parse.Node, rootDot bool, paths [][]string) [][]string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return paths
+		}
+		for _, child := range n.Nodes {
+			paths = findResolved(child, rootDot, paths)
+		}
+	case *parse.ActionNode:
+		paths = findResolved(n.Pipe, rootDot, paths)
+	case *parse.PipeNode:
+		if n == nil {
+			return paths
+		}
+		for _, cmd := range n.Cmds {
+			paths = findResolved(cmd, rootDot, paths)
+		}
+	case *parse.CommandNode:
+		if ident, ok := n.Args[0].(*parse.IdentifierNode); ok &&
+			ident.Ident == "resolve" && len(n.Args) == 2 {
+			switch arg := n.Args[1].(type) {
+			case *parse.FieldNode:
+				if rootDot {
+					paths = append(paths, arg.Ident)
+				}
+			case *parse.VariableNode:
+				if arg.Ident[0] == "$" && len(arg.Ident) > 1 {
+					paths = append(paths, arg.Ident[1:])
+				}
+			}
+		}
+		for _, arg := range n.Args {
+			paths = findResolved(arg, rootDot, paths)
+		}
+	case *parse.IfNode:
+		paths = findResolved(n.Pipe, rootDot, paths)
+		paths = findResolved(n.List, rootDot, paths)
+		paths = findResolved(n.ElseList, rootDot, paths)
+	case *parse.RangeNode:
+		paths = findResolved(n.Pipe, rootDot, paths)
+		paths = findResolved(n.List, false, paths)
+		paths = findResolved(n.ElseList, rootDot, paths)
+	case *parse.WithNode:
+		paths = findResolved(n.Pipe, rootDot, paths)
+		paths = findResolved(n.List, false, paths)
+		paths = findResolved(n.ElseList, rootDot, paths)
+	case *parse.TemplateNode:
+		paths = findResolved(n.Pipe, rootDot, paths)
+	}
+	return paths
+}
+
+// prefetch resolves the Providers the named template passes to resolve from
+// data before it executes, up to Config.ResolveConcurrency at a time, so
+// independent backend calls overlap. Providers that can't be memoized, see
+// resolve, are left for the template.
+func (t *Templates) prefetch(ctx context.Context, tmpl Executor, name string, data any) {
+	e, ok := tmpl.(*htmlExecutor)
+	execution := executionFrom(ctx)
+	if !ok || execution == nil {
+		return
+	}
+	var providers []Provider
+	seen := map[Provider]bool{}
+	for _, path := range e.resolvedPaths(name) {
+		value, ok := lookupPath(reflect.ValueOf(data), path)
+		if !ok {
+			continue
+		}
+		provider, ok := value.Interface().(Provider)
+		if !ok || !reflect.TypeOf(provider).Comparable() || seen[provider] {
+			continue
+		}
+		if _, ok := execution.resolved[provider]; ok {
+			continue
+		}
+		seen[provider] = true
+		providers = append(providers, provider)
+	}
+	if len(providers) == 0 {
+		return
+	}
+	results := make([]resolution, len(providers))
+	workers := make(chan struct{}, t.config.ResolveConcurrency)
+	var wg sync.WaitGroup
+	wg.Add(len(providers))
+	for i, provider := range providers {
+		workers <- struct{}{}
+		go func() {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			value, err := provider.Resolve(ctx)
+			results[i] = resolution{value: value, err: err}
+		}()
+	}
+	wg.Wait()
+	if execution.resolved == nil {
+		execution.resolved = map[Provider]resolution{}
+	}
+	for i, provider := range providers {
+		execution.resolved[provider] = results[i]
+	}
+}
+
+// lookupPath returns the field or map value at path in v, as the template
+// would evaluate it.
+func lookupPath(v reflect.Value, path []string) (reflect.Value, bool) {
+	for _, name := range path {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return reflect.Value{}, false
+			}
+			v = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		case reflect.Struct:
+			field, ok := v.Type().FieldByName(name)
+			if !ok || !field.IsExported() {
+				return reflect.Value{}, false
+			}
+			v = v.FieldByIndex(field.Index)
+		default:
+			return reflect.Value{}, false
+		}
+		if !v.IsValid() {
+			return reflect.Value{}, false
+		}
+	}
+	if !v.CanInterface() {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
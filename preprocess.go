@@ -0,0 +1,264 @@
+package tmpls
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// blockFuncs are funcs called as blocks:
+//
+//	{{ name args }}content{{ end }}
+//
+// Funcs can't take template content as an argument, so before parsing the
+// content is hoisted into a template of its own and the block becomes
+//
+//	{{ name args "hoisted" . }}
+//
+// leaving the func to execute the hoisted template with the block's dot.
+// Variables declared outside the block aren't visible inside it. Bare
+// durations in args, such as 5m, are quoted so the func receives strings.
+var blockFuncs = map[string]bool{
+	"wrap":   true,
+	"cache":  true,
+	"once":   true,
+	"async":  true,
+	"within": true,
+}
+
+// elseBlockFuncs are block funcs taking an else branch, hoisted into a second
+// template passed after the first:
+//
+//	{{ name args "hoisted" "hoisted-else" . }}
+//
+// Without an else branch the second name is empty.
+var elseBlockFuncs = map[string]bool{
+	"within": true,
+}
+
+// preprocess rewrites the block funcs in src, a file named file.
+func preprocess(file string, src string) (string, error) {
+	if !hasBlockFuncs(src) {
+		return src, nil
+	}
+	type frame struct {
+		block  *action
+		output *strings.Builder
+		// content and elseAction are set once the block's else is reached
+		content    *strings.Builder
+		elseAction *action
+	}
+	main := &strings.Builder{}
+	var hoisted strings.Builder
+	var frames []frame
+	hoistedCount := 0
+	output := main
+	for len(src) > 0 {
+		start := strings.Index(src, "{{")
+		if start < 0 {
+			output.WriteString(src)
+			break
+		}
+		output.WriteString(src[:start])
+		a, err := parseAction(src[start:])
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", file, err)
+		}
+		src = src[start+len(a.text):]
+
+		switch {
+		case blockFuncs[a.keyword]:
+			frames = append(frames, frame{block: a, output: output})
+			output = &strings.Builder{}
+		case a.keyword == "if" || a.keyword == "range" || a.keyword == "with" ||
+			a.keyword == "block" || a.keyword == "define":
+			frames = append(frames, frame{})
+			output.WriteString(a.text)
+		case a.keyword == "else" && len(frames) > 0 && frames[len(frames)-1].block != nil:
+			top := &frames[len(frames)-1]
+			if !elseBlockFuncs[top.block.keyword] || top.elseAction != nil {
+				return "", fmt.Errorf("%s: else is not supported in %s", file, top.block.keyword)
+			}
+			top.content, top.elseAction = output, a
+			output = &strings.Builder{}
+		case a.keyword == "end" && len(frames) > 0:
+			top := frames[len(frames)-1]
+			frames = frames[:len(frames)-1]
+			if top.block == nil {
+				output.WriteString(a.text)
+				continue
+			}
+			hoistedCount++
+			name := fmt.Sprintf("tmpls/%s#%d", file, hoistedCount)
+			names := strconv.Quote(name)
+			// trim markers move with the whitespace they trimmed
+			if top.elseAction != nil {
+				hoist(&hoisted, name, top.block.rtrim, top.content.String(), top.elseAction.ltrim)
+				hoist(&hoisted, name+"-else", top.elseAction.rtrim, output.String(), a.ltrim)
+				names += " " + strconv.Quote(name+"-else")
+			} else {
+				hoist(&hoisted, name, top.block.rtrim, output.String(), a.ltrim)
+				if elseBlockFuncs[top.block.keyword] {
+					names += ` ""`
+				}
+			}
+			output = top.output
+			fmt.Fprintf(
+				output,
+				`{{%s %s %s %s . %s}}`,
+				trimMarker(top.block.ltrim),
+				top.block.keyword,
+				quoteDurations(top.block.args),
+				names,
+				trimMarker(a.rtrim),
+			)
+		default:
+			output.WriteString(a.text)
+		}
+	}
+	for _, f := range frames {
+		if f.block != nil {
+			return "", fmt.Errorf("%s: unclosed %s", file, f.block.keyword)
+		}
+	}
+	main.WriteString(hoisted.String())
+	return main.String(), nil
+}
+
+// hoist writes a template named name holding content.
+func hoist(out *strings.Builder, name string, rtrim bool, content string, ltrim bool) {
+	fmt.Fprintf(
+		out,
+		`{{ define %q %s}}%s{{%s end }}`,
+		name,
+		trimMarker(rtrim),
+		content,
+		trimMarker(ltrim),
+	)
+}
+
+const argSeparators = " \t\r\n()|"
+
+var durationPattern = regexp.MustCompile(`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`)
+
+// quoteDurations quotes the words in args outside strings that are durations.
+func quoteDurations(args string) string {
+	var out strings.Builder
+	for len(args) > 0 {
+		switch c := args[0]; {
+		case c == '"' || c == '`' || c == '\'':
+			i := quotedEnd(args)
+			out.WriteString(args[:i])
+			args = args[i:]
+		case strings.IndexByte(argSeparators, c) >= 0:
+			out.WriteByte(c)
+			args = args[1:]
+		default:
+			i := strings.IndexAny(args, argSeparators+"\"`'")
+			if i < 0 {
+				i = len(args)
+			}
+			word := args[:i]
+			if durationPattern.MatchString(word) {
+				word = strconv.Quote(word)
+			}
+			out.WriteString(word)
+			args = args[i:]
+		}
+	}
+	return out.String()
+}
+
+// quotedEnd returns the index just past the quoted string or character at the
+// start of src, or len(src) if it is unterminated.
+func quotedEnd(src string) int {
+	quote := src[0]
+	for i := 1; i < len(src); i++ {
+		if src[i] == '\\' && quote != '`' {
+			i++
+		} else if src[i] == quote {
+			return i + 1
+		}
+	}
+	return len(src)
+}
+
+func hasBlockFuncs(src string) bool {
+	for name := range blockFuncs {
+		if strings.Contains(src, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func trimMarker(trim bool) string {
+	if trim {
+		return "-"
+	}
+	return ""
+}
+
+// action is a {{ }} action split into its trim markers, first word and the
+// rest.
+type action struct {
+	text    string
+	ltrim   bool
+	rtrim   bool
+	keyword string
+	args    string
+}
+
+// parseAction parses the action at the start of src, skipping over strings
+// and comments that may contain delimiters.
+func parseAction(src string) (*action, error) {
+	end := -1
+	if strings.HasPrefix(strings.TrimPrefix(src[2:], "- "), "/*") {
+		if i := strings.Index(src, "*/"); i >= 0 {
+			if j := strings.Index(src[i:], "}}"); j >= 0 {
+				end = i + j + 2
+			}
+		}
+	} else {
+		end = actionEnd(src)
+	}
+	if end < 0 {
+		return nil, fmt.Errorf("unclosed action")
+	}
+	a := &action{text: src[:end]}
+	inner := src[2 : end-2]
+	if strings.HasPrefix(inner, "- ") {
+		a.ltrim = true
+		inner = inner[2:]
+	}
+	if strings.HasSuffix(inner, " -") {
+		a.rtrim = true
+		inner = inner[:len(inner)-2]
+	}
+	inner = strings.TrimSpace(inner)
+	a.keyword, a.args, _ = strings.Cut(inner, " ")
+	a.args = strings.TrimSpace(a.args)
+	return a, nil
+}
+
+// actionEnd returns the index just past the action's closing delimiter.
+func actionEnd(src string) int {
+	var quote byte
+	for i := 2; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`' || c == '\'':
+			quote = c
+		case c == '}' && i+1 < len(src) && src[i+1] == '}':
+			return i + 2
+		}
+	}
+	return -1
+}
@@ -0,0 +1,201 @@
+// Package preview serves every template in a filesystem for designers to
+// iterate on without running the full application. Templates are rendered
+// uncached with their fixtures, parse and execution errors are shown
+// inline, and pages reload when files change.
+package preview
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/fivethirty/tmpls/fixture"
+)
+
+// VersionPath serves a hash of the filesystem, polled by previews to reload.
+const VersionPath = "/_tmpls/version"
+
+type server struct {
+	config    tmpls.Config
+	templates *tmpls.Templates
+	logger    *slog.Logger
+}
+
+// Handler returns a preview server for config. Caching is always disabled so
+// edits show up on reload.
+func Handler(config tmpls.Config, logger *slog.Logger) (http.Handler, error) {
+	config.DisableCache = true
+	templates, err := tmpls.New(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	s := &server{config: config, templates: templates, logger: logger}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.index)
+	mux.HandleFunc("GET /render/{path...}", s.render)
+	mux.HandleFunc("GET "+VersionPath, s.version)
+	return mux, nil
+}
+
+// Pages lists the templates of fsys that are not matched by commonGlob,
+// skipping fixtures.
+func Pages(fsys fs.FS, commonGlob string) ([]string, error) {
+	var pages []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		if common, _ := path.Match(commonGlob, p); !common && !fixture.IsFixture(p) {
+			pages = append(pages, p)
+		}
+		return nil
+	})
+	return pages, err
+}
+
+type indexEntry struct {
+	Path  string
+	Error string
+}
+
+func (s *server) index(w http.ResponseWriter, _ *http.Request) {
+	pages, err := Pages(s.config.TemplatesFS, s.config.CommonGlob)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	entries := make([]indexEntry, 0, len(pages))
+	for _, page := range pages {
+		entry := indexEntry{Path: page}
+		if _, err := s.execute(page); err != nil {
+			entry.Error = err.Error()
+		}
+		entries = append(entries, entry)
+	}
+	s.write(w, http.StatusOK, indexTemplate, entries)
+}
+
+func (s *server) render(w http.ResponseWriter, r *http.Request) {
+	page := r.PathValue("path")
+	output, err := s.execute(page)
+	if err != nil {
+		s.write(w, http.StatusInternalServerError, errorTemplate, indexEntry{
+			Path:  page,
+			Error: err.Error(),
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = fmt.Fprint(w, output, reloadScript)
+}
+
+func (s *server) version(w http.ResponseWriter, _ *http.Request) {
+	version, err := Version(s.config.TemplatesFS)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = fmt.Fprint(w, version)
+}
+
+// execute renders page with its fixture, if it has one.
+func (s *server) execute(page string) (string, error) {
+	data, _, err := fixture.Load(s.config.TemplatesFS, page)
+	if err != nil {
+		return "", err
+	}
+	return s.templates.Execute(page, path.Base(page), data)
+}
+
+func (s *server) write(w http.ResponseWriter, status int, tmpl *template.Template, data any) {
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		s.logger.Error("Failed to render preview page", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = buffer.WriteTo(w)
+	_, _ = fmt.Fprint(w, reloadScript)
+}
+
+// Version returns a hash of the names and contents of every file in fsys,
+// which changes whenever a template is added, removed or edited.
+func Version(fsys fs.FS) (string, error) {
+	hash := sha256.New()
+	var files []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			files = append(files, p)
+		}
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	slices.Sort(files)
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00", file, len(data))
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+var reloadScript = strings.ReplaceAll(`
+<script>
+(() => {
+  let version;
+  setInterval(async () => {
+    const response = await fetch("VERSION_PATH").catch(() => null);
+    if (!response || !response.ok) return;
+    const latest = await response.text();
+    if (version && latest !== version) location.reload();
+    version = latest;
+  }, 1000);
+})();
+</script>
+`, "VERSION_PATH", VersionPath)
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!doctype html>
+<title>Templates</title>
+<style>
+  body { font-family: sans-serif; margin: 2rem; }
+  li { margin: .25rem 0; }
+  pre { color: #b00020; white-space: pre-wrap; margin: .25rem 0 0 1rem; }
+</style>
+<h1>Templates</h1>
+<ul>
+{{- range . }}
+  <li>
+    <a href="/render/{{ .Path }}">{{ .Path }}</a>
+    {{- if .Error }}<pre>{{ .Error }}</pre>{{ end }}
+  </li>
+{{- end }}
+</ul>
+`))
+
+var errorTemplate = template.Must(template.New("error").Parse(`<!doctype html>
+<title>{{ .Path }}</title>
+<style>
+  body { font-family: sans-serif; margin: 2rem; }
+  pre { color: #b00020; white-space: pre-wrap; }
+</style>
+<p><a href="/">All templates</a></p>
+<h1>{{ .Path }}</h1>
+<pre>{{ .Error }}</pre>
+`))
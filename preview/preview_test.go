@@ -0,0 +1,140 @@
+package preview_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/fivethirty/tmpls/preview"
+)
+
+func newServer(t *testing.T, fsys fstest.MapFS) *httptest.Server {
+	t.Helper()
+	handler, err := preview.Handler(
+		tmpls.Config{TemplatesFS: fsys, CommonGlob: "common/*.html.tmpl"},
+		slog.New(slog.DiscardHandler),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response.StatusCode, string(body)
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	server := newServer(t, fstest.MapFS{
+		"common/layout.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ define "layout" }}<main>{{ . }}</main>{{ end }}`),
+		},
+		"ok.html.tmpl":        &fstest.MapFile{Data: []byte(`{{ template "layout" "hi" }}`)},
+		"broken.html.tmpl":    &fstest.MapFile{Data: []byte(`{{ if }}`)},
+		"data.html.tmpl":      &fstest.MapFile{Data: []byte(`<h1>{{ .Title }}</h1>`)},
+		"data.html.tmpl.json": &fstest.MapFile{Data: []byte(`{"Title": "From fixture"}`)},
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		status   int
+		contains []string
+		excludes []string
+	}{
+		{
+			name:   "should list pages with inline errors",
+			path:   "/",
+			status: http.StatusOK,
+			contains: []string{
+				`href="/render/ok.html.tmpl"`,
+				`href="/render/broken.html.tmpl"`,
+				"missing value for if",
+			},
+			excludes: []string{"common/layout.html.tmpl", "data.html.tmpl.json"},
+		},
+		{
+			name:     "should render page with reload script",
+			path:     "/render/ok.html.tmpl",
+			status:   http.StatusOK,
+			contains: []string{"<main>hi</main>", preview.VersionPath},
+		},
+		{
+			name:     "should render page with fixture",
+			path:     "/render/data.html.tmpl",
+			status:   http.StatusOK,
+			contains: []string{"<h1>From fixture</h1>"},
+		},
+		{
+			name:     "should show parse error",
+			path:     "/render/broken.html.tmpl",
+			status:   http.StatusInternalServerError,
+			contains: []string{"missing value for if", preview.VersionPath},
+		},
+		{
+			name:     "should show missing page error",
+			path:     "/render/missing.html.tmpl",
+			status:   http.StatusInternalServerError,
+			contains: []string{"pattern matches no files"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			status, body := get(t, server.URL+test.path)
+			if status != test.status {
+				t.Fatalf("expected %d but got %d", test.status, status)
+			}
+			for _, s := range test.contains {
+				if !strings.Contains(body, s) {
+					t.Fatalf("expected %s to contain %s", body, s)
+				}
+			}
+			for _, s := range test.excludes {
+				if strings.Contains(body, s) {
+					t.Fatalf("expected %s not to contain %s", body, s)
+				}
+			}
+		})
+	}
+}
+
+func TestVersion(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"common/layout.html.tmpl": &fstest.MapFile{},
+		"page.html.tmpl":          &fstest.MapFile{Data: []byte("a")},
+	}
+	server := newServer(t, fsys)
+
+	_, first := get(t, server.URL+preview.VersionPath)
+	_, second := get(t, server.URL+preview.VersionPath)
+	if first != second {
+		t.Fatalf("expected %s but got %s", first, second)
+	}
+	fsys["page.html.tmpl"] = &fstest.MapFile{Data: []byte("b")}
+	if _, third := get(t, server.URL+preview.VersionPath); third == first {
+		t.Fatalf("expected version to change after edit but got %s", third)
+	}
+}
@@ -0,0 +1,128 @@
+package tmpls
+
+import (
+	"fmt"
+	"html/template"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// PropError reports a partial called with data missing a required prop or
+// holding one of the wrong type.
+type PropError struct {
+	Prop     string
+	Expected string
+	// Got is the type found, empty if the prop is missing.
+	Got string
+}
+
+func (e *PropError) Error() string {
+	if e.Got == "" {
+		return fmt.Sprintf("missing required prop %q", e.Prop)
+	}
+	return fmt.Sprintf("prop %q should be %s but is %s", e.Prop, e.Expected, e.Got)
+}
+
+// props validates the data a partial was called with against declarations
+// such as "title", "count:int" and "items?:slice", where ? marks optional
+// props. It renders nothing, so partials declare their props with
+//
+//	{{ props . "title:string" "items?:slice" }}
+//
+// Data must be a map with string keys or a struct, or a pointer to one.
+func props(data any, declarations ...string) (string, error) {
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			break
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Map && value.Kind() != reflect.Struct {
+		return "", fmt.Errorf("props require a map or struct but got %T", data)
+	}
+	for _, declaration := range declarations {
+		name, kind, _ := strings.Cut(declaration, ":")
+		name, optional := strings.CutSuffix(name, "?")
+		if kind == "" {
+			kind = "any"
+		}
+		check, ok := propKinds[kind]
+		if !ok {
+			return "", fmt.Errorf("prop %q has unknown type %q", name, kind)
+		}
+		prop, ok := lookupProp(value, name)
+		if !ok {
+			if optional {
+				continue
+			}
+			return "", &PropError{Prop: name, Expected: kind}
+		}
+		if !check(prop) {
+			return "", &PropError{Prop: name, Expected: kind, Got: prop.Type().String()}
+		}
+	}
+	return "", nil
+}
+
+// lookupProp returns the named map entry or struct field, reporting false if
+// it is missing or nil.
+func lookupProp(value reflect.Value, name string) (reflect.Value, bool) {
+	var prop reflect.Value
+	if value.Kind() == reflect.Map {
+		if value.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		prop = value.MapIndex(reflect.ValueOf(name).Convert(value.Type().Key()))
+	} else {
+		field, ok := value.Type().FieldByName(name)
+		if !ok || !field.IsExported() {
+			return reflect.Value{}, false
+		}
+		prop = value.FieldByIndex(field.Index)
+	}
+	for prop.IsValid() && (prop.Kind() == reflect.Interface || prop.Kind() == reflect.Pointer) {
+		if prop.IsNil() {
+			return reflect.Value{}, false
+		}
+		prop = prop.Elem()
+	}
+	return prop, prop.IsValid()
+}
+
+var (
+	timeType = reflect.TypeFor[time.Time]()
+	htmlType = reflect.TypeFor[template.HTML]()
+)
+
+var propKinds = map[string]func(reflect.Value) bool{
+	"any": func(reflect.Value) bool { return true },
+	"string": func(v reflect.Value) bool {
+		return v.Kind() == reflect.String
+	},
+	"bool": func(v reflect.Value) bool {
+		return v.Kind() == reflect.Bool
+	},
+	"int": func(v reflect.Value) bool {
+		return v.CanInt() || v.CanUint()
+	},
+	"float": func(v reflect.Value) bool {
+		return v.CanFloat()
+	},
+	"number": func(v reflect.Value) bool {
+		return v.CanInt() || v.CanUint() || v.CanFloat()
+	},
+	"slice": func(v reflect.Value) bool {
+		return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+	},
+	"map": func(v reflect.Value) bool {
+		return v.Kind() == reflect.Map || v.Kind() == reflect.Struct
+	},
+	"time": func(v reflect.Value) bool {
+		return v.Type() == timeType
+	},
+	"html": func(v reflect.Value) bool {
+		return v.Type() == htmlType
+	},
+}
@@ -0,0 +1,103 @@
+package tmpls_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestProps(t *testing.T) {
+	t.Parallel()
+
+	button := `{{ define "button" }}` +
+		`{{ props . "Label:string" "Count?:int" "Items?:slice" "Disabled?:bool" }}` +
+		`<button>{{ .Label }}</button>{{ end }}`
+
+	type buttonProps struct {
+		Label string
+		Count int
+	}
+
+	tests := []struct {
+		name        string
+		data        any
+		expected    string
+		expectedErr *tmpls.PropError
+		expectError bool
+	}{
+		{
+			name:     "should accept map with required props",
+			data:     map[string]any{"Label": "Save", "Count": 2},
+			expected: "<button>Save</button>",
+		},
+		{
+			name:     "should accept struct pointer",
+			data:     &buttonProps{Label: "Save"},
+			expected: "<button>Save</button>",
+		},
+		{
+			name:        "should reject missing required prop",
+			data:        map[string]any{"Count": 2},
+			expectedErr: &tmpls.PropError{Prop: "Label", Expected: "string"},
+		},
+		{
+			name:        "should reject nil required prop",
+			data:        map[string]any{"Label": nil},
+			expectedErr: &tmpls.PropError{Prop: "Label", Expected: "string"},
+		},
+		{
+			name:        "should reject wrong type",
+			data:        map[string]any{"Label": "Save", "Items": "a,b"},
+			expectedErr: &tmpls.PropError{Prop: "Items", Expected: "slice", Got: "string"},
+		},
+		{
+			name:        "should reject non map data",
+			data:        "Save",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: fstest.MapFS{
+						"common/button.html.tmpl": &fstest.MapFile{Data: []byte(button)},
+						"page.html.tmpl": &fstest.MapFile{
+							Data: []byte(`{{ template "button" . }}`),
+						},
+					},
+					CommonGlob: "common/*.html.tmpl",
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", test.data)
+			if test.expectedErr != nil {
+				var propErr *tmpls.PropError
+				if !errors.As(err, &propErr) || *propErr != *test.expectedErr {
+					t.Fatalf("expected %v but got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package tmpls
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// QREncoder renders QR codes for the qrCode func, e.g. wrapping a QR library.
+type QREncoder interface {
+	// Encode returns an image of a QR code for value, size pixels wide, and
+	// its media type such as "image/png".
+	Encode(value string, size int) (image []byte, mediaType string, err error)
+}
+
+// QREncoderFunc adapts an ordinary function to a QREncoder.
+type QREncoderFunc func(value string, size int) ([]byte, string, error)
+
+func (f QREncoderFunc) Encode(value string, size int) ([]byte, string, error) {
+	return f(value, size)
+}
+
+// qrCode returns a data URI of a QR code for value from Config.QRCode, for
+// 2FA setup and ticket pages without a separate image endpoint:
+//
+//	<img src="{{ qrCode .OTPAuthURL 200 }}" alt="Scan to set up">
+func (t *Templates) qrCode(value string, size int) (template.URL, error) {
+	if t.config.QRCode == nil {
+		return "", errors.New("qrCode: Config.QRCode is not set")
+	}
+	if size <= 0 {
+		return "", fmt.Errorf("qrCode: size must be positive but got %d", size)
+	}
+	image, mediaType, err := t.config.QRCode.Encode(value, size)
+	if err != nil {
+		return "", fmt.Errorf("qrCode: %w", err)
+	}
+	// anything else, such as text/html, could run script when opened
+	if !strings.HasPrefix(mediaType, "image/") || strings.ContainsAny(mediaType, ",; ") {
+		return "", fmt.Errorf("qrCode: unsupported media type %q", mediaType)
+	}
+	return template.URL("data:" + mediaType + ";base64," +
+		base64.StdEncoding.EncodeToString(image)), nil
+}
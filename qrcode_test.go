@@ -0,0 +1,75 @@
+package tmpls_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestQRCode(t *testing.T) {
+	t.Parallel()
+
+	fakeEncoder := func(mediaType string, err error) tmpls.QREncoder {
+		return tmpls.QREncoderFunc(func(value string, size int) ([]byte, string, error) {
+			return []byte(value), mediaType, err
+		})
+	}
+
+	tests := []struct {
+		name        string
+		config      tmpls.Config
+		src         string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should render a data uri",
+			config:   tmpls.Config{QRCode: fakeEncoder("image/png", nil)},
+			src:      `<img src="{{ qrCode "hi" 100 }}">`,
+			expected: `<img src="data:image/png;base64,aGk=">`,
+		},
+		{
+			name:        "should fail without an encoder",
+			src:         `{{ qrCode "hi" 100 }}`,
+			expectError: true,
+		},
+		{
+			name:        "should fail on encoder errors",
+			config:      tmpls.Config{QRCode: fakeEncoder("image/png", errors.New("too long"))},
+			src:         `{{ qrCode "hi" 100 }}`,
+			expectError: true,
+		},
+		{
+			name:        "should fail on non image media types",
+			config:      tmpls.Config{QRCode: fakeEncoder("text/html", nil)},
+			src:         `{{ qrCode "hi" 100 }}`,
+			expectError: true,
+		},
+		{
+			name:        "should fail on non positive sizes",
+			config:      tmpls.Config{QRCode: fakeEncoder("image/png", nil)},
+			src:         `{{ qrCode "hi" 0 }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, test.config, test.src, nil)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package tmpls
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+)
+
+// qs returns base with its query params replaced by name-value pairs, keeping
+// the others, for filter, sort and pagination links:
+//
+//	<a href="{{ qs .Request "page" 2 }}">
+//
+// Base may be an *http.Request, *url.URL, URL string or url.Values, the
+// latter giving just the "?" query. A nil value removes a param and a slice
+// sets one value per element.
+func qs(base any, pairs ...any) (string, error) {
+	var u *url.URL
+	switch v := base.(type) {
+	case *http.Request:
+		if v == nil {
+			return "", fmt.Errorf("qs: nil request")
+		}
+		u = v.URL
+	case *url.URL:
+		u = v
+	case string:
+		parsed, err := url.Parse(v)
+		if err != nil {
+			return "", fmt.Errorf("qs: %w", err)
+		}
+		u = parsed
+	case url.Values:
+		query, err := setQuery(v, pairs)
+		if err != nil {
+			return "", err
+		}
+		return "?" + query.Encode(), nil
+	default:
+		return "", fmt.Errorf("qs: unsupported type %T", base)
+	}
+	if u == nil {
+		return "", fmt.Errorf("qs: nil URL")
+	}
+	query, err := setQuery(u.Query(), pairs)
+	if err != nil {
+		return "", err
+	}
+	return withQuery(u, query), nil
+}
+
+// setQuery returns a copy of query with name-value pairs applied.
+func setQuery(query url.Values, pairs []any) (url.Values, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("qs requires name-value pairs but got %d arguments", len(pairs))
+	}
+	updated := make(url.Values, len(query))
+	for key, values := range query {
+		updated[key] = append([]string(nil), values...)
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		name, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("qs param names must be strings but got %T", pairs[i])
+		}
+		updated.Del(name)
+		value := reflect.ValueOf(pairs[i+1])
+		switch {
+		case !value.IsValid():
+		case value.Kind() == reflect.Slice && value.Type().Elem().Kind() != reflect.Uint8:
+			for j := range value.Len() {
+				updated.Add(name, fmt.Sprint(value.Index(j).Interface()))
+			}
+		default:
+			updated.Set(name, fmt.Sprint(pairs[i+1]))
+		}
+	}
+	return updated, nil
+}
+
+// withQuery returns u with its query replaced.
+func withQuery(u *url.URL, query url.Values) string {
+	updated := *u
+	updated.RawQuery = query.Encode()
+	return updated.String()
+}
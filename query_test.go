@@ -0,0 +1,83 @@
+package tmpls_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestQS(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should set params keeping others",
+			src:      `<a href="{{ qs . "page" 2 }}">`,
+			data:     httptest.NewRequest("GET", "/items?sort=name&page=1", nil),
+			expected: `<a href="/items?page=2&amp;sort=name">`,
+		},
+		{
+			name:     "should remove nil params",
+			src:      `<a href="{{ qs . "sort" nil "q" "a b" }}">`,
+			data:     httptest.NewRequest("GET", "/items?sort=name", nil),
+			expected: `<a href="/items?q=a&#43;b">`,
+		},
+		{
+			name:     "should set a value per slice element",
+			src:      `<a href="{{ qs . "tag" .Query.tag }}">`,
+			data:     &url.URL{Path: "/items", RawQuery: "tag=a&tag=b"},
+			expected: `<a href="/items?tag=a&amp;tag=b">`,
+		},
+		{
+			name:     "should keep absolute urls",
+			src:      `{{ qs . "page" 3 }}`,
+			data:     "https://example.com/items?page=2#list",
+			expected: `https://example.com/items?page=3#list`,
+		},
+		{
+			name:     "should build query from values",
+			src:      `<a href="{{ qs . "page" 2 }}">`,
+			data:     url.Values{"sort": {"name"}},
+			expected: `<a href="?page=2&amp;sort=name">`,
+		},
+		{
+			name:        "should fail for odd arguments",
+			src:         `{{ qs . "page" }}`,
+			data:        "/items",
+			expectError: true,
+		},
+		{
+			name:        "should fail for unsupported base",
+			src:         `{{ qs . "page" 2 }}`,
+			data:        1,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{}, test.src, test.data)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package tmpls
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Quotas bound the resources of each execution, for templates from untrusted
+// sources such as tenant customizations, failing executions that exceed them
+// with a *QuotaError. Set them for tenants' globs with Config.Strictness.
+// Zero fields are unlimited.
+type Quotas struct {
+	// MaxDuration limits the time spent executing. It is checked as output
+	// is written and templates are called, so it can't stop a loop that does
+	// neither.
+	MaxDuration time.Duration
+	// MaxOutputBytes limits the size of the output.
+	MaxOutputBytes int64
+	// MaxIncludes limits the templates called, see Resources.Includes.
+	MaxIncludes int64
+}
+
+// Resources are the resources an execution used, returned by
+// ExecuteMetered.
+type Resources struct {
+	// Duration is the wall-clock time spent executing, since Go doesn't
+	// measure CPU time per goroutine. Parsing and waiting for RenderLimit
+	// aren't included.
+	Duration time.Duration
+	// OutputBytes is the size of the output.
+	OutputBytes int64
+	// Includes counts executed template and block actions, and calls to
+	// funcs rendering templates such as component and slot. Template and
+	// block actions of engines from Config.Engines are only counted if they
+	// set CountIncludes, and those of Config.Compiled functions aren't.
+	// Engines chosen by default only count them once ExecuteMetered has
+	// been called, or for globs with Quotas.
+	Includes int64
+}
+
+// QuotaError reports which Quotas field an execution exceeded.
+type QuotaError struct {
+	Quota string
+	Value int64
+	Max   int64
+}
+
+func (e *QuotaError) Error() string {
+	if e.Quota == "MaxDuration" {
+		return fmt.Sprintf(
+			"execution exceeds %s: %s > %s",
+			e.Quota,
+			time.Duration(e.Value),
+			time.Duration(e.Max),
+		)
+	}
+	return fmt.Sprintf("execution exceeds %s: %d > %d", e.Quota, e.Value, e.Max)
+}
+
+// ExecuteMetered is ExecuteContext also returning the Resources the
+// execution used, whether or not its glob has Quotas, e.g. to bill tenants
+// for rendering. Until it is first called, only globs with Quotas count
+// includes, so the first call has cached globs parsed again to count them.
+func (t *Templates) ExecuteMetered(
+	ctx context.Context,
+	glob string,
+	templateName string,
+	data any,
+) (string, Resources, error) {
+	if !t.metering.Swap(true) {
+		executors := t.state.Load().executors
+		executors.Range(func(key, value any) bool {
+			cached := value.(*cachedExecutor)
+			executors.CompareAndSwap(key, cached, cached.with(true))
+			return true
+		})
+	}
+	buffer := t.buffers.Get().(*bytes.Buffer)
+	defer func() {
+		buffer.Reset()
+		t.buffers.Put(buffer)
+	}()
+	ctx = withExecution(ctx)
+	meter := &meter{}
+	executionFrom(ctx).meter = meter
+	if err := t.execute(ctx, buffer, glob, templateName, data); err != nil {
+		return "", meter.resources(), err
+	}
+	if t.config.TrackUsage {
+		t.recordUsage(glob, templateName)
+	}
+	return buffer.String(), meter.resources(), nil
+}
+
+// meter accounts for the resources of an execution, which funcs may use from
+// several goroutines.
+type meter struct {
+	quotas      Quotas
+	start       time.Time
+	duration    atomic.Int64
+	outputBytes atomic.Int64
+	includes    atomic.Int64
+}
+
+// metered returns w accounting for the execution carried by ctx if it is
+// metered, or glob has Quotas, starting its meter. Otherwise w is returned
+// as is.
+func (t *Templates) metered(ctx context.Context, glob string, w io.Writer) io.Writer {
+	e := executionFrom(ctx)
+	quotas := t.strictness(glob).Quotas
+	if e == nil || (e.meter == nil && quotas == nil) {
+		return w
+	}
+	if e.meter == nil {
+		e.meter = &meter{}
+	}
+	if quotas != nil {
+		e.meter.quotas = *quotas
+	}
+	e.meter.start = time.Now()
+	return meteredWriter{meter: e.meter, w: w}
+}
+
+// check fails once the execution takes longer than MaxDuration.
+func (m *meter) check() error {
+	elapsed := time.Since(m.start)
+	m.duration.Store(int64(elapsed))
+	if max := m.quotas.MaxDuration; max > 0 && elapsed > max {
+		return &QuotaError{Quota: "MaxDuration", Value: int64(elapsed), Max: int64(max)}
+	}
+	return nil
+}
+
+// include counts a template called, failing once there are more than
+// MaxIncludes.
+func (m *meter) include() error {
+	includes := m.includes.Add(1)
+	if max := m.quotas.MaxIncludes; max > 0 && includes > max {
+		return &QuotaError{Quota: "MaxIncludes", Value: includes, Max: max}
+	}
+	return m.check()
+}
+
+func (m *meter) resources() Resources {
+	if !m.start.IsZero() {
+		m.duration.Store(int64(time.Since(m.start)))
+	}
+	return Resources{
+		Duration:    time.Duration(m.duration.Load()),
+		OutputBytes: m.outputBytes.Load(),
+		Includes:    m.includes.Load(),
+	}
+}
+
+// meteredWriter counts the output written to w, failing writes that would
+// exceed MaxOutputBytes or come after MaxDuration.
+type meteredWriter struct {
+	meter *meter
+	w     io.Writer
+}
+
+func (w meteredWriter) Write(p []byte) (int, error) {
+	if err := w.meter.check(); err != nil {
+		return 0, err
+	}
+	written := w.meter.outputBytes.Load() + int64(len(p))
+	if max := w.meter.quotas.MaxOutputBytes; max > 0 && written > max {
+		return 0, &QuotaError{Quota: "MaxOutputBytes", Value: written, Max: max}
+	}
+	n, err := w.w.Write(p)
+	w.meter.outputBytes.Add(int64(n))
+	return n, err
+}
+
+// meterInclude counts a template action for the execution's meter. Engines
+// call it before each template and block action when counting includes.
+func meterInclude(ctx context.Context) any {
+	return func() (string, error) {
+		if e := executionFrom(ctx); e != nil && e.meter != nil {
+			return "", e.meter.include()
+		}
+		return "", nil
+	}
+}
+
+// countIncludes inserts a call to meterInclude before each template and block
+// action in src. The call is assigned to a variable, so it writes nothing in
+// any context.
+func countIncludes(file string, src string) (string, error) {
+	var out strings.Builder
+	for len(src) > 0 {
+		start := strings.Index(src, "{{")
+		if start < 0 {
+			out.WriteString(src)
+			break
+		}
+		out.WriteString(src[:start])
+		a, err := parseAction(src[start:])
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", file, err)
+		}
+		src = src[start+len(a.text):]
+		text := a.text
+		if a.keyword == "template" || a.keyword == "block" {
+			// the trim marker moves to the call, next to the whitespace
+			fmt.Fprintf(&out, "{{%s $_ := meterInclude }}", trimMarker(a.ltrim))
+			if a.ltrim {
+				text = "{{ " + strings.TrimPrefix(text, "{{- ")
+			}
+		}
+		out.WriteString(text)
+	}
+	return out.String(), nil
+}
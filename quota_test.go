@@ -0,0 +1,161 @@
+package tmpls_test
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestExecuteMetered(t *testing.T) {
+	t.Parallel()
+
+	const src = `{{ define "a" }}ab{{ end }}{{ template "a" }} {{- template "a" }}`
+
+	tests := []struct {
+		name            string
+		config          tmpls.Config
+		expected        string
+		expectResources tmpls.Resources
+		expectQuota     string
+	}{
+		{
+			name:            "should count without quotas",
+			expected:        "abab",
+			expectResources: tmpls.Resources{OutputBytes: 4, Includes: 2},
+		},
+		{
+			name:            "should count with empty quotas",
+			config:          tmpls.Config{Quotas: &tmpls.Quotas{}},
+			expected:        "abab",
+			expectResources: tmpls.Resources{OutputBytes: 4, Includes: 2},
+		},
+		{
+			name: "should apply quotas of matching strictness",
+			config: tmpls.Config{
+				Strictness: map[string]tmpls.Strictness{
+					"page.*": {Quotas: &tmpls.Quotas{MaxIncludes: 2}},
+				},
+			},
+			expected:        "abab",
+			expectResources: tmpls.Resources{OutputBytes: 4, Includes: 2},
+		},
+		{
+			name:        "should fail on too much output",
+			config:      tmpls.Config{Quotas: &tmpls.Quotas{MaxOutputBytes: 3}},
+			expectQuota: "MaxOutputBytes",
+		},
+		{
+			name:        "should fail on too many includes",
+			config:      tmpls.Config{Quotas: &tmpls.Quotas{MaxIncludes: 1}},
+			expectQuota: "MaxIncludes",
+		},
+		{
+			name:        "should fail on slow executions",
+			config:      tmpls.Config{Quotas: &tmpls.Quotas{MaxDuration: time.Nanosecond}},
+			expectQuota: "MaxDuration",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, test.config, src)
+			actual, resources, err := templates.ExecuteMetered(
+				context.Background(),
+				"page.html.tmpl",
+				"page.html.tmpl",
+				nil,
+			)
+			if test.expectQuota != "" {
+				var quotaErr *tmpls.QuotaError
+				if !tmpls.IsExecError(err) || !errors.As(err, &quotaErr) {
+					t.Fatalf("expected quota error but got %v", err)
+				}
+				if quotaErr.Quota != test.expectQuota {
+					t.Fatalf("expected %s but got %s", test.expectQuota, quotaErr.Quota)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, actual)
+			}
+			if resources.Duration <= 0 {
+				t.Fatalf("expected a duration but got %s", resources.Duration)
+			}
+			resources.Duration = 0
+			if resources != test.expectResources {
+				t.Fatalf("expected %+v but got %+v", test.expectResources, resources)
+			}
+		})
+	}
+}
+
+func TestQuotas(t *testing.T) {
+	t.Parallel()
+
+	_, err := render(
+		t,
+		tmpls.Config{Quotas: &tmpls.Quotas{MaxOutputBytes: 4}},
+		`{{ range . }}{{ . }}{{ end }}`,
+		[]string{"ab", "cd", "ef"},
+	)
+	var quotaErr *tmpls.QuotaError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("expected quota error but got %v", err)
+	}
+	if quotaErr.Value != 6 || quotaErr.Max != 4 {
+		t.Fatalf("expected 6 > 4 but got %d > %d", quotaErr.Value, quotaErr.Max)
+	}
+}
+
+func TestCountIncludes(t *testing.T) {
+	t.Parallel()
+
+	templates := newTemplates(t, tmpls.Config{}, `{{ define "a" }}a{{ end }}{{ template "a" }}`)
+	counts := func() bool {
+		t.Helper()
+		executor, err := templates.Parse("page.html.tmpl")
+		if err != nil {
+			t.Fatal(err)
+		}
+		set, err := executor.(interface {
+			Template() (*template.Template, error)
+		}).Template()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tmpl := range set.Templates() {
+			if tmpl.Tree != nil && strings.Contains(tmpl.Tree.Root.String(), "meterInclude") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if counts() {
+		t.Fatal("expected unmetered templates not to count includes")
+	}
+	_, resources, err := templates.ExecuteMetered(
+		context.Background(),
+		"page.html.tmpl",
+		"page.html.tmpl",
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resources.Includes != 1 {
+		t.Fatalf("expected 1 include but got %d", resources.Includes)
+	}
+	if !counts() {
+		t.Fatal("expected templates to count includes once metered")
+	}
+}
@@ -0,0 +1,165 @@
+package tmpls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// Reload parses every cached glob again into a fresh cache and swaps it in
+// once all have been parsed, so executions in flight keep using the old
+// templates while the new ones warm. As for files changed while watching, a
+// glob that fails to parse keeps serving its previous templates unless
+// StrictReload is set, while the others are still swapped in. The parse
+// errors are returned.
+func (t *Templates) Reload() error {
+	if t.config.DisableCache {
+		return nil
+	}
+	old := t.state.Load()
+	next, err := t.warm(old, old.fsys, true)
+	if !t.state.CompareAndSwap(old, next) {
+		t.log().Debug("Templates swapped during reload, discarding reloaded templates")
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	t.log().Info("Reloaded templates")
+	return nil
+}
+
+// Refresh calls Reload every Config.RefreshInterval in the background until
+// ctx is done. Globs that fail to parse are logged and, unless StrictReload
+// is set, keep their previous templates until the next attempt.
+func (t *Templates) Refresh(ctx context.Context) error {
+	if t.config.RefreshInterval <= 0 {
+		return fmt.Errorf("RefreshInterval is required")
+	}
+	go func() {
+		ticker := time.NewTicker(t.config.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// Reload logs its own errors
+				_ = t.Reload()
+			}
+		}
+	}()
+	return nil
+}
+
+// SwapFS atomically replaces the templates filesystem, e.g. with a newly
+// downloaded theme, dropping every cached executor. If warm is set, the globs
+// cached from the old filesystem are parsed from the new one before the swap,
+// and the old filesystem is kept if any fail. Watch moves to the new
+// filesystem.
+func (t *Templates) SwapFS(fsys fs.FS, warm bool) error {
+	if fsys == nil {
+		return fmt.Errorf("TemplatesFS is required")
+	}
+	next := &state{fsys: fsys, executors: &sync.Map{}}
+	if warm && !t.config.DisableCache {
+		var err error
+		next, err = t.warm(t.state.Load(), fsys, false)
+		if err != nil {
+			t.log().Error("Warming swapped templates failed, keeping previous ones", "error", err)
+			return err
+		}
+	}
+	t.state.Store(next)
+	t.swapMu.Lock()
+	if t.swapped != nil {
+		close(t.swapped)
+		t.swapped = nil
+	}
+	t.swapMu.Unlock()
+	t.log().Info("Swapped templates filesystem", "warmed", warm)
+	return nil
+}
+
+// swaps returns a channel closed by the next SwapFS.
+func (t *Templates) swaps() <-chan struct{} {
+	t.swapMu.Lock()
+	defer t.swapMu.Unlock()
+	if t.swapped == nil {
+		t.swapped = make(chan struct{})
+	}
+	return t.swapped
+}
+
+// Invalidate marks glob's cached templates stale, so they are parsed again
+// on their next use, e.g. after pushing a hotfix to TemplatesFS. As for
+// files changed while watching, a glob that no longer parses keeps serving
+// its previous templates unless StrictReload is set. Fragments cached by
+// cache blocks and cached responses are dropped too. It reports whether
+// glob was cached.
+func (t *Templates) Invalidate(glob string) bool {
+	state := t.state.Load()
+	state.fragments.Clear()
+	state.responses.Clear()
+	state.missing.Delete(glob)
+	value, ok := state.executors.Load(glob)
+	if !ok {
+		return false
+	}
+	executor := value.(*cachedExecutor)
+	if !executor.stale {
+		state.executors.CompareAndSwap(glob, executor, executor.with(true))
+	}
+	t.log().Info("Invalidated cached templates", "glob", glob)
+	return true
+}
+
+// ClearCache drops every cached glob, along with cached fragments,
+// responses, components and missing globs, so everything is parsed again
+// on its next use.
+func (t *Templates) ClearCache() {
+	for {
+		old := t.state.Load()
+		// the filesystem may be swapped meanwhile
+		if t.state.CompareAndSwap(old, &state{fsys: old.fsys, executors: &sync.Map{}}) {
+			break
+		}
+	}
+	t.log().Info("Cleared template cache")
+}
+
+// warm parses every glob cached in old from fsys into a new state. If
+// keep is set, a glob that fails to parse is logged and keeps its executor
+// from old, unless StrictReload is set or its files were removed.
+func (t *Templates) warm(old *state, fsys fs.FS, keep bool) (*state, error) {
+	var globs []string
+	old.executors.Range(func(key, _ any) bool {
+		globs = append(globs, key.(string))
+		return true
+	})
+	next := &state{fsys: fsys, executors: &sync.Map{}}
+	var errs []error
+	for _, glob := range globs {
+		tmpl, files, err := t.newExecutor(fsys, glob)
+		if err != nil {
+			errs = append(errs, err)
+			if !keep || t.config.StrictReload || errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if previous, ok := old.executors.Load(glob); ok {
+				t.log().Error(
+					"Reloading templates failed, serving previous templates",
+					"glob", glob,
+					"error", err,
+				)
+				t.storeExecutor(next, glob, previous.(*cachedExecutor))
+			}
+			continue
+		}
+		t.storeExecutor(next, glob, &cachedExecutor{executor: tmpl, files: files})
+	}
+	return next, errors.Join(errs...)
+}
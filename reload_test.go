@@ -0,0 +1,251 @@
+package tmpls_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestReload(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"common/empty.html.tmpl": &fstest.MapFile{},
+		"page.html.tmpl":         &fstest.MapFile{Data: []byte("before")},
+		"other.html.tmpl":        &fstest.MapFile{Data: []byte("other1")},
+	}
+	templates, err := tmpls.New(
+		tmpls.Config{TemplatesFS: fsys, CommonGlob: "common/*.html.tmpl"},
+		slog.New(slog.DiscardHandler),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	execute := func(glob string, expected string) {
+		t.Helper()
+		output, err := templates.Execute(glob, glob, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if output != expected {
+			t.Fatalf("expected %s but got %s", expected, output)
+		}
+	}
+
+	execute("page.html.tmpl", "before")
+	execute("other.html.tmpl", "other1")
+	fsys["page.html.tmpl"] = &fstest.MapFile{Data: []byte("after")}
+	execute("page.html.tmpl", "before")
+	if err := templates.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	execute("page.html.tmpl", "after")
+
+	// a glob that fails to parse doesn't hold back the others
+	fsys["page.html.tmpl"] = &fstest.MapFile{Data: []byte("{{ if }}")}
+	fsys["other.html.tmpl"] = &fstest.MapFile{Data: []byte("other2")}
+	if err := templates.Reload(); !tmpls.IsParseError(err) {
+		t.Fatalf("expected parse error but got %v", err)
+	}
+	execute("page.html.tmpl", "after")
+	execute("other.html.tmpl", "other2")
+}
+
+func TestInvalidate(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"common/empty.html.tmpl": &fstest.MapFile{},
+		"a.html.tmpl":            &fstest.MapFile{Data: []byte("a1")},
+		"b.html.tmpl":            &fstest.MapFile{Data: []byte("b1")},
+	}
+	templates, err := tmpls.New(
+		tmpls.Config{TemplatesFS: fsys, CommonGlob: "common/*.html.tmpl"},
+		slog.New(slog.DiscardHandler),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	execute := func(glob string, expected string) {
+		t.Helper()
+		output, err := templates.Execute(glob, glob, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if output != expected {
+			t.Fatalf("expected %s but got %s", expected, output)
+		}
+	}
+
+	execute("a.html.tmpl", "a1")
+	execute("b.html.tmpl", "b1")
+	fsys["a.html.tmpl"] = &fstest.MapFile{Data: []byte("a2")}
+	fsys["b.html.tmpl"] = &fstest.MapFile{Data: []byte("b2")}
+	if !templates.Invalidate("a.html.tmpl") {
+		t.Fatal("expected a.html.tmpl to be cached")
+	}
+	if templates.Invalidate("missing.html.tmpl") {
+		t.Fatal("expected missing.html.tmpl not to be cached")
+	}
+	execute("a.html.tmpl", "a2")
+	execute("b.html.tmpl", "b1")
+
+	// a hotfix that doesn't parse keeps the previous templates
+	fsys["a.html.tmpl"] = &fstest.MapFile{Data: []byte("{{ if }}")}
+	templates.Invalidate("a.html.tmpl")
+	execute("a.html.tmpl", "a2")
+
+	fsys["a.html.tmpl"] = &fstest.MapFile{Data: []byte("a3")}
+	templates.ClearCache()
+	execute("a.html.tmpl", "a3")
+	execute("b.html.tmpl", "b2")
+}
+
+func TestSwapFS(t *testing.T) {
+	t.Parallel()
+
+	theme := func(page string) fstest.MapFS {
+		return fstest.MapFS{
+			"common/empty.html.tmpl": &fstest.MapFile{},
+			"page.html.tmpl":         &fstest.MapFile{Data: []byte(page)},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		page        string
+		warm        bool
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should render from swapped filesystem",
+			page:     "new",
+			expected: "new",
+		},
+		{
+			name:     "should render from warmed filesystem",
+			page:     "new",
+			warm:     true,
+			expected: "new",
+		},
+		{
+			name:        "should keep old filesystem when warming fails",
+			page:        "{{ if }}",
+			warm:        true,
+			expected:    "old",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates, err := tmpls.New(
+				tmpls.Config{TemplatesFS: theme("old"), CommonGlob: "common/*.html.tmpl"},
+				slog.New(slog.DiscardHandler),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := templates.Execute("page.html.tmpl", "page.html.tmpl", nil); err != nil {
+				t.Fatal(err)
+			}
+
+			err = templates.SwapFS(theme(test.page), test.warm)
+			if test.expectError != (err != nil) {
+				t.Fatalf("expected error %t but got %v", test.expectError, err)
+			}
+			output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name string, contents string) {
+		t.Helper()
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		// written then renamed so refreshes never read a partial file
+		if err := os.WriteFile(file+".tmp", []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(file+".tmp", file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("common/empty.html.tmpl", "")
+	write("page.html.tmpl", "before")
+
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS:     tmpls.DirFS(dir),
+			CommonGlob:      "common/*.html.tmpl",
+			RefreshInterval: 10 * time.Millisecond,
+		},
+		slog.New(slog.DiscardHandler),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	execute := func() string {
+		t.Helper()
+		output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return output
+	}
+	if output := execute(); output != "before" {
+		t.Fatalf("expected before but got %s", output)
+	}
+	if err := templates.Refresh(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	write("page.html.tmpl", "after")
+	deadline := time.Now().Add(5 * time.Second)
+	for execute() != "after" {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for refresh")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	write("page.html.tmpl", "{{ if }}")
+	time.Sleep(50 * time.Millisecond)
+	if output := execute(); output != "after" {
+		t.Fatalf("expected after but got %s", output)
+	}
+}
+
+func TestRefreshRequiresInterval(t *testing.T) {
+	t.Parallel()
+
+	templates, err := tmpls.New(
+		tmpls.Config{TemplatesFS: fstest.MapFS{}},
+		slog.New(slog.DiscardHandler),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := templates.Refresh(t.Context()); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+}
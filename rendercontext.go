@@ -0,0 +1,175 @@
+package tmpls
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RenderContext is request-scoped state that funcs read during an
+// ExecuteContext call, so it doesn't have to be added to every page's data.
+// A nil RenderContext is valid and reads as empty.
+type RenderContext struct {
+	// Request is the request being rendered for, read by funcs such as
+	// csrfField.
+	Request *http.Request
+	Locale  string
+	Nonce   string
+	User    any
+	Flags   map[string]bool
+	values  map[string]any
+}
+
+type renderContextKey struct{}
+
+// WithRenderContext returns a copy of ctx carrying rc.
+func WithRenderContext(ctx context.Context, rc *RenderContext) context.Context {
+	return context.WithValue(ctx, renderContextKey{}, rc)
+}
+
+// RenderContextFrom returns the RenderContext carried by ctx, or nil.
+func RenderContextFrom(ctx context.Context) *RenderContext {
+	rc, _ := ctx.Value(renderContextKey{}).(*RenderContext)
+	return rc
+}
+
+// Flag reports whether the named flag is set.
+func (rc *RenderContext) Flag(name string) bool {
+	if rc == nil {
+		return false
+	}
+	return rc.Flags[name]
+}
+
+// Value returns the custom value stored under name, for templates. Go code
+// should use a Key instead.
+func (rc *RenderContext) Value(name string) any {
+	if rc == nil {
+		return nil
+	}
+	return rc.values[name]
+}
+
+// Key is a typed name for a custom RenderContext value.
+type Key[T any] struct {
+	name string
+}
+
+// NewKey returns a key for values of type T, readable from templates with
+// {{ (renderContext).Value name }}.
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// Set stores value in rc.
+func (k Key[T]) Set(rc *RenderContext, value T) {
+	if rc.values == nil {
+		rc.values = map[string]any{}
+	}
+	rc.values[k.name] = value
+}
+
+// Get returns the value stored in rc, if any.
+func (k Key[T]) Get(rc *RenderContext) (T, bool) {
+	value, ok := rc.Value(k.name).(T)
+	return value, ok
+}
+
+type executionKey struct{}
+
+// execution holds state shared by the funcs of a single execution.
+type execution struct {
+	flashOnce sync.Once
+	flashes   []Flash
+	flashErr  error
+	userOnce  sync.Once
+	user      any
+	userErr   error
+	slots     []slot
+	once      map[string]bool
+	// parsed is set, with how long parsing took, if the execution's glob
+	// wasn't cached.
+	parsed    bool
+	parseTime time.Duration
+	// async is set for executions by StreamRequest.
+	async    *asyncBlocks
+	resolved map[Provider]resolution
+	// meter is set for executions by ExecuteMetered or of globs with Quotas.
+	meter *meter
+	// requestBound is set once the output depends on the request, such as
+	// its nonce, so it mustn't be served again as a snapshot.
+	requestBound bool
+}
+
+// bindToRequest marks the execution carried by ctx as depending on the
+// request.
+func bindToRequest(ctx context.Context) {
+	if e := executionFrom(ctx); e != nil {
+		e.requestBound = true
+	}
+}
+
+func withExecution(ctx context.Context) context.Context {
+	return context.WithValue(ctx, executionKey{}, &execution{})
+}
+
+// executionFrom returns the execution carried by ctx, or nil for funcs bound
+// outside of one.
+func executionFrom(ctx context.Context) *execution {
+	e, _ := ctx.Value(executionKey{}).(*execution)
+	return e
+}
+
+// contextFuncs are the built-in funcs reading the execution's context.
+func (t *Templates) contextFuncs() map[string]ContextFunc {
+	return map[string]ContextFunc{
+		"meterInclude": meterInclude,
+		"renderContext": func(ctx context.Context) any {
+			return func() *RenderContext {
+				return RenderContextFrom(ctx)
+			}
+		},
+		"locale": func(ctx context.Context) any {
+			return func() string {
+				if rc := RenderContextFrom(ctx); rc != nil {
+					return rc.Locale
+				}
+				return ""
+			}
+		},
+		"nonce": func(ctx context.Context) any {
+			return func() string {
+				bindToRequest(ctx)
+				if rc := RenderContextFrom(ctx); rc != nil {
+					return rc.Nonce
+				}
+				return ""
+			}
+		},
+		"flag": func(ctx context.Context) any {
+			return func(name string) bool {
+				return RenderContextFrom(ctx).Flag(name)
+			}
+		},
+		"flash":        t.flash,
+		"currentUser":  t.currentUser,
+		"loggedIn":     t.loggedIn,
+		"csrfToken":    t.csrfToken,
+		"csrfField":    t.csrfField,
+		"active":       t.active(false),
+		"activePrefix": t.active(true),
+		"wrap":         wrap,
+		"slot":         slotContent,
+		"component":    t.component,
+		"cache":        t.cache,
+		"once":         once,
+		"async":        async,
+		"within":       t.within,
+		"resolve":      resolve,
+		"embed":        embed,
+		"slugify":      slugify,
+		"initials":     initials,
+		"backURL":      t.backURL,
+	}
+}
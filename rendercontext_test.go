@@ -0,0 +1,136 @@
+package tmpls_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+var themeKey = tmpls.NewKey[string]("theme")
+
+func TestExecuteContext(t *testing.T) {
+	t.Parallel()
+
+	rc := &tmpls.RenderContext{
+		Locale: "de-DE",
+		Nonce:  `abc"def`,
+		User:   struct{ Name string }{Name: "Tom"},
+		Flags:  map[string]bool{"beta": true},
+	}
+	themeKey.Set(rc, "dark")
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		src      string
+		expected string
+	}{
+		{
+			name:     "should read locale",
+			ctx:      tmpls.WithRenderContext(context.Background(), rc),
+			src:      `<html lang="{{ locale }}">`,
+			expected: `<html lang="de-DE">`,
+		},
+		{
+			name:     "should escape nonce",
+			ctx:      tmpls.WithRenderContext(context.Background(), rc),
+			src:      `<script nonce="{{ nonce }}"></script>`,
+			expected: `<script nonce="abc&#34;def"></script>`,
+		},
+		{
+			name:     "should read flags",
+			ctx:      tmpls.WithRenderContext(context.Background(), rc),
+			src:      `{{ if flag "beta" }}beta{{ end }}{{ if flag "alpha" }}alpha{{ end }}`,
+			expected: "beta",
+		},
+		{
+			name:     "should read user",
+			ctx:      tmpls.WithRenderContext(context.Background(), rc),
+			src:      `{{ currentUser.Name }}`,
+			expected: "Tom",
+		},
+		{
+			name:     "should read custom values",
+			ctx:      tmpls.WithRenderContext(context.Background(), rc),
+			src:      `{{ (renderContext).Value "theme" }}`,
+			expected: "dark",
+		},
+		{
+			name:     "should read empty values without render context",
+			ctx:      context.Background(),
+			src:      `[{{ locale }}{{ nonce }}{{ flag "beta" }}{{ loggedIn }}]`,
+			expected: "[falsefalse]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, tmpls.Config{}, test.src)
+			output, err := templates.ExecuteContext(
+				test.ctx,
+				"page.html.tmpl",
+				"page.html.tmpl",
+				nil,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestExecuteContextConcurrent(t *testing.T) {
+	t.Parallel()
+
+	templates := newTemplates(t, tmpls.Config{}, `{{ locale }}`)
+	var wg sync.WaitGroup
+	for _, locale := range []string{"en", "de", "fr", "es", "it", "nl", "pt", "pl"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				ctx := tmpls.WithRenderContext(
+					context.Background(),
+					&tmpls.RenderContext{Locale: locale},
+				)
+				output, err := templates.ExecuteContext(
+					ctx,
+					"page.html.tmpl",
+					"page.html.tmpl",
+					nil,
+				)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if output != locale {
+					t.Errorf("expected %s but got %s", locale, output)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	rc := &tmpls.RenderContext{}
+	if _, ok := themeKey.Get(rc); ok {
+		t.Fatal("expected no value before set")
+	}
+	themeKey.Set(rc, "light")
+	if theme, ok := themeKey.Get(rc); !ok || theme != "light" {
+		t.Fatalf("expected light but got %s", theme)
+	}
+	if _, ok := themeKey.Get(nil); ok {
+		t.Fatal("expected no value from nil render context")
+	}
+}
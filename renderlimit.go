@@ -0,0 +1,70 @@
+package tmpls
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrQueueFull is returned when an execution would exceed RenderLimit's
+// MaxQueued, e.g. to answer with 503 Service Unavailable.
+var ErrQueueFull = errors.New("tmpls: render queue full")
+
+// RenderLimit bounds simultaneous executions, so a burst of heavy renders
+// queues and then fails fast instead of growing the buffer pool until memory
+// runs out.
+type RenderLimit struct {
+	// MaxConcurrent limits executions running at once. Zero is unlimited.
+	MaxConcurrent int
+	// MaxQueued limits executions waiting for one to finish, beyond which
+	// they fail with ErrQueueFull. Zero fails without waiting.
+	MaxQueued int
+}
+
+// renderLimiter enforces a RenderLimit.
+type renderLimiter struct {
+	slots     chan struct{}
+	maxQueued int64
+	queued    atomic.Int64
+}
+
+func newRenderLimiter(limit *RenderLimit) *renderLimiter {
+	if limit == nil || limit.MaxConcurrent <= 0 {
+		return nil
+	}
+	return &renderLimiter{
+		slots:     make(chan struct{}, limit.MaxConcurrent),
+		maxQueued: int64(limit.MaxQueued),
+	}
+}
+
+// acquire waits for a free slot, failing with ErrQueueFull if too many are
+// already waiting or with ctx's error if it is done first. A nil limiter
+// never waits.
+func (l *renderLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	if l.queued.Add(1) > l.maxQueued {
+		l.queued.Add(-1)
+		return ErrQueueFull
+	}
+	defer l.queued.Add(-1)
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *renderLimiter) release() {
+	if l != nil {
+		<-l.slots
+	}
+}
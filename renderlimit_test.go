@@ -0,0 +1,119 @@
+package tmpls_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+// blockingFaults is a tmpls.FaultInjector holding executions until released.
+type blockingFaults struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (f *blockingFaults) BeforeParse(string) error {
+	return nil
+}
+
+func (f *blockingFaults) BeforeExecute(context.Context, string, string) error {
+	f.started <- struct{}{}
+	<-f.release
+	return nil
+}
+
+func newLimitedTemplates(
+	t *testing.T,
+	limit *tmpls.RenderLimit,
+) (*tmpls.Templates, *blockingFaults) {
+	t.Helper()
+	faults := &blockingFaults{started: make(chan struct{}, 10), release: make(chan struct{})}
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: fstest.MapFS{
+				"common/empty.html.tmpl": &fstest.MapFile{},
+				"page.html.tmpl":         &fstest.MapFile{Data: []byte("page")},
+			},
+			CommonGlob:  "common/*.html.tmpl",
+			Faults:      faults,
+			RenderLimit: limit,
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return templates, faults
+}
+
+func TestRenderLimit(t *testing.T) {
+	t.Parallel()
+
+	templates, faults := newLimitedTemplates(t, &tmpls.RenderLimit{MaxConcurrent: 1, MaxQueued: 1})
+	errs := make(chan error, 3)
+	execute := func() {
+		_, err := templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+		errs <- err
+	}
+	go execute()
+	<-faults.started
+	// one of these waits and the other finds the queue full
+	go execute()
+	go execute()
+	if err := <-errs; !errors.Is(err, tmpls.ErrQueueFull) {
+		t.Fatalf("expected %v but got %v", tmpls.ErrQueueFull, err)
+	}
+	close(faults.release)
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRenderLimitWithoutQueue(t *testing.T) {
+	t.Parallel()
+
+	templates, faults := newLimitedTemplates(t, &tmpls.RenderLimit{MaxConcurrent: 1})
+	errs := make(chan error, 1)
+	go func() {
+		_, err := templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+		errs <- err
+	}()
+	<-faults.started
+	_, err := templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+	if !errors.Is(err, tmpls.ErrQueueFull) {
+		t.Fatalf("expected %v but got %v", tmpls.ErrQueueFull, err)
+	}
+	close(faults.release)
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRenderLimitContextDone(t *testing.T) {
+	t.Parallel()
+
+	templates, faults := newLimitedTemplates(t, &tmpls.RenderLimit{MaxConcurrent: 1, MaxQueued: 1})
+	errs := make(chan error, 1)
+	go func() {
+		_, err := templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+		errs <- err
+	}()
+	<-faults.started
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	_, err := templates.ExecuteContext(ctx, "page.html.tmpl", "page.html.tmpl", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v but got %v", context.DeadlineExceeded, err)
+	}
+	close(faults.release)
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,54 @@
+package tmpls
+
+import (
+	"context"
+	"reflect"
+)
+
+// Provider is data loaded only if a template uses it, with the resolve func:
+//
+//	{{ with resolve .Related }}{{ range . }}...{{ end }}{{ end }}
+//
+// so pages don't load data for sections their template never renders.
+type Provider interface {
+	Resolve(ctx context.Context) (any, error)
+}
+
+// ProviderFunc adapts an ordinary function to a Provider.
+type ProviderFunc func(ctx context.Context) (any, error)
+
+func (f ProviderFunc) Resolve(ctx context.Context) (any, error) {
+	return f(ctx)
+}
+
+// resolution is the memoized result of a Provider.
+type resolution struct {
+	value any
+	err   error
+}
+
+// resolve returns the value of a Provider, resolving it with the execution's
+// context the first time it is used in the execution. Other values are
+// returned as they are. Providers of types that aren't comparable, such as
+// ProviderFunc, can't be told apart and are resolved on every use.
+func resolve(ctx context.Context) any {
+	return func(value any) (any, error) {
+		provider, ok := value.(Provider)
+		if !ok {
+			return value, nil
+		}
+		execution := executionFrom(ctx)
+		if execution == nil || !reflect.TypeOf(provider).Comparable() {
+			return provider.Resolve(ctx)
+		}
+		if r, ok := execution.resolved[provider]; ok {
+			return r.value, r.err
+		}
+		value, err := provider.Resolve(ctx)
+		if execution.resolved == nil {
+			execution.resolved = map[Provider]resolution{}
+		}
+		execution.resolved[provider] = resolution{value: value, err: err}
+		return value, err
+	}
+}
@@ -0,0 +1,188 @@
+package tmpls_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+// countingProvider is a tmpls.Provider counting its resolutions.
+type countingProvider struct {
+	value any
+	err   error
+	calls atomic.Int64
+}
+
+func (p *countingProvider) Resolve(context.Context) (any, error) {
+	p.calls.Add(1)
+	return p.value, p.err
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		src           string
+		provider      *countingProvider
+		expected      string
+		expectedCalls int64
+		expectError   bool
+	}{
+		{
+			name:          "should resolve once per execution",
+			src:           `{{ range resolve .P }}{{ . }}{{ end }} {{ len (resolve .P) }}`,
+			provider:      &countingProvider{value: []string{"a", "b"}},
+			expected:      "ab 2",
+			expectedCalls: 1,
+		},
+		{
+			name:     "should not resolve unused providers",
+			src:      `{{ if false }}{{ resolve .P }}{{ end }}x`,
+			provider: &countingProvider{value: "a"},
+			expected: "x",
+		},
+		{
+			name:          "should fail for provider errors",
+			src:           `{{ resolve .P }}`,
+			provider:      &countingProvider{err: errors.New("down")},
+			expectedCalls: 1,
+			expectError:   true,
+		},
+		{
+			name:     "should return other values",
+			src:      `{{ resolve .Name }}`,
+			provider: &countingProvider{},
+			expected: "page",
+		},
+		{
+			name:     "should resolve provider funcs",
+			src:      `{{ resolve .Func }}`,
+			provider: &countingProvider{},
+			expected: "func",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, tmpls.Config{}, test.src)
+			data := map[string]any{
+				"Name": "page",
+				"P":    test.provider,
+				"Func": tmpls.ProviderFunc(func(context.Context) (any, error) {
+					return "func", nil
+				}),
+			}
+			output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", data)
+			if calls := test.provider.calls.Load(); calls != test.expectedCalls {
+				t.Fatalf("expected %d calls but got %d", test.expectedCalls, calls)
+			}
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+// barrierProvider is a tmpls.Provider that only resolves once every provider
+// sharing its barrier is resolving at the same time.
+type barrierProvider struct {
+	value   string
+	barrier *sync.WaitGroup
+	timeout time.Duration
+}
+
+func (p *barrierProvider) Resolve(context.Context) (any, error) {
+	p.barrier.Done()
+	done := make(chan struct{})
+	go func() {
+		p.barrier.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return p.value, nil
+	case <-time.After(p.timeout):
+		return nil, errors.New("resolved alone")
+	}
+}
+
+func TestResolveConcurrency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		concurrency int
+		timeout     time.Duration
+		expected    string
+		expectError bool
+	}{
+		{
+			name:        "should resolve providers concurrently",
+			src:         `{{ range .Items }}{{ resolve $.B }}{{ end }}{{ resolve .A }}`,
+			concurrency: 2,
+			timeout:     5 * time.Second,
+			expected:    "bba",
+		},
+		{
+			name:        "should resolve providers one at a time",
+			src:         `{{ resolve .B }}{{ resolve .A }}`,
+			concurrency: 1,
+			timeout:     20 * time.Millisecond,
+			expectError: true,
+		},
+		{
+			name:        "should resolve providers lazily by default",
+			src:         `{{ resolve .B }}{{ resolve .A }}`,
+			timeout:     20 * time.Millisecond,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(
+				t,
+				tmpls.Config{ResolveConcurrency: test.concurrency},
+				test.src,
+			)
+			var barrier sync.WaitGroup
+			barrier.Add(2)
+			data := map[string]any{
+				"Items": []int{1, 2},
+				"A":     &barrierProvider{value: "a", barrier: &barrier, timeout: test.timeout},
+				"B":     &barrierProvider{value: "b", barrier: &barrier, timeout: test.timeout},
+			}
+			output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", data)
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package tmpls
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ResponseCache caches the output of RenderRequest by glob, template and the
+// key Key returns, so pages rendered again for the same key aren't executed
+// again, and answers requests whose If-None-Match header holds the output's
+// ETag with 304 Not Modified. Only pages not depending on request state such
+// as the user or a CSRF token should be rendered with it. Nothing is cached
+// while Config.CSP holds NoncePlaceholder, since a cached page's nonce would
+// be replayed to every client.
+type ResponseCache struct {
+	// TTL is how long output is cached. Zero caches it until templates
+	// change.
+	TTL time.Duration
+	// Key returns the key output for r and data is cached under, which must
+	// identify everything the output depends on, and false to skip the
+	// cache. Nothing is cached without it.
+	Key func(r *http.Request, data any) (string, bool)
+}
+
+// cachedResponse is the output of a RenderRequest call.
+type cachedResponse struct {
+	output  []byte
+	etag    string
+	expires time.Time
+}
+
+// responseKey returns the ResponseCache key of a RenderRequest call, or ""
+// if it can't be cached.
+func (t *Templates) responseKey(
+	r *http.Request,
+	status int,
+	glob string,
+	template string,
+	data any,
+) string {
+	cache := t.config.ResponseCache
+	if cache == nil || cache.Key == nil || status != http.StatusOK ||
+		strings.Contains(t.config.CSP, NoncePlaceholder) {
+		return ""
+	}
+	key, ok := cache.Key(r, data)
+	if !ok {
+		return ""
+	}
+	return glob + "\x00" + template + "\x00" + key
+}
+
+func (t *Templates) cachedResponse(key string) (*cachedResponse, bool) {
+	if key == "" {
+		return nil, false
+	}
+	value, ok := t.state.Load().responses.Load(key)
+	if !ok {
+		return nil, false
+	}
+	cached := value.(*cachedResponse)
+	if !cached.expires.IsZero() && !t.clock.Now().Before(cached.expires) {
+		return nil, false
+	}
+	return cached, true
+}
+
+func (t *Templates) cacheResponse(key string, output []byte) *cachedResponse {
+	sum := sha256.Sum256(output)
+	cached := &cachedResponse{
+		output: output,
+		etag:   `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}
+	if ttl := t.config.ResponseCache.TTL; ttl > 0 {
+		cached.expires = t.clock.Now().Add(ttl)
+	}
+	t.state.Load().responses.Store(key, cached)
+	return cached
+}
+
+// etagMatches reports whether the If-None-Match header of r lists etag,
+// comparing weakly as RFC 9110 requires.
+func etagMatches(r *http.Request, etag string) bool {
+	for _, value := range r.Header.Values("If-None-Match") {
+		for candidate := range strings.SplitSeq(value, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
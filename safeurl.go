@@ -0,0 +1,63 @@
+package tmpls
+
+import (
+	"html/template"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// UnsafeURL replaces URLs rejected by the safeURL and safeMailto funcs. It is
+// the placeholder html/template uses for the same purpose.
+const UnsafeURL = "#ZgotmplZ"
+
+var defaultURLSchemes = []string{"http", "https", "mailto", "tel"}
+
+// safeURL returns u for an href or src attribute if it is relative or its
+// scheme is in Config.URLSchemes, or else logs a warning and returns
+// UnsafeURL, so javascript: and data: URLs from user data never reach the
+// page.
+func (t *Templates) safeURL(u string) template.URL {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return t.unsafeURL("unparsable", "")
+	}
+	if parsed.Scheme == "" {
+		return template.URL(u)
+	}
+	schemes := t.config.URLSchemes
+	if schemes == nil {
+		schemes = defaultURLSchemes
+	}
+	if !slices.Contains(schemes, strings.ToLower(parsed.Scheme)) {
+		return t.unsafeURL("scheme not allowed", parsed.Scheme)
+	}
+	return template.URL(u)
+}
+
+// safeMailto returns a mailto: URL for address, which may already have the
+// scheme and a query such as ?subject=, or UnsafeURL if it isn't a valid
+// email address.
+func (t *Templates) safeMailto(address string) template.URL {
+	if len(address) >= 7 && strings.EqualFold(address[:7], "mailto:") {
+		address = address[7:]
+	}
+	addr, query, _ := strings.Cut(address, "?")
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil || parsed.Name != "" {
+		return t.unsafeURL("invalid email address", "")
+	}
+	u := "mailto:" + addr
+	if query != "" {
+		u += "?" + query
+	}
+	return template.URL(u)
+}
+
+// unsafeURL logs why a URL was rejected without the URL itself, which may
+// hold user data.
+func (t *Templates) unsafeURL(reason string, detail string) template.URL {
+	t.log().Warn("Replaced unsafe URL", "reason", reason, "detail", detail)
+	return UnsafeURL
+}
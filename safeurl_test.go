@@ -0,0 +1,123 @@
+package tmpls_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestSafeURLs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		src       string
+		url       string
+		schemes   []string
+		expected  string
+		expectLog bool
+	}{
+		{
+			name:     "should allow relative urls",
+			src:      `<a href="{{ safeURL .URL }}">`,
+			url:      "/a?b=1&c=2",
+			expected: `<a href="/a?b=1&amp;c=2">`,
+		},
+		{
+			name:     "should allow default schemes",
+			src:      `<a href="{{ safeURL .URL }}">`,
+			url:      "tel:+15550100",
+			expected: `<a href="tel:&#43;15550100">`,
+		},
+		{
+			name:      "should replace javascript urls",
+			src:       `<a href="{{ safeURL .URL }}">`,
+			url:       "JavaScript:alert(1)",
+			expected:  `<a href="#ZgotmplZ">`,
+			expectLog: true,
+		},
+		{
+			name:      "should replace data urls",
+			src:       `<img src="{{ safeURL .URL }}">`,
+			url:       "data:text/html;base64,PHNjcmlwdD4=",
+			expected:  `<img src="#ZgotmplZ">`,
+			expectLog: true,
+		},
+		{
+			name:      "should replace unparsable urls",
+			src:       `<a href="{{ safeURL .URL }}">`,
+			url:       " javascript:alert(1)",
+			expected:  `<a href="#ZgotmplZ">`,
+			expectLog: true,
+		},
+		{
+			name:     "should allow configured schemes",
+			src:      `<a href="{{ safeURL .URL }}">`,
+			url:      "data:image/png;base64,AAAA",
+			schemes:  []string{"data"},
+			expected: `<a href="data:image/png;base64,AAAA">`,
+		},
+		{
+			name:     "should build mailto urls",
+			src:      `<a href="{{ safeMailto .URL }}">`,
+			url:      "a@example.com?subject=Hi there",
+			expected: `<a href="mailto:a@example.com?subject=Hi%20there">`,
+		},
+		{
+			name:     "should keep mailto scheme",
+			src:      `<a href="{{ safeMailto .URL }}">`,
+			url:      "MAILTO:a@example.com",
+			expected: `<a href="mailto:a@example.com">`,
+		},
+		{
+			name:      "should replace invalid addresses",
+			src:       `<a href="{{ safeMailto .URL }}">`,
+			url:       "javascript:alert(1)//@example.com",
+			expected:  `<a href="#ZgotmplZ">`,
+			expectLog: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var logs bytes.Buffer
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: fstest.MapFS{
+						"common/empty.html.tmpl": &fstest.MapFile{},
+						"page.html.tmpl":         &fstest.MapFile{Data: []byte(test.src)},
+					},
+					CommonGlob: "common/*.html.tmpl",
+					URLSchemes: test.schemes,
+				},
+				slog.New(slog.NewTextHandler(&logs, nil)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			output, err := templates.Execute(
+				"page.html.tmpl",
+				"page.html.tmpl",
+				map[string]string{"URL": test.url},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+			logged := strings.Contains(logs.String(), "Replaced unsafe URL")
+			if logged != test.expectLog {
+				t.Fatalf("expected logged=%v but got %s", test.expectLog, logs.String())
+			}
+			if strings.Contains(logs.String(), "alert") {
+				t.Fatalf("expected url to be left out of logs but got %s", logs.String())
+			}
+		})
+	}
+}
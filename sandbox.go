@@ -0,0 +1,215 @@
+package tmpls
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"text/template/parse"
+)
+
+// SandboxFuncs are the funcs sandboxed templates may call by default: pure
+// formatting and collection helpers that neither read request state, the
+// environment or other templates, nor do expensive work.
+var SandboxFuncs = []string{
+	"add", "batch", "chunk", "classes", "coalesce", "contains", "date", "default",
+	"dict", "div", "emoji", "first", "groupBy", "humanBytes", "humanDuration",
+	"indexOf", "initials", "last", "list", "mod", "mul", "percent", "slugify",
+	"sortBy", "sub", "ternary", "timeAgo", "truncate", "truncateHTML", "when",
+}
+
+// textFuncs are text/template's own funcs, available in every sandbox.
+var textFuncs = []string{
+	"and", "call", "eq", "ge", "gt", "html", "index", "js", "le", "len", "lt",
+	"ne", "not", "or", "print", "printf", "println", "slice", "urlquery",
+}
+
+// Sandbox restricts templates from untrusted sources, such as customizations
+// tenants edit, to a vetted set of funcs and to templates defined in their
+// own directory tree or shared files, so one tenant's templates can't reach
+// another's partials. It is set with Config.Strictness, and applies to the
+// files a glob matches, not to shared ones, and to every file whose own path
+// matches the pattern, whichever glob parses it:
+//
+//	Strictness: map[string]tmpls.Strictness{
+//		"tenants/*/*": {Sandbox: &tmpls.Sandbox{}},
+//	}
+//
+// A file several sandboxes apply to may only call the funcs all of them
+// allow. Globs breaking a sandbox fail to parse with a *SandboxError.
+type Sandbox struct {
+	// Funcs are the funcs sandboxed templates may call besides
+	// text/template's own, such as len and printf. Defaults to SandboxFuncs.
+	Funcs []string
+}
+
+// SandboxError reports a sandboxed file calling a func outside its sandbox,
+// or a template defined outside it.
+type SandboxError struct {
+	File     string
+	Func     string
+	Template string
+	// DefinedIn is the file defining Template.
+	DefinedIn string
+}
+
+func (e *SandboxError) Error() string {
+	if e.Func != "" {
+		return fmt.Sprintf("%s: func %q isn't allowed in the sandbox", e.File, e.Func)
+	}
+	return fmt.Sprintf(
+		"%s: template %q from %s is outside the sandbox",
+		e.File,
+		e.Template,
+		e.DefinedIn,
+	)
+}
+
+// funcs returns the funcs s allows.
+func (s Sandbox) funcs() []string {
+	if s.Funcs == nil {
+		return SandboxFuncs
+	}
+	return s.Funcs
+}
+
+// sandboxed returns the funcs file, parsed into glob, may call if a Sandbox
+// applies to it: glob's own unless file is shared, and that of each
+// Config.Strictness pattern matching file itself.
+func (t *Templates) sandboxed(glob string, file string) ([]string, bool) {
+	var sandboxes []*Sandbox
+	if s := t.strictness(glob).Sandbox; s != nil && !t.shared(file) {
+		sandboxes = append(sandboxes, s)
+	}
+	for pattern, s := range t.config.Strictness {
+		if matched, _ := path.Match(pattern, file); matched && s.Sandbox != nil {
+			sandboxes = append(sandboxes, s.Sandbox)
+		}
+	}
+	if len(sandboxes) == 0 {
+		return nil, false
+	}
+	allowed := slices.Clone(sandboxes[0].funcs())
+	for _, s := range sandboxes[1:] {
+		allowed = slices.DeleteFunc(allowed, func(fn string) bool {
+			return !slices.Contains(s.funcs(), fn)
+		})
+	}
+	return append(allowed, textFuncs...), true
+}
+
+// confine returns an error for the first of files, the files parsed into
+// glob in override order, breaking a Sandbox, or calling a template defined
+// outside its directory tree and the shared files when not shared and
+// ConfineIncludes is set.
+func (t *Templates) confine(fsys fs.FS, glob string, files []string) error {
+	type rule struct {
+		confined bool
+		// funcs are the funcs allowed, or nil for any
+		funcs []string
+	}
+	confineIncludes := t.strictness(glob).ConfineIncludes
+	rules := make([]rule, len(files))
+	confined := false
+	for i, file := range files {
+		if funcs, ok := t.sandboxed(glob, file); ok {
+			rules[i] = rule{confined: true, funcs: funcs}
+		} else {
+			rules[i] = rule{confined: confineIncludes && !t.shared(file)}
+		}
+		confined = confined || rules[i].confined
+	}
+	if !confined {
+		return nil
+	}
+	parsed := make([]map[string]*parse.Tree, len(files))
+	definers := map[string]string{}
+	for i, file := range files {
+		trees, err := parseTrees(fsys, file)
+		if err != nil {
+			return err
+		}
+		parsed[i] = trees
+		for name := range trees {
+			definers[name] = file
+		}
+	}
+	for i, file := range files {
+		if !rules[i].confined {
+			continue
+		}
+		names := make([]string, 0, len(parsed[i]))
+		for name := range parsed[i] {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			root := parsed[i][name].Root
+			if funcs := rules[i].funcs; funcs != nil {
+				for _, fn := range calledFuncs(root, nil) {
+					if !slices.Contains(funcs, fn) {
+						return &SandboxError{File: file, Func: fn}
+					}
+				}
+			}
+			for _, ref := range templateReferences(root, nil) {
+				definer, ok := definers[ref]
+				if ok && !t.shared(definer) && !within(path.Dir(file), definer) {
+					return &SandboxError{File: file, Template: ref, DefinedIn: definer}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// within reports whether file is in dir or one of its subdirectories.
+func within(dir string, file string) bool {
+	return dir == "." || strings.HasPrefix(file, dir+"/")
+}
+
+// calledFuncs appends the names of the funcs node calls to names.
+func calledFuncs(node parse.Node, names []string) []string {
+	switch n := node.(type) {
+	case *parse.IdentifierNode:
+		if !slices.Contains(names, n.Ident) {
+			names = append(names, n.Ident)
+		}
+	case *parse.ListNode:
+		if n == nil {
+			return names
+		}
+		for _, child := range n.Nodes {
+			names = calledFuncs(child, names)
+		}
+	case *parse.ActionNode:
+		names = calledFuncs(n.Pipe, names)
+	case *parse.PipeNode:
+		if n == nil {
+			return names
+		}
+		for _, cmd := range n.Cmds {
+			names = calledFuncs(cmd, names)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			names = calledFuncs(arg, names)
+		}
+	case *parse.ChainNode:
+		names = calledFuncs(n.Node, names)
+	case *parse.IfNode:
+		names = calledFuncs(&n.BranchNode, names)
+	case *parse.RangeNode:
+		names = calledFuncs(&n.BranchNode, names)
+	case *parse.WithNode:
+		names = calledFuncs(&n.BranchNode, names)
+	case *parse.BranchNode:
+		names = calledFuncs(n.Pipe, names)
+		names = calledFuncs(n.List, names)
+		names = calledFuncs(n.ElseList, names)
+	case *parse.TemplateNode:
+		names = calledFuncs(n.Pipe, names)
+	}
+	return names
+}
@@ -0,0 +1,231 @@
+package tmpls_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestSandbox(t *testing.T) {
+	t.Parallel()
+
+	sandboxFS := fstest.MapFS{
+		"common/layout.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ define "layout" }}{{ sourceComment "layout" }}` +
+				`{{ template "content" . }}{{ end }}`),
+		},
+		"tenants/acme/page.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ template "layout" . }}{{ define "content" }}` +
+				`{{ template "acme-nav" }}{{ truncate 3 .Title | printf "%s!" }}{{ end }}`),
+		},
+		"tenants/acme/nav.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ define "acme-nav" }}<nav>{{ end }}`),
+		},
+		"tenants/spy/version.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ buildVersion }}`),
+		},
+		"tenants/evil/reach.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ template "acme-nav" }}`),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		sandbox        tmpls.Sandbox
+		glob           string
+		template       string
+		expected       string
+		expectFunc     string
+		expectTemplate string
+	}{
+		{
+			name:     "should allow vetted funcs and templates in the directory tree",
+			glob:     "tenants/acme/*.html.tmpl",
+			template: "page.html.tmpl",
+			expected: "<!-- layout --><nav>Hel…!",
+		},
+		{
+			name:       "should reject other funcs",
+			glob:       "tenants/spy/version.html.tmpl",
+			template:   "version.html.tmpl",
+			expectFunc: "buildVersion",
+		},
+		{
+			name:     "should allow configured funcs",
+			sandbox:  tmpls.Sandbox{Funcs: []string{"buildVersion"}},
+			glob:     "tenants/spy/version.html.tmpl",
+			template: "version.html.tmpl",
+			expected: "v1",
+		},
+		{
+			name:       "should sandbox files reached through other globs",
+			glob:       "*/spy/*.html.tmpl",
+			template:   "version.html.tmpl",
+			expectFunc: "buildVersion",
+		},
+		{
+			name:           "should reject templates outside the directory tree",
+			glob:           "tenants/*/*.html.tmpl",
+			template:       "page.html.tmpl",
+			expectTemplate: "acme-nav",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: sandboxFS,
+					CommonGlob:  "common/*.html.tmpl",
+					BuildInfo:   &tmpls.BuildInfo{Version: "v1"},
+					Strictness: map[string]tmpls.Strictness{
+						"tenants/*/*": {Sandbox: &test.sandbox},
+					},
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			output, err := templates.Execute(
+				test.glob,
+				test.template,
+				map[string]string{"Title": "Hello"},
+			)
+			if test.expectFunc == "" && test.expectTemplate == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if output != test.expected {
+					t.Fatalf("expected %s but got %s", test.expected, output)
+				}
+				return
+			}
+			var sandboxErr *tmpls.SandboxError
+			if !tmpls.IsParseError(err) || !errors.As(err, &sandboxErr) {
+				t.Fatalf("expected sandbox error but got %v", err)
+			}
+			if sandboxErr.Func != test.expectFunc || sandboxErr.Template != test.expectTemplate {
+				t.Fatalf(
+					"expected %s%s but got %v",
+					test.expectFunc,
+					test.expectTemplate,
+					sandboxErr,
+				)
+			}
+		})
+	}
+}
+
+func TestSandboxesCombine(t *testing.T) {
+	t.Parallel()
+
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: fstest.MapFS{
+				"common/empty.html.tmpl": &fstest.MapFile{},
+				"tenants/spy/version.html.tmpl": &fstest.MapFile{
+					Data: []byte(`{{ buildVersion }}`),
+				},
+			},
+			CommonGlob: "common/*.html.tmpl",
+			Strictness: map[string]tmpls.Strictness{
+				"tenants/*/*":   {Sandbox: &tmpls.Sandbox{Funcs: []string{"buildVersion"}}},
+				"tenants/spy/*": {Sandbox: &tmpls.Sandbox{}},
+			},
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = templates.Execute("tenants/*/*.html.tmpl", "version.html.tmpl", nil)
+	var sandboxErr *tmpls.SandboxError
+	if !errors.As(err, &sandboxErr) || sandboxErr.Func != "buildVersion" {
+		t.Fatalf("expected buildVersion to be rejected but got %v", err)
+	}
+}
+
+func TestConfineIncludes(t *testing.T) {
+	t.Parallel()
+
+	confineFS := fstest.MapFS{
+		"common/layout.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ define "layout" }}<main>{{ template "content" . }}</main>{{ end }}`),
+		},
+		"admin/page.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ template "layout" . }}{{ define "content" }}` +
+				`{{ template "admin-nav" }}{{ end }}`),
+		},
+		"admin/nav.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ define "admin-nav" }}<nav>{{ buildVersion }}</nav>{{ end }}`),
+		},
+		"shop/index.html.tmpl": &fstest.MapFile{
+			Data: []byte(`{{ template "admin-nav" }}`),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		config         tmpls.Config
+		glob           string
+		expectTemplate string
+	}{
+		{
+			name:   "should allow templates from the directory tree",
+			config: tmpls.Config{ConfineIncludes: true},
+			glob:   "admin/*.html.tmpl",
+		},
+		{
+			name:           "should reject templates from other directories",
+			config:         tmpls.Config{ConfineIncludes: true},
+			glob:           "*/*.html.tmpl",
+			expectTemplate: "admin-nav",
+		},
+		{
+			name: "should only confine when configured",
+			glob: "*/*.html.tmpl",
+		},
+		{
+			name: "should confine globs with strictness",
+			config: tmpls.Config{
+				Strictness: map[string]tmpls.Strictness{
+					"*/*": {ConfineIncludes: true},
+				},
+			},
+			glob:           "*/*.html.tmpl",
+			expectTemplate: "admin-nav",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			config := test.config
+			config.TemplatesFS = confineFS
+			config.CommonGlob = "common/*.html.tmpl"
+			templates, err := tmpls.New(config, slog.Default())
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = templates.Execute(test.glob, "page.html.tmpl", nil)
+			if test.expectTemplate == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var sandboxErr *tmpls.SandboxError
+			if !tmpls.IsParseError(err) || !errors.As(err, &sandboxErr) {
+				t.Fatalf("expected sandbox error but got %v", err)
+			}
+			if sandboxErr.Template != test.expectTemplate || sandboxErr.Func != "" {
+				t.Fatalf("expected %s but got %v", test.expectTemplate, sandboxErr)
+			}
+		})
+	}
+}
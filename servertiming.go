@@ -0,0 +1,47 @@
+package tmpls
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"time"
+)
+
+// timedNewExecutor is newExecutor recording the parse in the execution
+// carried by ctx, if any, for Server-Timing.
+func (t *Templates) timedNewExecutor(
+	ctx context.Context,
+	fsys fs.FS,
+	glob string,
+) (Executor, []string, error) {
+	start := time.Now()
+	tmpl, files, err := t.newExecutor(fsys, glob)
+	if e := executionFrom(ctx); e != nil {
+		e.parsed = true
+		e.parseTime += time.Since(start)
+	}
+	return tmpl, files, err
+}
+
+// setServerTiming adds Server-Timing metrics for an execution that took
+// elapsed, so browser tools can attribute response time to rendering:
+//
+//	Server-Timing: tmpls-render;dur=12.5, tmpls-cache;desc=miss, tmpls-parse;dur=3.1
+//
+// tmpls-render includes parsing, which is only reported on a cache miss.
+func setServerTiming(header http.Header, e *execution, elapsed time.Duration) {
+	cache := "hit"
+	if e.parsed {
+		cache = "miss"
+	}
+	timing := fmt.Sprintf("tmpls-render;dur=%s, tmpls-cache;desc=%s", milliseconds(elapsed), cache)
+	if e.parsed {
+		timing += fmt.Sprintf(", tmpls-parse;dur=%s", milliseconds(e.parseTime))
+	}
+	header.Add("Server-Timing", timing)
+}
+
+func milliseconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,81 @@
+package tmpls
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+)
+
+// slot is content passed to a partial by a wrap block.
+type slot struct {
+	content string
+	dot     any
+}
+
+type executorKey struct{}
+
+// withExecutor returns a copy of ctx carrying the executor running the
+// execution, for funcs that execute other templates from the same set.
+func withExecutor(ctx context.Context, executor Executor) context.Context {
+	return context.WithValue(ctx, executorKey{}, executor)
+}
+
+func executorFrom(ctx context.Context) (Executor, *execution, error) {
+	executor, _ := ctx.Value(executorKey{}).(Executor)
+	execution := executionFrom(ctx)
+	if executor == nil || execution == nil {
+		return nil, nil, errors.New(
+			"executing templates from funcs requires HTMLEngine or TextEngine",
+		)
+	}
+	if execution.meter != nil {
+		if err := execution.meter.include(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return executor, execution, nil
+}
+
+// wrap executes the partial name with data, and the block's content available
+// to the partial through the slot func.
+func wrap(ctx context.Context) any {
+	return func(name string, data any, content string, dot any) (template.HTML, error) {
+		executor, execution, err := executorFrom(ctx)
+		if err != nil {
+			return "", err
+		}
+		execution.slots = append(execution.slots, slot{content: content, dot: dot})
+		defer func() {
+			execution.slots = execution.slots[:len(execution.slots)-1]
+		}()
+		var buffer bytes.Buffer
+		if err := executor.ExecuteTemplate(&buffer, name, data); err != nil {
+			return "", err
+		}
+		return template.HTML(buffer.String()), nil
+	}
+}
+
+// slot renders the content of the innermost wrap block, or nothing if the
+// partial wasn't wrapped around content. The content sees the slots of the
+// blocks around its own wrap block.
+func slotContent(ctx context.Context) any {
+	return func() (template.HTML, error) {
+		executor, execution, err := executorFrom(ctx)
+		if err != nil || len(execution.slots) == 0 {
+			return "", nil
+		}
+		slots := execution.slots
+		top := slots[len(slots)-1]
+		execution.slots = slots[:len(slots)-1]
+		defer func() {
+			execution.slots = slots
+		}()
+		var buffer bytes.Buffer
+		if err := executor.ExecuteTemplate(&buffer, top.content, top.dot); err != nil {
+			return "", err
+		}
+		return template.HTML(buffer.String()), nil
+	}
+}
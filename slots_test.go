@@ -0,0 +1,101 @@
+package tmpls_test
+
+import (
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestSlots(t *testing.T) {
+	t.Parallel()
+
+	card := `{{ define "card" }}<div class="card"><h2>{{ . }}</h2>{{ slot }}</div>{{ end }}`
+
+	tests := []struct {
+		name        string
+		src         string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should render content in slot with call site dot",
+			src:      `{{ wrap "card" "Title" }}<p>{{ .Body }}</p>{{ end }}`,
+			data:     map[string]string{"Body": "<b>"},
+			expected: `<div class="card"><h2>Title</h2><p>&lt;b&gt;</p></div>`,
+		},
+		{
+			name: "should nest wraps",
+			src:  `{{ wrap "card" "outer" }}{{ wrap "card" "inner" }}x{{ end }}{{ end }}`,
+			expected: `<div class="card"><h2>outer</h2>` +
+				`<div class="card"><h2>inner</h2>x</div></div>`,
+		},
+		{
+			name: "should keep control structures in content",
+			src: `{{ range . }}{{ wrap "card" . }}{{ if eq . "b" }}B{{ else }}-{{ end }}` +
+				`{{ end }}{{ end }}`,
+			data: []string{"a", "b"},
+			expected: `<div class="card"><h2>a</h2>-</div>` +
+				`<div class="card"><h2>b</h2>B</div>`,
+		},
+		{
+			name:     "should honour trim markers",
+			src:      "a {{- wrap \"card\" \"t\" -}}\n  x\n  {{- end -}} \n b",
+			expected: `a<div class="card"><h2>t</h2>x</div>b`,
+		},
+		{
+			name:     "should ignore delimiters in strings and comments",
+			src:      `{{/* {{ end }} */}}{{ wrap "card" "a}}b" }}x{{ end }}`,
+			expected: `<div class="card"><h2>a}}b</h2>x</div>`,
+		},
+		{
+			name:     "should render empty slot without wrap",
+			src:      `{{ template "card" "t" }}`,
+			expected: `<div class="card"><h2>t</h2></div>`,
+		},
+		{
+			name:        "should fail for unclosed wrap",
+			src:         `{{ wrap "card" . }}x`,
+			expectError: true,
+		},
+		{
+			name:        "should fail for else in wrap",
+			src:         `{{ wrap "card" . }}x{{ else }}y{{ end }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: fstest.MapFS{
+						"common/card.html.tmpl": &fstest.MapFile{Data: []byte(card)},
+						"page.html.tmpl":        &fstest.MapFile{Data: []byte(test.src)},
+					},
+					CommonGlob: "common/*.html.tmpl",
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", test.data)
+			if test.expectError {
+				if !tmpls.IsParseError(err) {
+					t.Fatalf("expected parse error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
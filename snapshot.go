@@ -0,0 +1,76 @@
+package tmpls
+
+import (
+	"bytes"
+	"context"
+)
+
+// LastKnownGood keeps the output of successful executions so a later failure,
+// such as a bad hot-swapped template, serves the previous output with a
+// warning instead of an error. Output calling nonce, csrfToken or csrfField
+// isn't kept, since it only suits the request it was rendered for.
+type LastKnownGood struct {
+	// Key derives the snapshot key from the data, which must identify
+	// everything the output depends on, such as the user, so one user's page
+	// is never served to another. It should have few distinct values since
+	// every snapshot is kept in memory. Nothing is kept without it.
+	Key func(data any) string
+}
+
+type snapshotKey struct {
+	glob     string
+	template string
+	key      string
+}
+
+func (t *Templates) snapshotKey(glob string, templateName string, data any) snapshotKey {
+	return snapshotKey{glob: glob, template: templateName, key: t.config.LastKnownGood.Key(data)}
+}
+
+func (t *Templates) saveSnapshot(
+	ctx context.Context,
+	buffer *bytes.Buffer,
+	glob string,
+	templateName string,
+	data any,
+) {
+	if !t.snapshotting() || buffer == nil {
+		return
+	}
+	if e := executionFrom(ctx); e != nil && e.requestBound {
+		return
+	}
+	t.snapshots.Store(t.snapshotKey(glob, templateName, data), buffer.String())
+}
+
+// restoreSnapshot replaces the buffer with the last known good output for
+// the failed execution, reporting whether there was one. Streamed executions,
+// with a nil buffer, have none.
+func (t *Templates) restoreSnapshot(
+	buffer *bytes.Buffer,
+	glob string,
+	templateName string,
+	data any,
+	err error,
+) bool {
+	if !t.snapshotting() || buffer == nil {
+		return false
+	}
+	snapshot, ok := t.snapshots.Load(t.snapshotKey(glob, templateName, data))
+	if !ok {
+		return false
+	}
+	buffer.Reset()
+	buffer.WriteString(snapshot.(string))
+	t.log().Warn(
+		"Template execution failed, served last known good output",
+		"error", err,
+		"glob", glob,
+		"template", templateName,
+	)
+	return true
+}
+
+func (t *Templates) snapshotting() bool {
+	return t.config.LastKnownGood != nil && t.config.LastKnownGood.Key != nil
+}
@@ -0,0 +1,120 @@
+package tmpls_test
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestLastKnownGood(t *testing.T) {
+	t.Parallel()
+
+	page := func(any) string { return "page" }
+
+	tests := []struct {
+		name        string
+		page        string
+		broken      string
+		key         func(data any) string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should serve snapshot after parse error",
+			broken:   `{{ if }}`,
+			key:      page,
+			data:     "a",
+			expected: "<p>a</p>",
+		},
+		{
+			name:     "should serve snapshot after exec error",
+			broken:   `{{ .Missing }}`,
+			key:      page,
+			data:     "a",
+			expected: "<p>a</p>",
+		},
+		{
+			name:     "should serve snapshot regardless of data for constant key",
+			broken:   `{{ if }}`,
+			key:      page,
+			data:     "b",
+			expected: "<p>a</p>",
+		},
+		{
+			name:        "should not snapshot without key",
+			broken:      `{{ if }}`,
+			data:        "a",
+			expectError: true,
+		},
+		{
+			name:        "should not snapshot pages using nonces",
+			page:        `<p nonce="{{ nonce }}">{{ . }}</p>`,
+			broken:      `{{ if }}`,
+			key:         page,
+			data:        "a",
+			expectError: true,
+		},
+		{
+			name:     "should serve snapshot for same key",
+			broken:   `{{ if }}`,
+			key:      func(data any) string { return fmt.Sprint(data) },
+			data:     "a",
+			expected: "<p>a</p>",
+		},
+		{
+			name:        "should return error without snapshot for key",
+			broken:      `{{ if }}`,
+			key:         func(data any) string { return fmt.Sprint(data) },
+			data:        "b",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			src := test.page
+			if src == "" {
+				src = `<p>{{ . }}</p>`
+			}
+			fsys := fstest.MapFS{
+				"common/empty.html.tmpl": &fstest.MapFile{},
+				"page.html.tmpl":         &fstest.MapFile{Data: []byte(src)},
+			}
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS:   fsys,
+					CommonGlob:    "common/*.html.tmpl",
+					DisableCache:  true,
+					LastKnownGood: &tmpls.LastKnownGood{Key: test.key},
+				},
+				slog.New(slog.DiscardHandler),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := templates.Execute("page.html.tmpl", "page.html.tmpl", "a"); err != nil {
+				t.Fatal(err)
+			}
+
+			fsys["page.html.tmpl"] = &fstest.MapFile{Data: []byte(test.broken)}
+			output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", test.data)
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package tmpls
+
+import (
+	"fmt"
+	"html/template"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// sourceComment returns an HTML comment holding text, used to mark where
+// output came from when Config.SourceComments is set. html/template drops
+// comments written in templates, so they are emitted by a func instead.
+func sourceComment(text string) template.HTML {
+	text = strings.ReplaceAll(text, "--", "- -")
+	return template.HTML("<!-- " + text + " -->")
+}
+
+// annotate marks the output of src, a file named file, and of each template
+// it defines with begin and end comments naming the template and file:
+//
+//	<!-- tmpls: begin "nav" (common/layout.html.tmpl) -->
+//
+// Comments in the wrong place would break the page, so only templates whose
+// content starts with a tag, and so are likely called between elements, are
+// marked. Content starting with a doctype isn't marked either, since anything
+// before it switches browsers into quirks mode.
+func annotate(file string, src string) (string, error) {
+	type frame struct {
+		name string
+	}
+	var out strings.Builder
+	var frames []frame
+	comment := func(kind string, name string, trim string, after bool) {
+		text := fmt.Sprintf("tmpls: %s %q (%s)", kind, name, file)
+		if after {
+			fmt.Fprintf(&out, "{{ sourceComment %q %s}}", text, trim)
+		} else {
+			fmt.Fprintf(&out, "{{%s sourceComment %q }}", trim, text)
+		}
+	}
+	root := ""
+	if startsWithTag(src) {
+		root = path.Base(file)
+		comment("begin", root, "", true)
+	}
+	for len(src) > 0 {
+		start := strings.Index(src, "{{")
+		if start < 0 {
+			out.WriteString(src)
+			break
+		}
+		out.WriteString(src[:start])
+		a, err := parseAction(src[start:])
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", file, err)
+		}
+		src = src[start+len(a.text):]
+
+		switch {
+		case a.keyword == "define" || a.keyword == "block":
+			name := ""
+			if startsWithTag(src) {
+				name = definedName(a.args)
+			}
+			frames = append(frames, frame{name: name})
+			if name == "" {
+				out.WriteString(a.text)
+				continue
+			}
+			// the trim marker moves to the comment, next to the whitespace
+			text := a.text
+			if a.rtrim {
+				text = strings.TrimSuffix(text, " -}}") + " }}"
+			}
+			out.WriteString(text)
+			comment("begin", name, trimMarker(a.rtrim), true)
+		case a.keyword == "if" || a.keyword == "range" || a.keyword == "with" ||
+			blockFuncs[a.keyword]:
+			frames = append(frames, frame{})
+			out.WriteString(a.text)
+		case a.keyword == "end" && len(frames) > 0:
+			top := frames[len(frames)-1]
+			frames = frames[:len(frames)-1]
+			if top.name == "" {
+				out.WriteString(a.text)
+				continue
+			}
+			comment("end", top.name, trimMarker(a.ltrim), false)
+			text := a.text
+			if a.ltrim {
+				text = "{{ " + strings.TrimPrefix(text, "{{- ")
+			}
+			out.WriteString(text)
+		default:
+			out.WriteString(a.text)
+		}
+	}
+	if root != "" {
+		comment("end", root, "", false)
+	}
+	return out.String(), nil
+}
+
+// startsWithTag reports whether src starts with a tag other than a doctype or
+// comment, after whitespace.
+func startsWithTag(src string) bool {
+	src = strings.TrimLeft(src, " \t\r\n")
+	return strings.HasPrefix(src, "<") && !strings.HasPrefix(src, "<!")
+}
+
+// definedName returns the name defined by the args of a define or block
+// action, or "" if it isn't a plain string.
+func definedName(args string) string {
+	if args == "" || (args[0] != '"' && args[0] != '`') {
+		return ""
+	}
+	name, err := strconv.Unquote(args[:quotedEnd(args)])
+	if err != nil {
+		return ""
+	}
+	return name
+}
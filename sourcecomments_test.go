@@ -0,0 +1,99 @@
+package tmpls_test
+
+import (
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestSourceComments(t *testing.T) {
+	t.Parallel()
+
+	layout := `{{ define "nav" }}<nav>{{ . }}</nav>{{ end }}` +
+		`{{ define "title" }}Title{{ end }}`
+
+	tests := []struct {
+		name           string
+		src            string
+		template       string
+		sourceComments bool
+		expected       string
+	}{
+		{
+			name:     "should not mark output by default",
+			src:      `<main>{{ template "nav" "x" }}</main>`,
+			expected: `<main><nav>x</nav></main>`,
+		},
+		{
+			name:           "should mark files and defines starting with tags",
+			src:            `<main>{{ template "nav" "x" }}</main>`,
+			sourceComments: true,
+			expected: `<!-- tmpls: begin "page.html.tmpl" (page.html.tmpl) --><main>` +
+				`<!-- tmpls: begin "nav" (common/layout.html.tmpl) --><nav>x</nav>` +
+				`<!-- tmpls: end "nav" (common/layout.html.tmpl) --></main>` +
+				`<!-- tmpls: end "page.html.tmpl" (page.html.tmpl) -->`,
+		},
+		{
+			name:           "should not mark defines starting with text",
+			src:            `{{ define "page" }}<title>{{ template "title" }}</title>{{ end }}`,
+			template:       "page",
+			sourceComments: true,
+			expected: `<!-- tmpls: begin "page" (page.html.tmpl) -->` +
+				`<title>Title</title><!-- tmpls: end "page" (page.html.tmpl) -->`,
+		},
+		{
+			name:           "should not mark doctypes",
+			src:            `<!DOCTYPE html><html></html>`,
+			sourceComments: true,
+			expected:       `<!DOCTYPE html><html></html>`,
+		},
+		{
+			name:           "should keep trim markers",
+			src:            "{{ define \"page\" -}}\n  <p>{{ if true }}x{{ end }}</p>\n{{- end }}",
+			template:       "page",
+			sourceComments: true,
+			expected: `<!-- tmpls: begin "page" (page.html.tmpl) --><p>x</p>` +
+				`<!-- tmpls: end "page" (page.html.tmpl) -->`,
+		},
+		{
+			name:           "should mark block funcs content",
+			src:            `<p>{{ once "x" }}<b>y</b>{{ end }}</p>`,
+			sourceComments: true,
+			expected: `<!-- tmpls: begin "page.html.tmpl" (page.html.tmpl) -->` +
+				`<p><b>y</b></p><!-- tmpls: end "page.html.tmpl" (page.html.tmpl) -->`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: fstest.MapFS{
+						"common/layout.html.tmpl": &fstest.MapFile{Data: []byte(layout)},
+						"page.html.tmpl":          &fstest.MapFile{Data: []byte(test.src)},
+					},
+					CommonGlob:     "common/*.html.tmpl",
+					SourceComments: test.sourceComments,
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			name := test.template
+			if name == "" {
+				name = "page.html.tmpl"
+			}
+			output, err := templates.Execute("page.html.tmpl", name, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
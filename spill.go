@@ -0,0 +1,92 @@
+package tmpls
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+)
+
+const defaultSpillThreshold = 1 << 20
+
+// ExecuteLarge executes a template for exports and reports far larger than
+// typical pages, keeping heap usage flat. Output is buffered in memory up to
+// Config.SpillThreshold bytes and then written to a temporary file in
+// Config.SpillDir, removed when the returned reader is closed. Nothing is
+// returned if execution fails.
+//
+// As with Stream, Config.ErrorTemplate and Config.LastKnownGood don't apply.
+func (t *Templates) ExecuteLarge(
+	ctx context.Context,
+	glob string,
+	template string,
+	data any,
+) (io.ReadCloser, error) {
+	threshold := t.config.SpillThreshold
+	if threshold <= 0 {
+		threshold = defaultSpillThreshold
+	}
+	w := &spillWriter{threshold: threshold, dir: t.config.SpillDir}
+	if err := t.executeTo(withExecution(ctx), w, glob, template, data); err != nil {
+		w.discard()
+		return nil, err
+	}
+	return w.reader()
+}
+
+// spillWriter buffers writes in memory until they exceed threshold, then
+// moves them to a temporary file.
+type spillWriter struct {
+	threshold int
+	dir       string
+	buffer    bytes.Buffer
+	file      *os.File
+}
+
+func (w *spillWriter) Write(p []byte) (int, error) {
+	if w.file == nil && w.buffer.Len()+len(p) > w.threshold {
+		file, err := os.CreateTemp(w.dir, "tmpls-*.html")
+		if err != nil {
+			return 0, err
+		}
+		w.file = file
+		if _, err := w.buffer.WriteTo(file); err != nil {
+			return 0, err
+		}
+	}
+	if w.file != nil {
+		return w.file.Write(p)
+	}
+	return w.buffer.Write(p)
+}
+
+func (w *spillWriter) reader() (io.ReadCloser, error) {
+	if w.file == nil {
+		return io.NopCloser(&w.buffer), nil
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		w.discard()
+		return nil, err
+	}
+	return &spillFile{File: w.file}, nil
+}
+
+func (w *spillWriter) discard() {
+	if w.file != nil {
+		w.file.Close()
+		os.Remove(w.file.Name())
+	}
+}
+
+// spillFile is a temporary file removed on Close.
+type spillFile struct {
+	*os.File
+}
+
+func (f *spillFile) Close() error {
+	err := f.File.Close()
+	if removeErr := os.Remove(f.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
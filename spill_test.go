@@ -0,0 +1,110 @@
+package tmpls_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestExecuteLarge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		data        any
+		expected    string
+		files       int
+		expectError bool
+	}{
+		{
+			name:     "should keep small output in memory",
+			src:      `{{ . }}`,
+			data:     "small",
+			expected: "small",
+		},
+		{
+			name:     "should spill large output to file",
+			src:      `{{ range . }}{{ . }}{{ end }}`,
+			data:     []string{"0123456789", "abcdefghij", "<>"},
+			expected: "0123456789abcdefghij&lt;&gt;",
+			files:    1,
+		},
+		{
+			name:        "should remove file on error",
+			src:         `{{ range . }}{{ . }}{{ end }}{{ index . 5 }}`,
+			data:        []string{"0123456789", "abcdefghij"},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: fstest.MapFS{
+						"common/empty.html.tmpl": &fstest.MapFile{},
+						"page.html.tmpl":         &fstest.MapFile{Data: []byte(test.src)},
+					},
+					CommonGlob:     "common/*.html.tmpl",
+					SpillThreshold: 16,
+					SpillDir:       dir,
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files := func() int {
+				t.Helper()
+				entries, err := os.ReadDir(dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return len(entries)
+			}
+			reader, err := templates.ExecuteLarge(
+				context.Background(),
+				"page.html.tmpl",
+				"page.html.tmpl",
+				test.data,
+			)
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				if count := files(); count != 0 {
+					t.Fatalf("expected no files but got %d", count)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count := files(); count != test.files {
+				t.Fatalf("expected %d files but got %d", test.files, count)
+			}
+			var output strings.Builder
+			if _, err := io.Copy(&output, reader); err != nil {
+				t.Fatal(err)
+			}
+			if err := reader.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if output.String() != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output.String())
+			}
+			if count := files(); count != 0 {
+				t.Fatalf("expected no files but got %d", count)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package tmpls
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Stream executes a template in a goroutine, returning a reader of its output
+// as it is written, so large documents can be sent into an upload or proxied
+// request without buffering them. An execution error is returned by Read once
+// the output written before it has been read, and by Close, which waits for
+// the execution to finish. Closing the reader early stops the execution.
+//
+// Since output is streamed, Config.ErrorTemplate and Config.LastKnownGood don't
+// apply.
+func (t *Templates) Stream(
+	ctx context.Context,
+	glob string,
+	template string,
+	data any,
+) io.ReadCloser {
+	reader, writer := io.Pipe()
+	s := &stream{reader: reader, done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		s.err = t.executeTo(withExecution(ctx), writer, glob, template, data)
+		writer.CloseWithError(s.err)
+	}()
+	return s
+}
+
+// executeTo executes a template straight into w, without the buffering
+// ErrorTemplate and LastKnownGood need.
+func (t *Templates) executeTo(
+	ctx context.Context,
+	w io.Writer,
+	glob string,
+	templateName string,
+	data any,
+) error {
+	if err := t.run(ctx, nil, w, glob, templateName, data); err != nil {
+		return err
+	}
+	if t.config.TrackUsage {
+		t.recordUsage(glob, templateName)
+	}
+	return nil
+}
+
+// stream is the reader returned by Stream.
+type stream struct {
+	reader *io.PipeReader
+	done   chan struct{}
+	err    error
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	return s.reader.Read(p)
+}
+
+// Close stops the execution if it is still writing and returns its error,
+// other than the one caused by closing the reader.
+func (s *stream) Close() error {
+	s.reader.Close()
+	<-s.done
+	if errors.Is(s.err, io.ErrClosedPipe) {
+		return nil
+	}
+	return s.err
+}
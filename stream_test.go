@@ -0,0 +1,107 @@
+package tmpls_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		data        any
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should stream output",
+			src:      `{{ range . }}<p>{{ . }}</p>{{ end }}`,
+			data:     []string{"a", "<b>"},
+			expected: `<p>a</p><p>&lt;b&gt;</p>`,
+		},
+		{
+			name:        "should return exec error",
+			src:         `<p>{{ index . 5 }}</p>`,
+			data:        []string{"a"},
+			expectError: true,
+		},
+		{
+			name:        "should return parse error",
+			src:         `{{ if }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newStreamTemplates(t, test.src)
+			reader := templates.Stream(
+				context.Background(),
+				"page.html.tmpl",
+				"page.html.tmpl",
+				test.data,
+			)
+			output, readErr := io.ReadAll(reader)
+			closeErr := reader.Close()
+			if test.expectError {
+				if readErr == nil || closeErr == nil {
+					t.Fatalf("expected errors but got %v and %v", readErr, closeErr)
+				}
+				return
+			}
+			if readErr != nil {
+				t.Fatal(readErr)
+			}
+			if closeErr != nil {
+				t.Fatal(closeErr)
+			}
+			if string(output) != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestStreamClosedEarly(t *testing.T) {
+	t.Parallel()
+
+	templates := newStreamTemplates(t, `{{ range . }}{{ . }}{{ end }}`)
+	data := make([]string, 10000)
+	for i := range data {
+		data[i] = strings.Repeat("x", 100)
+	}
+	reader := templates.Stream(context.Background(), "page.html.tmpl", "page.html.tmpl", data)
+	if _, err := io.ReadFull(reader, make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := reader.Close(); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+}
+
+func newStreamTemplates(t *testing.T, src string) *tmpls.Templates {
+	t.Helper()
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: fstest.MapFS{
+				"common/empty.html.tmpl": &fstest.MapFile{},
+				"page.html.tmpl":         &fstest.MapFile{Data: []byte(src)},
+			},
+			CommonGlob: "common/*.html.tmpl",
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return templates
+}
@@ -0,0 +1,50 @@
+package tmpls
+
+import "path"
+
+// Strictness holds the settings deciding how strictly globs are checked,
+// so mixed-trust template sets can be strict for application pages and
+// lenient for snippets from a CMS:
+//
+//	tmpls.Config{
+//		MissingKeyError: true,
+//		Strictness: map[string]tmpls.Strictness{
+//			"cms/*": {},
+//		},
+//	}
+type Strictness struct {
+	// MissingKeyError replaces Config.MissingKeyError.
+	MissingKeyError bool
+	// DataLimits replaces Config.DataLimits. Nil is unlimited.
+	DataLimits *DataLimits
+	// ParseLimits replaces Config.ParseLimits. Nil is unlimited.
+	ParseLimits *ParseLimits
+	// ConfineIncludes replaces Config.ConfineIncludes.
+	ConfineIncludes bool
+	// Sandbox, if set, restricts the funcs and templates the glob's own
+	// files can reach, confining their includes regardless of
+	// ConfineIncludes.
+	Sandbox *Sandbox
+	// Quotas replaces Config.Quotas. Nil is unlimited.
+	Quotas *Quotas
+}
+
+// strictness returns the Strictness of the longest Config.Strictness
+// pattern matching glob, or else the one set by Config itself.
+func (t *Templates) strictness(glob string) Strictness {
+	strictness := Strictness{
+		MissingKeyError: t.config.MissingKeyError,
+		DataLimits:      t.config.DataLimits,
+		ParseLimits:     t.config.ParseLimits,
+		ConfineIncludes: t.config.ConfineIncludes,
+		Quotas:          t.config.Quotas,
+	}
+	selected := ""
+	for pattern, s := range t.config.Strictness {
+		if matched, _ := path.Match(pattern, glob); !matched || len(pattern) <= len(selected) {
+			continue
+		}
+		strictness, selected = s, pattern
+	}
+	return strictness
+}
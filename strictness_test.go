@@ -0,0 +1,97 @@
+package tmpls_test
+
+import (
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestStrictness(t *testing.T) {
+	t.Parallel()
+
+	strictnessFS := fstest.MapFS{
+		"pages/page.html.tmpl":    &fstest.MapFile{Data: []byte(`{{ .title }}`)},
+		"cms/snippet.html.tmpl":   &fstest.MapFile{Data: []byte(`{{ .title }}`)},
+		"cms/trusted-a.html.tmpl": &fstest.MapFile{Data: []byte(`{{ .title }}`)},
+		"common/common.html.tmpl": &fstest.MapFile{},
+	}
+	config := tmpls.Config{
+		TemplatesFS:     strictnessFS,
+		CommonGlob:      "common/*.html.tmpl",
+		MissingKeyError: true,
+		Strictness: map[string]tmpls.Strictness{
+			"cms/*":         {DataLimits: &tmpls.DataLimits{MaxLen: 1}},
+			"cms/trusted-*": {MissingKeyError: true},
+		},
+	}
+	templates, err := tmpls.New(config, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		glob        string
+		template    string
+		data        map[string]any
+		expected    string
+		expectError bool
+	}{
+		{
+			name:        "should apply the config without a matching pattern",
+			glob:        "pages/page.html.tmpl",
+			template:    "page.html.tmpl",
+			data:        map[string]any{},
+			expectError: true,
+		},
+		{
+			name:     "should apply the matching strictness",
+			glob:     "cms/snippet.html.tmpl",
+			template: "snippet.html.tmpl",
+			data:     map[string]any{},
+			expected: "",
+		},
+		{
+			name:        "should apply the data limits of the matching strictness",
+			glob:        "cms/snippet.html.tmpl",
+			template:    "snippet.html.tmpl",
+			data:        map[string]any{"a": 1, "b": 2},
+			expectError: true,
+		},
+		{
+			name:        "should apply the longest matching pattern",
+			glob:        "cms/trusted-a.html.tmpl",
+			template:    "trusted-a.html.tmpl",
+			data:        map[string]any{},
+			expectError: true,
+		},
+		{
+			name:     "should match globs as passed to execute",
+			glob:     "cms/*.html.tmpl",
+			template: "snippet.html.tmpl",
+			data:     map[string]any{"title": "hi"},
+			expected: "hi",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := templates.Execute(test.glob, test.template, test.data)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
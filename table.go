@@ -0,0 +1,199 @@
+package tmpls
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Table describes an admin table of items from a slice of structs or maps,
+// rendered by the table func or used by a partial for custom markup.
+type Table struct {
+	Columns []Column
+	// Items is a slice or array of structs, maps or pointers to them, one
+	// for each row.
+	Items any
+	// Sort is the Field of the sortable column the rows are sorted by,
+	// descending if it starts with "-".
+	Sort string
+	// URL is the table's URL, usually the request's, whose query the sort
+	// links keep.
+	URL *url.URL
+	// Param names the query param holding the sort. Defaults to "sort".
+	Param string
+}
+
+// Column is a column of a Table.
+type Column struct {
+	// Header labels the column.
+	Header string
+	// Field is the field or map key path of the column's values, such as
+	// "Author.Name".
+	Field string
+	// Sortable makes the header a link sorting the table by the column.
+	Sortable bool
+	// Format, if set, formats the column's values, e.g. as dates. Values
+	// are passed with pointers followed, or nil. A template.HTML result is
+	// rendered as is; anything else is escaped.
+	Format func(value any) any
+}
+
+// TableHeader is a header of a Table with its sort link.
+type TableHeader struct {
+	Label string
+	// SortURL links to the table sorted by the column, ascending unless it
+	// already is. It is empty for columns that aren't sortable.
+	SortURL string
+	// Sorted is "ascending" or "descending" if the table is sorted by the
+	// column, as aria-sort expects, and otherwise "".
+	Sorted string
+}
+
+// TableRow is a row of a Table with its formatted cells.
+type TableRow struct {
+	Item  any
+	Cells []template.HTML
+}
+
+// NewTable returns a Table of items sorted by the "sort" query param of r's
+// URL, which is ignored unless it names a sortable column.
+func NewTable(r *http.Request, items any, columns ...Column) Table {
+	t := Table{Columns: columns, Items: items, URL: r.URL}
+	t.Sort = r.URL.Query().Get(t.param())
+	return t
+}
+
+// sortColumn returns the sortable column named by Sort, if any.
+func (t Table) sortColumn() (column Column, descending bool, ok bool) {
+	field, descending := strings.CutPrefix(t.Sort, "-")
+	for _, column := range t.Columns {
+		if column.Sortable && column.Field == field {
+			return column, descending, true
+		}
+	}
+	return Column{}, false, false
+}
+
+// Headers returns the column headers with their sort links.
+func (t Table) Headers() []TableHeader {
+	sorted, descending, ok := t.sortColumn()
+	headers := make([]TableHeader, len(t.Columns))
+	for i, column := range t.Columns {
+		headers[i].Label = column.Header
+		if !column.Sortable {
+			continue
+		}
+		sort := column.Field
+		if ok && column.Field == sorted.Field {
+			headers[i].Sorted = "ascending"
+			if descending {
+				headers[i].Sorted = "descending"
+			} else {
+				sort = "-" + sort
+			}
+		}
+		headers[i].SortURL = t.sortURL(sort)
+	}
+	return headers
+}
+
+func (t Table) sortURL(sort string) string {
+	u := t.URL
+	if u == nil {
+		u = &url.URL{}
+	}
+	query, _ := setQuery(u.Query(), []any{t.param(), sort})
+	return withQuery(u, query)
+}
+
+// Rows returns a row for each item, sorted by the Sort column, with its
+// cells formatted.
+func (t Table) Rows() ([]TableRow, error) {
+	items, err := collectionItems("table", t.Items)
+	if err != nil {
+		return nil, err
+	}
+	if column, descending, ok := t.sortColumn(); ok {
+		field := column.Field
+		if descending {
+			field = "-" + field
+		}
+		if items, err = sortBy(field, items); err != nil {
+			return nil, fmt.Errorf("table: %w", err)
+		}
+	}
+	rows := make([]TableRow, len(items))
+	for i, item := range items {
+		rows[i] = TableRow{Item: item, Cells: make([]template.HTML, len(t.Columns))}
+		for j, column := range t.Columns {
+			if rows[i].Cells[j], err = column.cell(i, item); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return rows, nil
+}
+
+// cell returns the column's formatted value for item, the i-th row.
+func (c Column) cell(i int, item any) (template.HTML, error) {
+	v, err := itemField("table", c.Field, i, item)
+	if err != nil {
+		return "", err
+	}
+	var value any
+	if v = indirectValue(v); v.IsValid() {
+		value = v.Interface()
+	}
+	if c.Format != nil {
+		value = c.Format(value)
+	}
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case template.HTML:
+		return value, nil
+	default:
+		return template.HTML(template.HTMLEscapeString(fmt.Sprint(value))), nil
+	}
+}
+
+// HTML renders the table with sort links in its headers.
+func (t Table) HTML() (template.HTML, error) {
+	rows, err := t.Rows()
+	if err != nil {
+		return "", err
+	}
+	var s strings.Builder
+	s.WriteString(`<table><thead><tr>`)
+	for _, header := range t.Headers() {
+		label := template.HTMLEscapeString(header.Label)
+		s.WriteString(`<th scope="col"`)
+		if header.Sorted != "" {
+			fmt.Fprintf(&s, ` aria-sort="%s"`, header.Sorted)
+		}
+		if header.SortURL == "" {
+			fmt.Fprintf(&s, `>%s</th>`, label)
+		} else {
+			fmt.Fprintf(&s, `><a href="%s">%s</a></th>`, safeHref(header.SortURL), label)
+		}
+	}
+	s.WriteString(`</tr></thead><tbody>`)
+	for _, row := range rows {
+		s.WriteString(`<tr>`)
+		for _, cell := range row.Cells {
+			fmt.Fprintf(&s, `<td>%s</td>`, cell)
+		}
+		s.WriteString(`</tr>`)
+	}
+	s.WriteString(`</tbody></table>`)
+	return template.HTML(s.String()), nil
+}
+
+func (t Table) param() string {
+	if t.Param == "" {
+		return "sort"
+	}
+	return t.Param
+}
@@ -0,0 +1,126 @@
+package tmpls_test
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestTable(t *testing.T) {
+	t.Parallel()
+
+	columns := []tmpls.Column{
+		{Header: "Name", Field: "Name", Sortable: true},
+		{
+			Header: "Price",
+			Field:  "Price",
+			Format: func(value any) any {
+				return template.HTML(fmt.Sprintf("<b>€%v</b>", value))
+			},
+			Sortable: true,
+		},
+		{Header: "Supplier", Field: "Supplier.Name"},
+	}
+	items := []product{
+		{Name: "pen", Price: 2, Supplier: &supplier{Name: "<Acme>"}},
+		{Name: "mug", Price: 8},
+	}
+
+	tests := []struct {
+		name        string
+		url         string
+		columns     []tmpls.Column
+		items       any
+		expected    string
+		expectError bool
+	}{
+		{
+			name:    "should render rows in order with sort links",
+			url:     "/products?page=2",
+			columns: columns,
+			items:   items,
+			expected: `<table><thead><tr>` +
+				`<th scope="col"><a href="/products?page=2&amp;sort=Name">Name</a></th>` +
+				`<th scope="col"><a href="/products?page=2&amp;sort=Price">Price</a></th>` +
+				`<th scope="col">Supplier</th>` +
+				`</tr></thead><tbody>` +
+				`<tr><td>pen</td><td><b>€2</b></td><td>&lt;Acme&gt;</td></tr>` +
+				`<tr><td>mug</td><td><b>€8</b></td><td></td></tr>` +
+				`</tbody></table>`,
+		},
+		{
+			name:    "should sort ascending and link to descending",
+			url:     "/products?sort=Name",
+			columns: columns[:1],
+			items:   items,
+			expected: `<table><thead><tr>` +
+				`<th scope="col" aria-sort="ascending">` +
+				`<a href="/products?sort=-Name">Name</a></th>` +
+				`</tr></thead><tbody>` +
+				`<tr><td>mug</td></tr><tr><td>pen</td></tr>` +
+				`</tbody></table>`,
+		},
+		{
+			name:    "should sort descending and link to ascending",
+			url:     "/products?sort=-Name",
+			columns: columns[:1],
+			items:   items,
+			expected: `<table><thead><tr>` +
+				`<th scope="col" aria-sort="descending">` +
+				`<a href="/products?sort=Name">Name</a></th>` +
+				`</tr></thead><tbody>` +
+				`<tr><td>pen</td></tr><tr><td>mug</td></tr>` +
+				`</tbody></table>`,
+		},
+		{
+			name:    "should ignore sorts by columns that aren't sortable",
+			url:     "/products?sort=-Supplier.Name",
+			columns: columns[2:],
+			items:   items,
+			expected: `<table><thead><tr>` +
+				`<th scope="col">Supplier</th>` +
+				`</tr></thead><tbody>` +
+				`<tr><td>&lt;Acme&gt;</td></tr><tr><td></td></tr>` +
+				`</tbody></table>`,
+		},
+		{
+			name:        "should fail on missing fields",
+			url:         "/products",
+			columns:     []tmpls.Column{{Header: "Color", Field: "Color"}},
+			items:       items,
+			expectError: true,
+		},
+		{
+			name:        "should fail on items that aren't collections",
+			url:         "/products",
+			columns:     columns,
+			items:       "pens",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest(http.MethodGet, test.url, nil)
+			table := tmpls.NewTable(r, test.items, test.columns...)
+			output, err := render(t, tmpls.Config{}, `{{ table . }}`, table)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
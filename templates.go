@@ -2,93 +2,665 @@ package tmpls
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"io/fs"
 	"log/slog"
+	"path"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Config struct {
 	TemplatesFS  fs.FS
 	DisableCache bool
 	CommonGlob   string
+	TrackUsage   bool
+	Faults       FaultInjector
+	// Deterministic makes time-, random- and environment-dependent built-in
+	// funcs return fixed values, for reproducible golden-file tests.
+	Deterministic *Deterministic
+	// Clock is the time source for built-in time funcs. Defaults to the
+	// system clock, or a fixed clock when Deterministic is set.
+	Clock Clock
+	// Rand is the randomness source for built-in random funcs and must be
+	// safe for concurrent use. Defaults to crypto/rand, or a seeded source
+	// when Deterministic is set.
+	Rand io.Reader
+	// ErrorTemplate names a template, usually in CommonGlob, that replaces
+	// the output when executing a template fails against its data. It is
+	// executed with an ErrorData.
+	ErrorTemplate string
+	// DataLimits, if set, are checked against the data before execution.
+	DataLimits *DataLimits
+	// ParseLimits, if set, bound the templates parsed into each glob.
+	ParseLimits *ParseLimits
+	// Compiled maps templates to Go functions compiled ahead of time, which
+	// are used in their place unless DisableCache is set.
+	Compiled map[CompiledKey]CompiledFunc
+	// Engines selects an Engine by file extension, e.g. ".txt.tmpl", for
+	// globs and common files ending in it. Everything else is parsed with
+	// TextEngine if it ends in ".txt.tmpl" and HTMLEngine otherwise.
+	Engines map[string]Engine
+	// LastKnownGood, if set with a Key, serves the last successful output
+	// when executing fails, before falling back to ErrorTemplate.
+	LastKnownGood *LastKnownGood
+	// WatchDebounce coalesces changes seen by Watch until none have arrived
+	// for this long, so saving many files at once reloads once.
+	WatchDebounce time.Duration
+	// Flash supplies the one-time messages returned by the flash func.
+	Flash FlashProvider
+	// Users resolves the currentUser func from the execution's context. It
+	// defaults to the RenderContext's User.
+	Users UserProvider
+	// CSRF supplies the tokens emitted by the csrfToken and csrfField funcs.
+	CSRF CSRFProvider
+	// CSRFFieldName names the csrfField input. Defaults to "csrf_token".
+	CSRFFieldName string
+	// CSP is the Content-Security-Policy header set by RenderRequest, with
+	// NoncePlaceholder replaced by the request's nonce, e.g.
+	// "script-src 'nonce-{nonce}'".
+	CSP string
+	// Meta holds site-wide defaults for the metaTags func.
+	Meta *Meta
+	// ActiveClass is returned by the active and activePrefix funcs for the
+	// current nav item. Defaults to "active".
+	ActiveClass string
+	// Components registers the partials rendered by the component func, by
+	// name.
+	Components map[string]Component
+	// ComponentsGlob matches partials parsed into every glob like CommonGlob,
+	// each also registered as a component under its base name without
+	// extensions, e.g. "button" for "components/button.html.tmpl".
+	ComponentsGlob string
+	// ParseOnInit makes New parse every ".tmpl" file in TemplatesFS outside
+	// CommonGlob and ComponentsGlob as its own glob, failing with the errors
+	// of every broken one. Escaping errors still only surface on execution.
+	ParseOnInit bool
+	// PreloadGlobs makes New parse each glob, as passed to Execute, failing
+	// with the errors of every broken one, for globs other than single files
+	// that ParseOnInit doesn't cover.
+	PreloadGlobs []string
+	// URLs builds the URLs of named routes for the url func, e.g. a Routes.
+	URLs URLBuilder
+	// URLSchemes allows URL schemes for the safeURL func. Defaults to http,
+	// https, mailto and tel.
+	URLSchemes []string
+	// Audit, if set, logs every execution with the data's hash.
+	Audit *Audit
+	// TenantCache, if set, partitions cached executors by tenant with a limit
+	// for each.
+	TenantCache *TenantCache
+	// MissingTTL caches the error of a glob matching no files, or a missing
+	// file, for this long, so repeated requests for a missing template don't
+	// search TemplatesFS every time. Zero disables it. Watch and Reload drop
+	// cached errors.
+	MissingTTL time.Duration
+	// StrictReload makes executions fail once a glob changed while watching,
+	// or reloaded, fails to parse. By default the error is logged and the
+	// glob's previous templates are served until it changes again, unless
+	// its files were removed.
+	StrictReload bool
+	// RefreshInterval is how often Refresh reparses every cached glob in the
+	// background, e.g. to pick up templates from a remote or database-backed
+	// TemplatesFS.
+	RefreshInterval time.Duration
+	// RenderLimit, if set, bounds simultaneous executions.
+	RenderLimit *RenderLimit
+	// SpillThreshold is how much output ExecuteLarge buffers in memory before
+	// moving it to a temporary file. Defaults to 1 MiB.
+	SpillThreshold int
+	// SpillDir holds ExecuteLarge's temporary files. Defaults to os.TempDir.
+	SpillDir string
+	// TrackLatency records how long executions take, reported by Latency.
+	TrackLatency bool
+	// SourceComments marks the output of each file and define starting with
+	// a tag with HTML comments naming the template and file it came from, so
+	// the file to edit can be found from the page source. It is meant for
+	// development.
+	SourceComments bool
+	// ServerTiming makes RenderRequest add the render time, and whether the
+	// glob was parsed or cached, to the Server-Timing header.
+	ServerTiming bool
+	// ResolveConcurrency, if positive, resolves the Providers a template
+	// passes to the resolve func from its data before executing it, this many
+	// at a time.
+	ResolveConcurrency int
+	// ResponseCache, if set, caches the output of RenderRequest and answers
+	// matching If-None-Match headers with 304 Not Modified.
+	ResponseCache *ResponseCache
+	// BuildInfo is returned by the buildInfo and buildVersion funcs. Defaults
+	// to the version and VCS details stamped into the binary.
+	BuildInfo *BuildInfo
+	// Env lists the environment variables the env func may read, keeping
+	// secrets in the environment out of reach of templates.
+	Env []string
+	// QRCode renders the images of the qrCode func, which fails without it.
+	QRCode QREncoder
+	// Avatars builds the URLs of the avatar func. Defaults to Gravatar.
+	Avatars AvatarProvider
+	// Funcs are custom template funcs available in every template, replacing
+	// built-in funcs of the same name. A ContextFunc is bound to the context
+	// of each execution, like the built-in request funcs.
+	Funcs template.FuncMap
+	// MissingKeyError makes executions fail when a template indexes a map,
+	// such as a dict, with a missing key, so typos fail loudly instead of
+	// rendering nothing. Missing struct fields always fail. It applies to
+	// templates parsed with HTMLEngine.
+	MissingKeyError bool
+	// Highlighter syntax-highlights code for the highlight func, which
+	// otherwise leaves it to the browser.
+	Highlighter Highlighter
+	// Emoji adds shortcodes to the emoji func, or replaces built-in ones, by
+	// name without colons, e.g. "shipit".
+	Emoji map[string]string
+	// ConfineIncludes makes template actions in each glob's own files only
+	// call templates defined in their directory tree or shared files,
+	// failing to parse with a *SandboxError otherwise, so pages can't couple
+	// to each other's partials.
+	ConfineIncludes bool
+	// Quotas, if set, bound the resources of each execution, failing those
+	// exceeding them with a *QuotaError.
+	Quotas *Quotas
+	// Strictness replaces MissingKeyError, DataLimits, ParseLimits,
+	// ConfineIncludes and Quotas, and sets a Sandbox, for globs matching its
+	// keys, path.Match patterns matched against the glob as passed to
+	// Execute. The longest matching pattern applies.
+	Strictness map[string]Strictness
+}
+
+// FaultInjector lets tests force failures and delays for chosen globs. A
+// non-nil error from either method is returned in place of the real result.
+// BeforeExecute is given the execution's context so delays can end with it.
+type FaultInjector interface {
+	BeforeParse(glob string) error
+	BeforeExecute(ctx context.Context, glob string, template string) error
+}
+
+// Usage reports how many times a template was successfully executed from a
+// glob. It is only recorded when Config.TrackUsage is set.
+type Usage struct {
+	Glob     string
+	Template string
+	Count    int64
+}
+
+type usageKey struct {
+	glob     string
+	template string
+}
+
+// missingGlob is the cached error of a glob whose files are missing.
+type missingGlob struct {
+	err     error
+	expires time.Time
+}
+
+// state is the templates filesystem and the executors parsed from it,
+// swapped together so executions never mix files from two filesystems.
+type state struct {
+	fsys       fs.FS
+	executors  *sync.Map
+	components atomic.Pointer[map[string]Component]
+	fragments  sync.Map
+	missing    sync.Map
+	responses  sync.Map
+	// tenants holds each tenant's cached globs for Config.TenantCache
+	tenants sync.Map
 }
 
 type Templates struct {
 	config    Config
-	executors sync.Map
+	state     atomic.Pointer[state]
 	buffers   sync.Pool
-	logger    *slog.Logger
+	usage     sync.Map
+	latency   sync.Map
+	snapshots sync.Map
+	slowGlobs sync.Map
+	swapMu    sync.Mutex
+	swapped   chan struct{}
+	clock     Clock
+	random    io.Reader
+	logger    atomic.Pointer[slog.Logger]
+	level     slog.LevelVar
+	levelSet  atomic.Bool
+	ticks     atomic.Int64
+	metering  atomic.Bool
+	limiter   *renderLimiter
+	build     BuildInfo
+	envValues map[string]string
 }
 
 func New(config Config, logger *slog.Logger) (*Templates, error) {
 	if config.TemplatesFS == nil {
 		return nil, fmt.Errorf("TemplatesFS is required")
 	}
-	if config.DisableCache {
-		logger.Warn("Template caching disabled - templates will be parsed on each request")
+	if err := checkFuncs(config.Funcs); err != nil {
+		return nil, err
+	}
+	clock := config.Clock
+	if clock == nil {
+		clock = defaultClock(config.Deterministic)
+	}
+	random := config.Rand
+	if random == nil {
+		random = defaultRand(config.Deterministic)
 	}
-	return &Templates{
-		config:    config,
-		executors: sync.Map{},
+	t := &Templates{
+		config: config,
 		buffers: sync.Pool{
 			New: func() any {
 				return &bytes.Buffer{}
 			},
 		},
-		logger: logger,
-	}, nil
+		clock:   clock,
+		random:  random,
+		limiter: newRenderLimiter(config.RenderLimit),
+	}
+	t.build = t.buildInfo()
+	t.envValues = t.readEnv()
+	t.state.Store(&state{fsys: config.TemplatesFS, executors: &sync.Map{}})
+	t.SetLogger(logger)
+	if config.DisableCache {
+		t.log().Warn("Template caching disabled - templates will be parsed on each request")
+	}
+	if config.ParseOnInit {
+		if err := t.parseAll(!config.DisableCache); err != nil {
+			return nil, err
+		}
+	}
+	if err := t.preload(config.PreloadGlobs); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// preload parses globs, caching the results unless DisableCache is set.
+func (t *Templates) preload(globs []string) error {
+	state := t.state.Load()
+	var errs []error
+	for _, glob := range globs {
+		tmpl, files, err := t.newExecutor(state.fsys, glob)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !t.config.DisableCache {
+			t.storeExecutor(state, glob, &cachedExecutor{executor: tmpl, files: files})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate parses every ".tmpl" file in TemplatesFS outside CommonGlob and
+// ComponentsGlob as its own glob, as ParseOnInit does, and returns the
+// ParseErrors of every broken one joined with errors.Join, e.g. to check all
+// templates in a test without listing globs. Nothing is cached.
+func (t *Templates) Validate() error {
+	return t.parseAll(false)
+}
+
+// parseAll parses every template file outside the shared globs as its own
+// glob, caching the results if cache is set.
+func (t *Templates) parseAll(cache bool) error {
+	state := t.state.Load()
+	var errs []error
+	err := fs.WalkDir(state.fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() || !strings.HasSuffix(file, ".tmpl") {
+			return err
+		}
+		for _, pattern := range []string{t.config.CommonGlob, t.config.ComponentsGlob} {
+			if shared, _ := path.Match(pattern, file); shared {
+				return nil
+			}
+		}
+		tmpl, files, err := t.newExecutor(state.fsys, file)
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if cache {
+			t.storeExecutor(state, file, &cachedExecutor{executor: tmpl, files: files})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return errors.Join(errs...)
 }
 
 func (t *Templates) Execute(
 	glob string,
 	template string,
 	data any,
+) (string, error) {
+	return t.ExecuteContext(context.Background(), glob, template, data)
+}
+
+// ExecuteContext is Execute with ctx available to funcs, including the
+// RenderContext added with WithRenderContext. Once ctx is done, execution
+// stops at its next write and fails with ctx's error, without falling back to
+// ErrorTemplate or LastKnownGood.
+func (t *Templates) ExecuteContext(
+	ctx context.Context,
+	glob string,
+	template string,
+	data any,
 ) (string, error) {
 	buffer := t.buffers.Get().(*bytes.Buffer)
 	defer func() {
 		buffer.Reset()
 		t.buffers.Put(buffer)
 	}()
-	if err := t.execute(buffer, glob, template, data); err != nil {
+	if err := t.execute(withExecution(ctx), buffer, glob, template, data); err != nil {
 		return "", err
 	}
+	if t.config.TrackUsage {
+		t.recordUsage(glob, template)
+	}
 	return buffer.String(), nil
 }
 
+// ExecuteTo is Execute writing the output to w instead of returning it, to
+// skip the copy into a string when it is written straight out. The output is
+// still buffered, so nothing is written if execution fails.
+func (t *Templates) ExecuteTo(w io.Writer, glob string, template string, data any) error {
+	buffer := t.buffers.Get().(*bytes.Buffer)
+	defer func() {
+		buffer.Reset()
+		t.buffers.Put(buffer)
+	}()
+	ctx := withExecution(context.Background())
+	if err := t.execute(ctx, buffer, glob, template, data); err != nil {
+		return err
+	}
+	if t.config.TrackUsage {
+		t.recordUsage(glob, template)
+	}
+	_, err := buffer.WriteTo(w)
+	return err
+}
+
+// Usage returns a snapshot of recorded executions. It is empty unless
+// Config.TrackUsage is set.
+func (t *Templates) Usage() []Usage {
+	var usage []Usage
+	t.usage.Range(func(key, value any) bool {
+		k := key.(usageKey)
+		usage = append(usage, Usage{
+			Glob:     k.glob,
+			Template: k.template,
+			Count:    value.(*atomic.Int64).Load(),
+		})
+		return true
+	})
+	return usage
+}
+
+func (t *Templates) recordUsage(glob string, template string) {
+	key := usageKey{glob: glob, template: template}
+	value, ok := t.usage.Load(key)
+	if !ok {
+		value, _ = t.usage.LoadOrStore(key, &atomic.Int64{})
+	}
+	value.(*atomic.Int64).Add(1)
+}
+
+// execute executes a template into buffer, replacing its output with a
+// LastKnownGood snapshot or Config.ErrorTemplate when execution fails.
 func (t *Templates) execute(
+	ctx context.Context,
 	buffer *bytes.Buffer,
 	glob string,
 	templateName string,
 	data any,
 ) error {
-	if t.config.DisableCache {
-		tmpl, err := t.newExecutor(glob)
-		if err != nil {
+	return t.run(ctx, buffer, nil, glob, templateName, data)
+}
+
+// run is the execution pipeline shared by execute and executeTo, writing the
+// output to buffer, whose output fallbacks can replace, or if nil straight
+// to stream, which can't take back what was written, so has none.
+func (t *Templates) run(
+	ctx context.Context,
+	buffer *bytes.Buffer,
+	stream io.Writer,
+	glob string,
+	templateName string,
+	data any,
+) (err error) {
+	if t.config.Audit != nil {
+		// hashed first since funcs such as set can modify data
+		hash, hashErr := dataHash(data)
+		defer func() {
+			t.audit(ctx, glob, templateName, hash, hashErr, err)
+		}()
+	}
+	if err := t.limiter.acquire(ctx); err != nil {
+		return err
+	}
+	defer t.limiter.release()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if t.config.TrackLatency {
+		start := time.Now()
+		defer func() {
+			if err == nil {
+				t.recordLatency(glob, templateName, time.Since(start))
+			}
+		}()
+	}
+	if limits := t.strictness(glob).DataLimits; limits != nil {
+		if err := limits.check(data); err != nil {
+			return &Error{Kind: ExecError, Glob: glob, Template: templateName, Err: err}
+		}
+	}
+	w := stream
+	if buffer != nil {
+		w = buffer
+	}
+	var tmpl Executor
+	if fn, ok := t.compiled(glob, templateName); ok {
+		err = t.executeCompiled(ctx, fn, t.metered(ctx, glob, w), glob, templateName, data)
+	} else {
+		if tmpl, err = t.executor(ctx, glob); err != nil {
+			if t.restoreSnapshot(buffer, glob, templateName, data, err) {
+				return nil
+			}
 			return err
 		}
-		return tmpl.ExecuteTemplate(buffer, templateName, data)
+		err = t.executeTemplate(ctx, tmpl, t.metered(ctx, glob, w), glob, templateName, data)
+	}
+	if err == nil {
+		t.saveSnapshot(ctx, buffer, glob, templateName, data)
+		return nil
 	}
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		// the caller has gone, so there's no one to show a fallback to
+		return err
+	}
+	if t.restoreSnapshot(buffer, glob, templateName, data, err) {
+		return nil
+	}
+	if buffer != nil && t.config.ErrorTemplate != "" && IsExecError(err) {
+		if tmpl == nil {
+			// compiled templates need the set to execute ErrorTemplate from
+			var executorErr error
+			if tmpl, executorErr = t.executor(ctx, glob); executorErr != nil {
+				return err
+			}
+		}
+		return t.executeErrorTemplate(ctx, tmpl, buffer, glob, templateName, data, err)
+	}
+	return err
+}
 
-	value, _ := t.executors.Load(glob)
-	var tmpl *template.Template
-	if value == nil {
-		var err error
-		tmpl, err = t.newExecutor(glob)
-		if err != nil {
-			return err
+func (t *Templates) executor(ctx context.Context, glob string) (Executor, error) {
+	state := t.state.Load()
+	if t.config.DisableCache {
+		tmpl, _, err := t.timedNewExecutor(ctx, state.fsys, glob)
+		return tmpl, err
+	}
+
+	value, _ := state.executors.Load(glob)
+	var previous *cachedExecutor
+	if value != nil {
+		cached := value.(*cachedExecutor)
+		if t.config.TenantCache != nil {
+			cached.lastUsed.Store(t.ticks.Add(1))
 		}
-		t.executors.Store(glob, tmpl)
-	} else {
-		tmpl = value.(*template.Template)
+		if !cached.stale {
+			return cached.executor, nil
+		}
+		previous = cached
+	}
+	now := t.clock.Now()
+	if value, ok := state.missing.Load(glob); ok {
+		if missing := value.(*missingGlob); now.Before(missing.expires) {
+			return nil, missing.err
+		}
+	}
+	tmpl, files, err := t.timedNewExecutor(ctx, state.fsys, glob)
+	if err != nil {
+		if previous != nil {
+			if kept, ok := t.keepPrevious(state, glob, previous, err); ok {
+				return kept, nil
+			}
+		}
+		if t.config.MissingTTL > 0 && errors.Is(err, fs.ErrNotExist) {
+			state.missing.Store(glob, &missingGlob{err: err, expires: now.Add(t.config.MissingTTL)})
+		}
+		return nil, err
 	}
+	t.storeExecutor(state, glob, &cachedExecutor{executor: tmpl, files: files})
+	return tmpl, nil
+}
 
-	return tmpl.ExecuteTemplate(buffer, templateName, data)
+func (t *Templates) executeTemplate(
+	ctx context.Context,
+	tmpl Executor,
+	w io.Writer,
+	glob string,
+	templateName string,
+	data any,
+) error {
+	if t.config.Faults != nil {
+		if err := t.config.Faults.BeforeExecute(ctx, glob, templateName); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+				return ctxErr
+			}
+			return &Error{Kind: ExecError, Glob: glob, Template: templateName, Err: err}
+		}
+	}
+	if t.config.ResolveConcurrency > 0 {
+		t.prefetch(ctx, tmpl, templateName, data)
+	}
+	if err := executeTemplate(ctx, tmpl, w, templateName, data); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+			return ctxErr
+		}
+		return executeError(glob, templateName, err)
+	}
+	return nil
+}
+
+// executeCompiled is executeTemplate for a template compiled ahead of time.
+func (t *Templates) executeCompiled(
+	ctx context.Context,
+	fn CompiledFunc,
+	w io.Writer,
+	glob string,
+	templateName string,
+	data any,
+) error {
+	if t.config.Faults != nil {
+		if err := t.config.Faults.BeforeExecute(ctx, glob, templateName); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+				return ctxErr
+			}
+			return &Error{Kind: ExecError, Glob: glob, Template: templateName, Err: err}
+		}
+	}
+	if err := fn(contextWriter{ctx: ctx, w: w}, data); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+			return ctxErr
+		}
+		return &Error{Kind: ExecError, Glob: glob, Template: templateName, Err: err}
+	}
+	return nil
+}
+
+// executeTemplate executes with ctx if tmpl supports it.
+func executeTemplate(
+	ctx context.Context,
+	tmpl Executor,
+	w io.Writer,
+	name string,
+	data any,
+) error {
+	if html, ok := tmpl.(*htmlExecutor); ok {
+		return html.ExecuteTemplateContext(ctx, w, name, data)
+	}
+	if funcsFrom(ctx) != nil {
+		return fmt.Errorf("%T doesn't support ExecuteWithFuncs", tmpl)
+	}
+	if contextExecutor, ok := tmpl.(ContextExecutor); ok {
+		return contextExecutor.ExecuteTemplateContext(ctx, w, name, data)
+	}
+	return tmpl.ExecuteTemplate(contextWriter{ctx: ctx, w: w}, name, data)
+}
+
+// contextWriter fails writes once ctx is done, aborting the execution
+// writing to it, so a page whose client has gone away stops rendering.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (w contextWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return w.w.Write(p)
 }
 
-func (t *Templates) newExecutor(patterns ...string) (*template.Template, error) {
-	// common goes first so it can be overridden
-	allPatterns := append([]string{t.config.CommonGlob}, patterns...)
-	return template.ParseFS(t.config.TemplatesFS, allPatterns...)
+// newExecutor parses glob from fsys, also returning the files it was parsed
+// from.
+func (t *Templates) newExecutor(fsys fs.FS, glob string) (Executor, []string, error) {
+	if t.config.Faults != nil {
+		if err := t.config.Faults.BeforeParse(glob); err != nil {
+			return nil, nil, parseError(glob, err)
+		}
+	}
+	t.log().Debug("Parsing templates", "glob", glob, "commonGlob", t.config.CommonGlob)
+	files, err := t.files(fsys, glob)
+	if err != nil {
+		return nil, nil, parseError(glob, err)
+	}
+	strictness := t.strictness(glob)
+	var limits ParseLimits
+	if strictness.ParseLimits != nil {
+		limits = *strictness.ParseLimits
+	}
+	if err := limits.checkFiles(fsys, files); err != nil {
+		return nil, nil, parseError(glob, err)
+	}
+	engine, _ := t.engineFor(glob)
+	tmpl, err := limits.parse(&t.slowGlobs, glob, func() (Executor, error) {
+		return engine.Parse(fsys, files, t.builtinFuncs())
+	})
+	if err != nil {
+		return nil, nil, parseError(glob, err)
+	}
+	if err := t.confine(fsys, glob, files); err != nil {
+		return nil, nil, parseError(glob, err)
+	}
+	return tmpl, files, nil
 }
@@ -1,10 +1,17 @@
 package tmpls_test
 
 import (
+	"context"
+	"errors"
 	"io/fs"
 	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/fivethirty/tmpls"
 )
@@ -245,3 +252,423 @@ func TestHTMLEscaping(t *testing.T) {
 		t.Fatalf("expected %s but got %s", expected, output)
 	}
 }
+
+func TestUsage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		trackUsage bool
+		expected   []tmpls.Usage
+	}{
+		{
+			name:       "should record usage when enabled",
+			trackUsage: true,
+			expected: []tmpls.Usage{
+				{Glob: "test.html.tmpl", Template: "test.html.tmpl", Count: 2},
+			},
+		},
+		{
+			name:       "should not record usage when disabled",
+			trackUsage: false,
+			expected:   nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpls, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: testFS,
+					CommonGlob:  "common/*.html.tmpl",
+					TrackUsage:  test.trackUsage,
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for range 2 {
+				if _, err := tmpls.Execute(
+					"test.html.tmpl",
+					"test.html.tmpl",
+					templateData{Text: "world"},
+				); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if !reflect.DeepEqual(tmpls.Usage(), test.expected) {
+				t.Fatalf("expected %v but got %v", test.expected, tmpls.Usage())
+			}
+		})
+	}
+}
+
+func TestParseOnInit(t *testing.T) {
+	t.Parallel()
+
+	broken := &fstest.MapFile{Data: []byte(`{{ if }}`)}
+	tests := []struct {
+		name         string
+		templatesFS  fstest.MapFS
+		expectedErrs []string
+	}{
+		{
+			name:        "should parse every template",
+			templatesFS: testFS,
+		},
+		{
+			name: "should report every broken template",
+			templatesFS: fstest.MapFS{
+				"common/common.html.tmpl": testFS["common/common.html.tmpl"],
+				"test.html.tmpl":          testFS["test.html.tmpl"],
+				"broken.html.tmpl":        broken,
+				"nested/broken.txt.tmpl":  broken,
+			},
+			expectedErrs: []string{"broken.html.tmpl", "nested/broken.txt.tmpl"},
+		},
+		{
+			name: "should skip shared files and files other than templates",
+			templatesFS: fstest.MapFS{
+				"common/common.html.tmpl":     testFS["common/common.html.tmpl"],
+				"components/button.html.tmpl": &fstest.MapFile{Data: []byte(`<button>`)},
+				"test.html.tmpl":              testFS["test.html.tmpl"],
+				"test.html.tmpl.json":         broken,
+				"static/script.js":            broken,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS:    test.templatesFS,
+					CommonGlob:     "common/*.html.tmpl",
+					ComponentsGlob: "components/*.html.tmpl",
+					ParseOnInit:    true,
+				},
+				slog.Default(),
+			)
+			var globs []string
+			for _, err := range unjoin(err) {
+				var tmplsErr *tmpls.Error
+				if !errors.As(err, &tmplsErr) || tmplsErr.Kind != tmpls.ParseError {
+					t.Fatalf("expected parse error but got %v", err)
+				}
+				globs = append(globs, tmplsErr.Glob)
+			}
+			if !slices.Equal(globs, test.expectedErrs) {
+				t.Fatalf("expected errors in %v but got %v", test.expectedErrs, globs)
+			}
+		})
+	}
+}
+
+func TestPreloadGlobs(t *testing.T) {
+	t.Parallel()
+
+	preloadFS := fstest.MapFS{
+		"common/common.html.tmpl": testFS["common/common.html.tmpl"],
+		"pages/a.html.tmpl":       testFS["test.html.tmpl"],
+		"pages/b.html.tmpl":       testFS["test.html.tmpl"],
+		"broken/a.html.tmpl":      testFS["test.html.tmpl"],
+		"broken/b.html.tmpl":      &fstest.MapFile{Data: []byte(`{{ if }}`)},
+	}
+	tests := []struct {
+		name          string
+		globs         []string
+		expectedErrs  []string
+		expectMissing bool
+	}{
+		{
+			name:  "should parse every glob",
+			globs: []string{"pages/*.html.tmpl", "pages/a.html.tmpl"},
+		},
+		{
+			name:         "should report every broken glob",
+			globs:        []string{"broken/*.html.tmpl", "pages/*.html.tmpl", "broken/b.html.tmpl"},
+			expectedErrs: []string{"broken/*.html.tmpl", "broken/b.html.tmpl"},
+		},
+		{
+			name:          "should fail on globs matching nothing",
+			globs:         []string{"missing/*.html.tmpl"},
+			expectMissing: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS:  preloadFS,
+					CommonGlob:   "common/*.html.tmpl",
+					PreloadGlobs: test.globs,
+				},
+				slog.Default(),
+			)
+			if test.expectMissing {
+				if !errors.Is(err, fs.ErrNotExist) {
+					t.Fatalf("expected %v but got %v", fs.ErrNotExist, err)
+				}
+				return
+			}
+			var globs []string
+			for _, err := range unjoin(err) {
+				var tmplsErr *tmpls.Error
+				if !errors.As(err, &tmplsErr) || tmplsErr.Kind != tmpls.ParseError {
+					t.Fatalf("expected parse error but got %v", err)
+				}
+				globs = append(globs, tmplsErr.Glob)
+			}
+			if !slices.Equal(globs, test.expectedErrs) {
+				t.Fatalf("expected errors in %v but got %v", test.expectedErrs, globs)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	broken := &fstest.MapFile{Data: []byte(`{{ if }}`)}
+	tests := []struct {
+		name         string
+		templatesFS  fstest.MapFS
+		expectedErrs []string
+	}{
+		{
+			name:        "should pass when every template parses",
+			templatesFS: testFS,
+		},
+		{
+			name: "should report every broken template",
+			templatesFS: fstest.MapFS{
+				"common/common.html.tmpl": testFS["common/common.html.tmpl"],
+				"test.html.tmpl":          testFS["test.html.tmpl"],
+				"a/broken.html.tmpl":      broken,
+				"b/broken.txt.tmpl":       broken,
+			},
+			expectedErrs: []string{"a/broken.html.tmpl", "b/broken.txt.tmpl"},
+		},
+		{
+			name: "should report templates broken by the common set",
+			templatesFS: fstest.MapFS{
+				"common/common.html.tmpl": broken,
+				"test.html.tmpl":          testFS["test.html.tmpl"],
+			},
+			expectedErrs: []string{"test.html.tmpl"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: test.templatesFS,
+					CommonGlob:  "common/*.html.tmpl",
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var globs []string
+			for _, err := range unjoin(templates.Validate()) {
+				var tmplsErr *tmpls.Error
+				if !errors.As(err, &tmplsErr) || tmplsErr.Kind != tmpls.ParseError {
+					t.Fatalf("expected parse error but got %v", err)
+				}
+				globs = append(globs, tmplsErr.Glob)
+			}
+			if !slices.Equal(globs, test.expectedErrs) {
+				t.Fatalf("expected errors in %v but got %v", test.expectedErrs, globs)
+			}
+		})
+	}
+}
+
+func unjoin(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	if err != nil {
+		return []error{err}
+	}
+	return nil
+}
+
+func TestMissingTTL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		ttl         time.Duration
+		advance     time.Duration
+		expectError bool
+	}{
+		{
+			name: "should not cache missing globs without ttl",
+		},
+		{
+			name:        "should cache missing globs for ttl",
+			ttl:         time.Minute,
+			advance:     30 * time.Second,
+			expectError: true,
+		},
+		{
+			name:    "should search again after ttl",
+			ttl:     time.Minute,
+			advance: time.Minute,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			fsys := fstest.MapFS{"common/empty.html.tmpl": &fstest.MapFile{}}
+			var now atomic.Int64
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: fsys,
+					CommonGlob:  "common/*.html.tmpl",
+					MissingTTL:  test.ttl,
+					Clock: tmpls.ClockFunc(func() time.Time {
+						return time.Unix(now.Load(), 0)
+					}),
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+			if !errors.Is(err, fs.ErrNotExist) {
+				t.Fatalf("expected %v but got %v", fs.ErrNotExist, err)
+			}
+			fsys["page.html.tmpl"] = &fstest.MapFile{Data: []byte("page")}
+			now.Add(int64(test.advance / time.Second))
+			output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+			if test.expectError {
+				if !errors.Is(err, fs.ErrNotExist) {
+					t.Fatalf("expected %v but got %v", fs.ErrNotExist, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != "page" {
+				t.Fatalf("expected page but got %s", output)
+			}
+		})
+	}
+}
+
+func TestExecuteTo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should write output",
+			src:      `hello {{ . }}`,
+			expected: "hello world",
+		},
+		{
+			name:        "should write nothing on error",
+			src:         `hello {{ index . 5 }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, tmpls.Config{}, test.src)
+			var out strings.Builder
+			err := templates.ExecuteTo(&out, "page.html.tmpl", "page.html.tmpl", "world")
+			if test.expectError != tmpls.IsExecError(err) {
+				t.Fatalf("expected exec error %t but got %v", test.expectError, err)
+			}
+			if !test.expectError && err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, out.String())
+			}
+		})
+	}
+}
+
+// canceler cancels a context when a template calls its Cancel method.
+type canceler struct {
+	cancel context.CancelFunc
+}
+
+func (c canceler) Cancel() string {
+	c.cancel()
+	return ""
+}
+
+func TestExecuteContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		config   tmpls.Config
+		src      string
+		cancel   bool
+		expected error
+	}{
+		{
+			name:     "should not execute with a done context",
+			src:      `hello`,
+			cancel:   true,
+			expected: context.Canceled,
+		},
+		{
+			name: "should stop executing once the context is done",
+			src: `{{ range list 1 2 }}{{ . }}{{ end }}{{ .Cancel }}` +
+				`{{ range list 3 4 }}{{ . }}{{ end }}`,
+			expected: context.Canceled,
+		},
+		{
+			name:     "should not render the error template once the context is done",
+			config:   tmpls.Config{ErrorTemplate: "page.html.tmpl"},
+			src:      `a{{ .Cancel }}b`,
+			expected: context.Canceled,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, test.config, test.src)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.cancel {
+				cancel()
+			}
+			output, err := templates.ExecuteContext(
+				ctx,
+				"page.html.tmpl",
+				"page.html.tmpl",
+				canceler{cancel: cancel},
+			)
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected %v but got %v (%s)", test.expected, err, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package tmpls
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// TenantCache partitions cached executors by tenant, evicting a tenant's
+// least recently used executors once it has more than its limit, so a tenant
+// with thousands of templates can't crowd out everyone else's.
+type TenantCache struct {
+	// Tenant returns the tenant glob belongs to. Defaults to the glob's first
+	// path element, e.g. "acme" for "acme/pages/*.html.tmpl".
+	Tenant func(glob string) string
+	// MaxEntries limits the executors cached for each tenant. Zero is
+	// unlimited.
+	MaxEntries int
+	// Limits overrides MaxEntries for individual tenants.
+	Limits map[string]int
+}
+
+func (c *TenantCache) tenant(glob string) string {
+	if c.Tenant != nil {
+		return c.Tenant(glob)
+	}
+	tenant, _, _ := strings.Cut(glob, "/")
+	return tenant
+}
+
+func (c *TenantCache) limit(tenant string) int {
+	if limit, ok := c.Limits[tenant]; ok {
+		return limit
+	}
+	return c.MaxEntries
+}
+
+// tenantGlobs is the set of globs a tenant has cached, so storing one only
+// looks at that tenant's executors. Globs dropped from the cache elsewhere
+// are pruned once the tenant is over its limit.
+type tenantGlobs struct {
+	mu    sync.Mutex
+	globs map[string]struct{}
+}
+
+// storeExecutor caches cached for glob, evicting the least recently used
+// executors of glob's tenant beyond its TenantCache limit.
+func (t *Templates) storeExecutor(state *state, glob string, cached *cachedExecutor) {
+	c := t.config.TenantCache
+	if c == nil {
+		state.executors.Store(glob, cached)
+		return
+	}
+	cached.lastUsed.Store(t.ticks.Add(1))
+	state.executors.Store(glob, cached)
+	tenant := c.tenant(glob)
+	limit := c.limit(tenant)
+	if limit <= 0 {
+		return
+	}
+	value, _ := state.tenants.LoadOrStore(tenant, &tenantGlobs{globs: map[string]struct{}{}})
+	tenantGlobs := value.(*tenantGlobs)
+	tenantGlobs.mu.Lock()
+	defer tenantGlobs.mu.Unlock()
+	tenantGlobs.globs[glob] = struct{}{}
+	if len(tenantGlobs.globs) <= limit {
+		return
+	}
+	type entry struct {
+		glob     string
+		lastUsed int64
+	}
+	entries := make([]entry, 0, len(tenantGlobs.globs))
+	for other := range tenantGlobs.globs {
+		value, ok := state.executors.Load(other)
+		if !ok {
+			delete(tenantGlobs.globs, other)
+			continue
+		}
+		entries = append(entries, entry{
+			glob:     other,
+			lastUsed: value.(*cachedExecutor).lastUsed.Load(),
+		})
+	}
+	if len(entries) <= limit {
+		return
+	}
+	slices.SortFunc(entries, func(a, b entry) int {
+		return cmp.Compare(a.lastUsed, b.lastUsed)
+	})
+	for _, e := range entries[:len(entries)-limit] {
+		state.executors.Delete(e.glob)
+		delete(tenantGlobs.globs, e.glob)
+		t.log().Debug("Evicted cached templates", "glob", e.glob, "tenant", tenant)
+	}
+}
@@ -0,0 +1,154 @@
+package tmpls_test
+
+import (
+	"context"
+	"log/slog"
+	"path"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+// parseCounter is a tmpls.FaultInjector counting parses per glob.
+type parseCounter struct {
+	mu     sync.Mutex
+	parses map[string]int
+}
+
+func (c *parseCounter) BeforeParse(glob string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.parses[glob]++
+	return nil
+}
+
+func (c *parseCounter) BeforeExecute(context.Context, string, string) error {
+	return nil
+}
+
+func (c *parseCounter) count(glob string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.parses[glob]
+}
+
+func TestTenantCache(t *testing.T) {
+	t.Parallel()
+
+	// reload in globs reloads every cached glob
+	const reload = "reload"
+
+	tests := []struct {
+		name     string
+		cache    *tmpls.TenantCache
+		globs    []string
+		expected map[string]int
+	}{
+		{
+			name:  "should evict least recently used of tenant",
+			cache: &tmpls.TenantCache{MaxEntries: 2},
+			globs: []string{"a/1", "a/2", "b/1", "b/2", "a/1", "a/3", "a/1", "a/2", "b/1"},
+			expected: map[string]int{
+				"a/1": 1,
+				"a/2": 2,
+				"a/3": 1,
+				"b/1": 1,
+				"b/2": 1,
+			},
+		},
+		{
+			name: "should use tenant limit",
+			cache: &tmpls.TenantCache{
+				MaxEntries: 2,
+				Limits:     map[string]int{"b": 1},
+			},
+			globs: []string{"a/1", "a/2", "b/1", "b/2", "a/1", "b/1"},
+			expected: map[string]int{
+				"a/1": 1,
+				"a/2": 1,
+				"b/1": 2,
+				"b/2": 1,
+			},
+		},
+		{
+			name: "should use tenant func",
+			cache: &tmpls.TenantCache{
+				Tenant: func(string) string {
+					return "all"
+				},
+				MaxEntries: 1,
+			},
+			globs: []string{"a/1", "b/1", "a/1"},
+			expected: map[string]int{
+				"a/1": 2,
+				"b/1": 1,
+			},
+		},
+		{
+			name:  "should evict reloaded executors",
+			cache: &tmpls.TenantCache{MaxEntries: 2},
+			globs: []string{"a/1", "a/2", reload, "a/2", "a/3", "a/1"},
+			expected: map[string]int{
+				"a/1": 3,
+				"a/2": 2,
+				"a/3": 1,
+			},
+		},
+		{
+			name:  "should not limit without max entries",
+			cache: &tmpls.TenantCache{},
+			globs: []string{"a/1", "a/2", "a/3", "a/1"},
+			expected: map[string]int{
+				"a/1": 1,
+				"a/2": 1,
+				"a/3": 1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			fsys := fstest.MapFS{"common/empty.html.tmpl": &fstest.MapFile{}}
+			for _, name := range []string{"a/1", "a/2", "a/3", "b/1", "b/2"} {
+				fsys[name+".html.tmpl"] = &fstest.MapFile{Data: []byte(name)}
+			}
+			counter := &parseCounter{parses: map[string]int{}}
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: fsys,
+					CommonGlob:  "common/*.html.tmpl",
+					Faults:      counter,
+					TenantCache: test.cache,
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, glob := range test.globs {
+				if glob == reload {
+					if err := templates.Reload(); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				file := glob + ".html.tmpl"
+				output, err := templates.Execute(file, path.Base(file), nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if output != glob {
+					t.Fatalf("expected %s but got %s", glob, output)
+				}
+			}
+			for glob, expected := range test.expected {
+				if count := counter.count(glob + ".html.tmpl"); count != expected {
+					t.Fatalf("expected %s parsed %d times but got %d", glob, expected, count)
+				}
+			}
+		})
+	}
+}
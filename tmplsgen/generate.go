@@ -0,0 +1,689 @@
+// Package tmplsgen compiles hot templates ahead of time into Go functions
+// for tmpls.Config.Compiled, trading flexibility for lower render latency.
+//
+// Generation runs from a small Go program, typically behind go:generate, so
+// it can see the types templates are executed with. Only a subset of the
+// template language is supported: text, field and method chains, variables,
+// if, range and with over fields, template calls, and output in HTML text,
+// attribute and URL contexts. Templates using anything else, including funcs,
+// fail with ErrUnsupported and should stay interpreted.
+package tmplsgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"html/template"
+	"io"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template/parse"
+
+	"github.com/fivethirty/tmpls"
+)
+
+// Target selects a template to compile.
+type Target struct {
+	Glob     string
+	Template string
+	// Data is a value of the type the template is executed with. Only its
+	// type is used.
+	Data any
+}
+
+// Options configures the generated file.
+type Options struct {
+	// Package is the name of the generated file's package.
+	Package string
+	// PackagePath is the import path of the generated file's package, so
+	// types declared there are referenced without a qualifier.
+	PackagePath string
+}
+
+// escapers maps the escapers html/template inserts to their equivalents in
+// this package.
+var escapers = map[string]string{
+	"_html_template_htmlescaper":    "EscapeHTML",
+	"_html_template_attrescaper":    "EscapeAttr",
+	"_html_template_rcdataescaper":  "EscapeRCData",
+	"_html_template_nospaceescaper": "EscapeHTMLNoSpace",
+	"_html_template_urlfilter":      "FilterURL",
+	"_html_template_urlnormalizer":  "NormalizeURL",
+	"_html_template_urlescaper":     "EscapeURL",
+}
+
+// Generate writes a Go file declaring
+//
+//	var Compiled = map[tmpls.CompiledKey]tmpls.CompiledFunc{...}
+//
+// with a function for each target, parsed through templates so common
+// templates and funcs match what Execute would use.
+func Generate(
+	w io.Writer,
+	templates *tmpls.Templates,
+	options Options,
+	targets ...Target,
+) error {
+	g := &generator{
+		options: options,
+		imports: map[string]string{},
+		funcs:   map[funcKey]string{},
+	}
+	// reserve the names generated code refers to unqualified
+	g.importAlias("io")
+	g.importAlias("github.com/fivethirty/tmpls")
+	g.importAlias("github.com/fivethirty/tmpls/tmplsgen")
+	var entries strings.Builder
+	for _, target := range targets {
+		entry, err := g.target(templates, target)
+		if err != nil {
+			return fmt.Errorf("%s (%s): %w", target.Template, target.Glob, err)
+		}
+		entries.WriteString(entry)
+	}
+	for len(g.queue) > 0 {
+		pending := g.queue[0]
+		g.queue = g.queue[1:]
+		if err := g.function(pending); err != nil {
+			return fmt.Errorf("%s: %w", pending.key.name, err)
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by tmplsgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", options.Package)
+	out.WriteString("import (\n")
+	var std, other []string
+	for _, imp := range slices.Sorted(maps.Keys(g.imports)) {
+		spec := strconv.Quote(imp)
+		if g.imports[imp] != path.Base(imp) {
+			spec = g.imports[imp] + " " + spec
+		}
+		if strings.Contains(strings.Split(imp, "/")[0], ".") {
+			other = append(other, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	fmt.Fprintf(&out, "%s\n\n%s\n)\n\n", strings.Join(std, "\n"), strings.Join(other, "\n"))
+	out.WriteString("// Compiled holds templates compiled ahead of time, for Config.Compiled.\n")
+	out.WriteString("var Compiled = map[tmpls.CompiledKey]tmpls.CompiledFunc{\n")
+	out.WriteString(entries.String())
+	out.WriteString("}\n")
+	out.WriteString(g.bodies.String())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated code: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type generator struct {
+	options Options
+	imports map[string]string
+	funcs   map[funcKey]string
+	queue   []pendingFunc
+	bodies  strings.Builder
+}
+
+type funcKey struct {
+	set  *template.Template
+	name string
+	typ  reflect.Type
+}
+
+type pendingFunc struct {
+	key  funcKey
+	name string
+}
+
+func (g *generator) target(templates *tmpls.Templates, target Target) (string, error) {
+	typ := reflect.TypeOf(target.Data)
+	if typ == nil {
+		return "", fmt.Errorf("%w: nil data", ErrUnsupported)
+	}
+	executor, err := templates.Parse(target.Glob)
+	if err != nil {
+		return "", err
+	}
+	htmlExecutor, ok := executor.(interface {
+		Template() (*template.Template, error)
+	})
+	if !ok {
+		return "", fmt.Errorf("%w: engine for %s", ErrUnsupported, target.Glob)
+	}
+	set, err := htmlExecutor.Template()
+	if err != nil {
+		return "", err
+	}
+	// executing escapes every template reachable from the target; only
+	// escaping errors matter here, not errors from the zero data
+	if err := set.ExecuteTemplate(io.Discard, target.Template, target.Data); err != nil {
+		var escapeErr *template.Error
+		if errors.As(err, &escapeErr) {
+			return "", err
+		}
+	}
+	fn, err := g.funcFor(set, target.Template, typ)
+	if err != nil {
+		return "", err
+	}
+	typeName, err := g.typeName(typ)
+	if err != nil {
+		return "", err
+	}
+
+	var entry strings.Builder
+	fmt.Fprintf(
+		&entry,
+		"{Glob: %q, Template: %q}: func(w io.Writer, data any) error {\n",
+		target.Glob,
+		target.Template,
+	)
+	fmt.Fprintf(&entry, "var dot %s\nswitch d := data.(type) {\n", typeName)
+	fmt.Fprintf(&entry, "case %s:\ndot = d\n", typeName)
+	if typ.Kind() != reflect.Pointer {
+		fmt.Fprintf(&entry, "case *%s:\nif d == nil {\n", typeName)
+		fmt.Fprintf(&entry, "return tmplsgen.TypeError(%q, data)\n}\ndot = *d\n", typeName)
+	}
+	fmt.Fprintf(&entry, "default:\nreturn tmplsgen.TypeError(%q, data)\n}\n", typeName)
+	fmt.Fprintf(&entry, "out := tmplsgen.NewWriter(w)\nif err := %s(out, dot); err != nil {\n", fn)
+	entry.WriteString("return err\n}\nreturn out.Err()\n},\n")
+	return entry.String(), nil
+}
+
+// funcFor returns the name of the function rendering the template name from
+// set with data of type typ, queueing it for generation if needed.
+func (g *generator) funcFor(set *template.Template, name string, typ reflect.Type) (string, error) {
+	key := funcKey{set: set, name: name, typ: typ}
+	if fn, ok := g.funcs[key]; ok {
+		return fn, nil
+	}
+	if set.Lookup(name) == nil || set.Lookup(name).Tree == nil {
+		return "", fmt.Errorf("template %q is undefined", name)
+	}
+	fn := fmt.Sprintf("render%d", len(g.funcs)+1)
+	g.funcs[key] = fn
+	g.queue = append(g.queue, pendingFunc{key: key, name: fn})
+	return fn, nil
+}
+
+func (g *generator) function(pending pendingFunc) error {
+	typeName, err := g.typeName(pending.key.typ)
+	if err != nil {
+		return err
+	}
+	f := &funcGen{
+		generator: g,
+		set:       pending.key.set,
+		dot:       value{expr: "dot", typ: pending.key.typ},
+		scopes:    []map[string]value{{"$": {expr: "dot", typ: pending.key.typ}}},
+	}
+	if err := f.node(pending.key.set.Lookup(pending.key.name).Tree.Root); err != nil {
+		return err
+	}
+	fmt.Fprintf(
+		&g.bodies,
+		"\n// %s renders %q.\nfunc %s(w *tmplsgen.Writer, dot %s) error {\n%sreturn nil\n}\n",
+		pending.name,
+		pending.key.name,
+		pending.name,
+		typeName,
+		f.body.String(),
+	)
+	return nil
+}
+
+func (g *generator) typeName(typ reflect.Type) (string, error) {
+	if typ.Name() != "" {
+		if typ.PkgPath() == "" {
+			return typ.Name(), nil
+		}
+		if typ.PkgPath() == "main" {
+			return "", fmt.Errorf("%w: type %s is declared in package main", ErrUnsupported, typ)
+		}
+		if typ.PkgPath() == g.options.PackagePath {
+			return typ.Name(), nil
+		}
+		return g.importAlias(typ.PkgPath()) + "." + typ.Name(), nil
+	}
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Slice:
+		elem, err := g.typeName(typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		if typ.Kind() == reflect.Pointer {
+			return "*" + elem, nil
+		}
+		return "[]" + elem, nil
+	case reflect.Array:
+		elem, err := g.typeName(typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("[%d]%s", typ.Len(), elem), nil
+	case reflect.Map:
+		key, err := g.typeName(typ.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := g.typeName(typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "map[" + key + "]" + elem, nil
+	default:
+		return "", fmt.Errorf("%w: unnamed type %s", ErrUnsupported, typ)
+	}
+}
+
+func (g *generator) importAlias(pkgPath string) string {
+	if alias, ok := g.imports[pkgPath]; ok {
+		return alias
+	}
+	base := strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return '_'
+		}
+		return r
+	}, path.Base(pkgPath))
+	alias := base
+	for i := 2; slices.Contains(slices.Collect(maps.Values(g.imports)), alias); i++ {
+		alias = base + strconv.Itoa(i)
+	}
+	g.imports[pkgPath] = alias
+	return alias
+}
+
+// value is a Go expression, always a local variable, and its type.
+type value struct {
+	expr string
+	typ  reflect.Type
+}
+
+type funcGen struct {
+	*generator
+	set    *template.Template
+	body   strings.Builder
+	dot    value
+	scopes []map[string]value
+	vars   int
+}
+
+func (f *funcGen) emit(format string, args ...any) {
+	fmt.Fprintf(&f.body, format+"\n", args...)
+}
+
+func (f *funcGen) declare(expr string, typ reflect.Type) value {
+	f.vars++
+	v := value{expr: "v" + strconv.Itoa(f.vars), typ: typ}
+	f.emit("%s := %s", v.expr, expr)
+	return v
+}
+
+func unsupported(node parse.Node) error {
+	return fmt.Errorf("%w: %s", ErrUnsupported, node)
+}
+
+func (f *funcGen) node(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := f.node(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *parse.TextNode:
+		f.emit("w.WriteString(%s)", strconv.Quote(string(n.Text)))
+		return nil
+	case *parse.CommentNode:
+		return nil
+	case *parse.ActionNode:
+		return f.action(n)
+	case *parse.IfNode:
+		return f.ifNode(n)
+	case *parse.RangeNode:
+		return f.rangeNode(n)
+	case *parse.WithNode:
+		return f.withNode(n)
+	case *parse.TemplateNode:
+		return f.templateNode(n)
+	default:
+		return unsupported(node)
+	}
+}
+
+// countsInclude reports whether n is the call counting an include that
+// engines insert before template and block actions.
+func countsInclude(n *parse.ActionNode) bool {
+	if len(n.Pipe.Decl) != 1 || len(n.Pipe.Cmds) != 1 || len(n.Pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	ident, ok := n.Pipe.Cmds[0].Args[0].(*parse.IdentifierNode)
+	return ok && ident.Ident == "meterInclude"
+}
+
+func (f *funcGen) action(n *parse.ActionNode) error {
+	if countsInclude(n) {
+		// compiled functions run without a meter to count includes for
+		return nil
+	}
+	if len(n.Pipe.Decl) > 0 {
+		if len(n.Pipe.Decl) != 1 || len(n.Pipe.Cmds) != 1 || n.Pipe.IsAssign {
+			return unsupported(n)
+		}
+		v, err := f.command(n.Pipe.Cmds[0])
+		if err != nil {
+			return err
+		}
+		f.emit("_ = %s", v.expr)
+		f.scopes[len(f.scopes)-1][n.Pipe.Decl[0].Ident[0]] = v
+		return nil
+	}
+	if len(n.Pipe.Cmds) < 2 {
+		return unsupported(n)
+	}
+	v, err := f.command(n.Pipe.Cmds[0])
+	if err != nil {
+		return err
+	}
+	out := v.expr
+	for _, cmd := range n.Pipe.Cmds[1:] {
+		ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+		if !ok || len(cmd.Args) != 1 || escapers[ident.Ident] == "" {
+			return unsupported(n)
+		}
+		out = fmt.Sprintf("tmplsgen.%s(%s)", escapers[ident.Ident], out)
+	}
+	f.emit("w.WriteString(%s)", out)
+	return nil
+}
+
+func (f *funcGen) ifNode(n *parse.IfNode) error {
+	cond, err := f.condition(n.Pipe)
+	if err != nil {
+		return err
+	}
+	f.emit("if %s {", cond)
+	if err := f.scoped(f.dot, n.List); err != nil {
+		return err
+	}
+	if n.ElseList != nil {
+		f.emit("} else {")
+		if err := f.scoped(f.dot, n.ElseList); err != nil {
+			return err
+		}
+	}
+	f.emit("}")
+	return nil
+}
+
+func (f *funcGen) withNode(n *parse.WithNode) error {
+	v, err := f.pipe(n.Pipe)
+	if err != nil {
+		return err
+	}
+	f.emit("if %s {", truth(v))
+	if err := f.scoped(v, n.List); err != nil {
+		return err
+	}
+	if n.ElseList != nil {
+		f.emit("} else {")
+		if err := f.scoped(f.dot, n.ElseList); err != nil {
+			return err
+		}
+	}
+	f.emit("}")
+	return nil
+}
+
+func (f *funcGen) rangeNode(n *parse.RangeNode) error {
+	if len(n.Pipe.Decl) > 2 || n.Pipe.IsAssign {
+		return unsupported(n)
+	}
+	decls := n.Pipe.Decl
+	n.Pipe.Decl = nil
+	v, err := f.pipe(n.Pipe)
+	n.Pipe.Decl = decls
+	if err != nil {
+		return err
+	}
+
+	f.vars++
+	index, elem := "i"+strconv.Itoa(f.vars), "e"+strconv.Itoa(f.vars)
+	var indexType reflect.Type
+	switch v.typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		indexType = reflect.TypeFor[int]()
+		f.emit("for %s, %s := range %s {", index, elem, v.expr)
+	case reflect.Map:
+		// like text/template, visit maps in key order
+		switch v.typ.Key().Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64, reflect.Float32, reflect.Float64:
+		default:
+			return unsupported(n)
+		}
+		g := f.generator
+		indexType = v.typ.Key()
+		f.emit(
+			"for _, %s := range %s.Sorted(%s.Keys(%s)) {",
+			index,
+			g.importAlias("slices"),
+			g.importAlias("maps"),
+			v.expr,
+		)
+		f.emit("%s := %s[%s]", elem, v.expr, index)
+	default:
+		return unsupported(n)
+	}
+	f.emit("_, _ = %s, %s", index, elem)
+
+	f.scopes = append(f.scopes, map[string]value{})
+	scope := f.scopes[len(f.scopes)-1]
+	switch len(decls) {
+	case 1:
+		scope[decls[0].Ident[0]] = value{expr: elem, typ: v.typ.Elem()}
+	case 2:
+		scope[decls[0].Ident[0]] = value{expr: index, typ: indexType}
+		scope[decls[1].Ident[0]] = value{expr: elem, typ: v.typ.Elem()}
+	}
+	err = f.scoped(value{expr: elem, typ: v.typ.Elem()}, n.List)
+	f.scopes = f.scopes[:len(f.scopes)-1]
+	if err != nil {
+		return err
+	}
+	f.emit("}")
+
+	if n.ElseList != nil {
+		f.emit("if len(%s) == 0 {", v.expr)
+		if err := f.scoped(f.dot, n.ElseList); err != nil {
+			return err
+		}
+		f.emit("}")
+	}
+	return nil
+}
+
+func (f *funcGen) templateNode(n *parse.TemplateNode) error {
+	if n.Pipe == nil {
+		return unsupported(n)
+	}
+	v, err := f.pipe(n.Pipe)
+	if err != nil {
+		return err
+	}
+	fn, err := f.funcFor(f.set, n.Name, v.typ)
+	if err != nil {
+		return err
+	}
+	f.emit("if err := %s(w, %s); err != nil {\nreturn err\n}", fn, v.expr)
+	return nil
+}
+
+// scoped generates list with dot set to v in a new variable scope.
+func (f *funcGen) scoped(v value, list *parse.ListNode) error {
+	dot := f.dot
+	f.dot = v
+	f.scopes = append(f.scopes, map[string]value{})
+	err := f.node(list)
+	f.scopes = f.scopes[:len(f.scopes)-1]
+	f.dot = dot
+	return err
+}
+
+// condition generates a boolean expression for an if pipeline, which may be
+// negated with not.
+func (f *funcGen) condition(pipe *parse.PipeNode) (string, error) {
+	if len(pipe.Decl) == 0 && len(pipe.Cmds) == 1 && len(pipe.Cmds[0].Args) == 2 {
+		if ident, ok := pipe.Cmds[0].Args[0].(*parse.IdentifierNode); ok && ident.Ident == "not" {
+			v, err := f.operand(pipe.Cmds[0].Args[1])
+			if err != nil {
+				return "", err
+			}
+			return "!(" + truth(v) + ")", nil
+		}
+	}
+	v, err := f.pipe(pipe)
+	if err != nil {
+		return "", err
+	}
+	return truth(v), nil
+}
+
+func (f *funcGen) pipe(pipe *parse.PipeNode) (value, error) {
+	if len(pipe.Decl) > 0 || len(pipe.Cmds) != 1 {
+		return value{}, unsupported(pipe)
+	}
+	return f.command(pipe.Cmds[0])
+}
+
+func (f *funcGen) command(cmd *parse.CommandNode) (value, error) {
+	if len(cmd.Args) != 1 {
+		return value{}, unsupported(cmd)
+	}
+	return f.operand(cmd.Args[0])
+}
+
+func (f *funcGen) operand(node parse.Node) (value, error) {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return f.dot, nil
+	case *parse.FieldNode:
+		return f.fields(f.dot, n.Ident)
+	case *parse.VariableNode:
+		v, ok := f.variable(n.Ident[0])
+		if !ok {
+			return value{}, fmt.Errorf("undefined variable %s", n.Ident[0])
+		}
+		return f.fields(v, n.Ident[1:])
+	case *parse.StringNode:
+		return f.declare(strconv.Quote(n.Text), reflect.TypeFor[string]()), nil
+	case *parse.BoolNode:
+		return f.declare(strconv.FormatBool(n.True), reflect.TypeFor[bool]()), nil
+	case *parse.NumberNode:
+		if n.IsInt {
+			return f.declare(strconv.FormatInt(n.Int64, 10), reflect.TypeFor[int]()), nil
+		}
+		if n.IsFloat {
+			return f.declare(n.Text, reflect.TypeFor[float64]()), nil
+		}
+		return value{}, unsupported(n)
+	default:
+		return value{}, unsupported(node)
+	}
+}
+
+func (f *funcGen) variable(name string) (value, bool) {
+	for i := len(f.scopes) - 1; i >= 0; i-- {
+		if v, ok := f.scopes[i][name]; ok {
+			return v, true
+		}
+	}
+	return value{}, false
+}
+
+func (f *funcGen) fields(v value, names []string) (value, error) {
+	for _, name := range names {
+		var err error
+		if v, err = f.field(v, name); err != nil {
+			return value{}, err
+		}
+	}
+	return v, nil
+}
+
+// field generates .name on v the way text/template evaluates it: methods
+// first, then struct fields or map keys through any pointers.
+func (f *funcGen) field(v value, name string) (value, error) {
+	if method, ok := v.typ.MethodByName(name); ok {
+		mt := method.Type
+		if mt.NumIn() != 1 || mt.NumOut() == 0 || mt.NumOut() > 2 ||
+			(mt.NumOut() == 2 && mt.Out(1) != errorType) {
+			return value{}, fmt.Errorf("%w: method %s.%s", ErrUnsupported, v.typ, name)
+		}
+		if mt.NumOut() == 1 {
+			return f.declare(v.expr+"."+name+"()", mt.Out(0)), nil
+		}
+		f.vars++
+		result := value{expr: "v" + strconv.Itoa(f.vars), typ: mt.Out(0)}
+		f.emit("%s, err := %s.%s()\nif err != nil {\nreturn err\n}", result.expr, v.expr, name)
+		return result, nil
+	}
+
+	typ := v.typ
+	for typ.Kind() == reflect.Pointer {
+		f.emit("if %s == nil {\nreturn tmplsgen.NilError(%q)\n}", v.expr, name)
+		typ = typ.Elem()
+		if typ.Kind() == reflect.Pointer {
+			v = f.declare("*"+v.expr, typ)
+		}
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		field, ok := typ.FieldByName(name)
+		if !ok || !field.IsExported() {
+			return value{}, fmt.Errorf("can't evaluate field %s in type %s", name, typ)
+		}
+		if field.Type.Kind() == reflect.Interface {
+			return value{}, fmt.Errorf("%w: field %s has interface type", ErrUnsupported, name)
+		}
+		return f.declare(v.expr+"."+name, field.Type), nil
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String || typ.Elem().Kind() == reflect.Interface {
+			return value{}, fmt.Errorf("%w: map key %s in type %s", ErrUnsupported, name, typ)
+		}
+		return f.declare(fmt.Sprintf("%s[%q]", v.expr, name), typ.Elem()), nil
+	default:
+		return value{}, fmt.Errorf("can't evaluate field %s in type %s", name, typ)
+	}
+}
+
+// truth generates text/template's notion of a non-empty value.
+func truth(v value) string {
+	switch v.typ.Kind() {
+	case reflect.Bool:
+		return v.expr
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array, reflect.Chan:
+		return "len(" + v.expr + ") > 0"
+	case reflect.Pointer, reflect.Interface, reflect.Func:
+		return v.expr + " != nil"
+	case reflect.Struct:
+		return "true"
+	default:
+		return v.expr + " != 0"
+	}
+}
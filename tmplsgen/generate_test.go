@@ -0,0 +1,159 @@
+package tmplsgen_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/fs"
+	"log/slog"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/fivethirty/tmpls/tmplsgen"
+	"github.com/fivethirty/tmpls/tmplsgen/internal/example"
+)
+
+var update = flag.Bool("update", false, "rewrite internal/example/compiled.go")
+
+func exampleTemplates(
+	t *testing.T,
+	compiled map[tmpls.CompiledKey]tmpls.CompiledFunc,
+) *tmpls.Templates {
+	t.Helper()
+	templatesFS, err := fs.Sub(example.FS, "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: templatesFS,
+			CommonGlob:  "common/*.html.tmpl",
+			Compiled:    compiled,
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return templates
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	var generated bytes.Buffer
+	err := tmplsgen.Generate(
+		&generated,
+		exampleTemplates(t, nil),
+		tmplsgen.Options{
+			Package:     "example",
+			PackagePath: "github.com/fivethirty/tmpls/tmplsgen/internal/example",
+		},
+		tmplsgen.Target{Glob: "page.html.tmpl", Template: "page.html.tmpl", Data: example.Page{}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const golden = "internal/example/compiled.go"
+	if *update {
+		if err := os.WriteFile(golden, generated.Bytes(), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(generated.Bytes(), expected) {
+		t.Fatalf("%s is stale, run go generate ./...", golden)
+	}
+}
+
+func TestCompiledMatchesInterpreted(t *testing.T) {
+	t.Parallel()
+
+	interpreted := exampleTemplates(t, nil)
+	compiled := exampleTemplates(t, example.Compiled)
+
+	tests := []struct {
+		name string
+		data any
+	}{
+		{
+			name: "should match with full data",
+			data: example.Page{
+				Title: `Tom & "Jerry's" <shop>`,
+				User:  &example.User{Name: "tom cat", Admin: true},
+				Items: []example.Item{
+					{Name: "a b", Price: 1.5, Description: "<b>bold</b>"},
+					{Name: "c+d", Price: 2, Description: "plain"},
+				},
+				Tags: map[string]int{"z": 1, "a": 2},
+				Link: "https://example.com/a b?x=<y>",
+			},
+		},
+		{
+			name: "should match with empty data",
+			data: &example.Page{Link: "javascript:alert(1)"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			expected, err := interpreted.Execute("page.html.tmpl", "page.html.tmpl", test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			output, err := compiled.Execute("page.html.tmpl", "page.html.tmpl", test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != expected {
+				t.Fatalf("expected %s but got %s", expected, output)
+			}
+		})
+	}
+}
+
+func TestCompiledTypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	compiled := exampleTemplates(t, example.Compiled)
+	_, err := compiled.Execute("page.html.tmpl", "page.html.tmpl", "not a page")
+	if !tmpls.IsExecError(err) {
+		t.Fatalf("expected exec error but got %v", err)
+	}
+}
+
+func TestGenerateUnsupported(t *testing.T) {
+	t.Parallel()
+
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: fstest.MapFS{
+				"page.html.tmpl":         &fstest.MapFile{Data: []byte(`{{ now }}`)},
+				"common/empty.html.tmpl": &fstest.MapFile{},
+			},
+			CommonGlob: "common/*.html.tmpl",
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var generated bytes.Buffer
+	err = tmplsgen.Generate(
+		&generated,
+		templates,
+		tmplsgen.Options{Package: "views"},
+		tmplsgen.Target{Glob: "page.html.tmpl", Template: "page.html.tmpl", Data: example.Page{}},
+	)
+	if !errors.Is(err, tmplsgen.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported but got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+// Code generated by tmplsgen. DO NOT EDIT.
+
+package example
+
+import (
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/fivethirty/tmpls/tmplsgen"
+)
+
+// Compiled holds templates compiled ahead of time, for Config.Compiled.
+var Compiled = map[tmpls.CompiledKey]tmpls.CompiledFunc{
+	{Glob: "page.html.tmpl", Template: "page.html.tmpl"}: func(w io.Writer, data any) error {
+		var dot Page
+		switch d := data.(type) {
+		case Page:
+			dot = d
+		case *Page:
+			if d == nil {
+				return tmplsgen.TypeError("Page", data)
+			}
+			dot = *d
+		default:
+			return tmplsgen.TypeError("Page", data)
+		}
+		out := tmplsgen.NewWriter(w)
+		if err := render1(out, dot); err != nil {
+			return err
+		}
+		return out.Err()
+	},
+}
+
+// render1 renders "page.html.tmpl".
+func render1(w *tmplsgen.Writer, dot Page) error {
+	if err := render2(w, dot); err != nil {
+		return err
+	}
+	w.WriteString("\n")
+	w.WriteString("\n")
+	return nil
+}
+
+// render2 renders "layout.html.tmpl".
+func render2(w *tmplsgen.Writer, dot Page) error {
+	w.WriteString("<!doctype html>\n<title>")
+	v1 := dot.Title
+	w.WriteString(tmplsgen.EscapeRCData(v1))
+	w.WriteString("</title>\n<main>")
+	if err := render3(w, dot); err != nil {
+		return err
+	}
+	w.WriteString("</main>\n")
+	w.WriteString("\n")
+	return nil
+}
+
+// render3 renders "content".
+func render3(w *tmplsgen.Writer, dot Page) error {
+	v1 := dot.User
+	if v1 != nil {
+		if err := render4(w, v1); err != nil {
+			return err
+		}
+		if v1 == nil {
+			return tmplsgen.NilError("Admin")
+		}
+		v2 := v1.Admin
+		if v2 {
+			w.WriteString(" (admin)")
+		}
+	} else {
+		w.WriteString("anonymous")
+	}
+	w.WriteString("\n<a href=\"")
+	v3 := dot.Link
+	w.WriteString(tmplsgen.EscapeAttr(tmplsgen.NormalizeURL(tmplsgen.FilterURL(v3))))
+	w.WriteString("\">link</a>\n<ul>")
+	v4 := dot.Items
+	for i5, e5 := range v4 {
+		_, _ = i5, e5
+		w.WriteString("\n  <li class=")
+		v6 := e5.Name
+		w.WriteString(tmplsgen.EscapeHTMLNoSpace(v6))
+		w.WriteString(">")
+		w.WriteString(tmplsgen.EscapeHTML(i5))
+		w.WriteString(": ")
+		v7 := e5.Name
+		w.WriteString(tmplsgen.EscapeHTML(v7))
+		w.WriteString(" ")
+		v8 := e5.Price
+		w.WriteString(tmplsgen.EscapeHTML(v8))
+		w.WriteString(" ")
+		v9 := e5.Description
+		w.WriteString(tmplsgen.EscapeHTML(v9))
+		w.WriteString("</li>")
+	}
+	if len(v4) == 0 {
+		w.WriteString("\n  <li>none</li>")
+	}
+	w.WriteString("\n</ul>\n")
+	v10 := dot.Tags
+	for _, i11 := range slices.Sorted(maps.Keys(v10)) {
+		e11 := v10[i11]
+		_, _ = i11, e11
+		w.WriteString(tmplsgen.EscapeHTML(i11))
+		w.WriteString("=")
+		w.WriteString(tmplsgen.EscapeHTML(e11))
+		w.WriteString(" ")
+	}
+	v12 := dot.Title
+	_ = v12
+	w.WriteString("\n")
+	v13 := dot.Items
+	if !(len(v13) > 0) {
+		w.WriteString("empty ")
+		w.WriteString(tmplsgen.EscapeHTML(v12))
+	}
+	return nil
+}
+
+// render4 renders "user".
+func render4(w *tmplsgen.Writer, dot *User) error {
+	w.WriteString("<a href=\"/users/")
+	if dot == nil {
+		return tmplsgen.NilError("Name")
+	}
+	v1 := dot.Name
+	w.WriteString(tmplsgen.EscapeAttr(tmplsgen.NormalizeURL(v1)))
+	w.WriteString("?q=")
+	if dot == nil {
+		return tmplsgen.NilError("Name")
+	}
+	v2 := dot.Name
+	w.WriteString(tmplsgen.EscapeAttr(tmplsgen.EscapeURL(v2)))
+	w.WriteString("\" title=\"")
+	v3 := dot.Greeting()
+	w.WriteString(tmplsgen.EscapeAttr(v3))
+	w.WriteString("\">")
+	if dot == nil {
+		return tmplsgen.NilError("Name")
+	}
+	v4 := dot.Name
+	w.WriteString(tmplsgen.EscapeHTML(v4))
+	w.WriteString("</a>")
+	return nil
+}
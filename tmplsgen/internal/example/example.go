@@ -0,0 +1,35 @@
+// Package example holds templates and data compiled by the tmplsgen tests.
+package example
+
+import (
+	"embed"
+	"html/template"
+)
+
+//go:generate go test .. -run TestGenerate -update
+
+//go:embed templates
+var FS embed.FS
+
+type Page struct {
+	Title string
+	User  *User
+	Items []Item
+	Tags  map[string]int
+	Link  string
+}
+
+type User struct {
+	Name  string
+	Admin bool
+}
+
+func (u *User) Greeting() string {
+	return "Hello, " + u.Name + "!"
+}
+
+type Item struct {
+	Name        string
+	Price       float64
+	Description template.HTML
+}
@@ -0,0 +1,260 @@
+package tmplsgen
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// The funcs in this file are called by generated code. They mirror the
+// escapers html/template inserts into templates, which are unexported.
+
+// Writer writes generated output, remembering the first error so generated
+// code only has to check once at the end.
+type Writer struct {
+	w   io.Writer
+	err error
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) WriteString(s string) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = io.WriteString(w.w, s)
+}
+
+func (w *Writer) Err() error {
+	return w.err
+}
+
+// NilError reports evaluating a field or method through a nil pointer.
+func NilError(name string) error {
+	return fmt.Errorf("nil pointer evaluating %s", name)
+}
+
+// TypeError reports executing a compiled template with data of the wrong type.
+func TypeError(expected string, data any) error {
+	return fmt.Errorf("compiled template expects %s but got %T", expected, data)
+}
+
+// ErrUnsupported is returned by Generate for templates using features the
+// code generator can't compile. Those templates should stay interpreted.
+var ErrUnsupported = errors.New("unsupported by tmplsgen")
+
+func EscapeHTML(v any) string {
+	s, html := stringify(v)
+	if html {
+		return s
+	}
+	return replace(s, htmlReplacements, true)
+}
+
+// EscapeAttr escapes a value for a quoted attribute. Unlike html/template,
+// it escapes template.HTML values instead of stripping their tags.
+func EscapeAttr(v any) string {
+	s, _ := stringify(v)
+	return replace(s, htmlReplacements, true)
+}
+
+// EscapeRCData escapes a value inside RCDATA elements such as title.
+func EscapeRCData(v any) string {
+	s, html := stringify(v)
+	if html {
+		return replace(s, htmlNormReplacements, true)
+	}
+	return replace(s, htmlReplacements, true)
+}
+
+// EscapeHTMLNoSpace escapes a value for an unquoted attribute. Unlike
+// html/template, it escapes template.HTML values instead of stripping their
+// tags.
+func EscapeHTMLNoSpace(v any) string {
+	s, _ := stringify(v)
+	if s == "" {
+		return filterFailsafe
+	}
+	return replace(s, htmlNoSpaceReplacements, false)
+}
+
+func FilterURL(v any) string {
+	s, isURL := stringifyURL(v)
+	if isURL {
+		return s
+	}
+	if protocol, _, ok := strings.Cut(s, ":"); ok && !strings.Contains(protocol, "/") {
+		if !strings.EqualFold(protocol, "http") &&
+			!strings.EqualFold(protocol, "https") &&
+			!strings.EqualFold(protocol, "mailto") {
+			return "#" + filterFailsafe
+		}
+	}
+	return s
+}
+
+func NormalizeURL(v any) string {
+	s, _ := stringifyURL(v)
+	return processURL(s, true)
+}
+
+func EscapeURL(v any) string {
+	s, isURL := stringifyURL(v)
+	return processURL(s, isURL)
+}
+
+const filterFailsafe = "ZgotmplZ"
+
+var htmlReplacements = []string{
+	0:    "\uFFFD",
+	'"':  "&#34;",
+	'&':  "&amp;",
+	'\'': "&#39;",
+	'+':  "&#43;",
+	'<':  "&lt;",
+	'>':  "&gt;",
+}
+
+var htmlNormReplacements = []string{
+	0:    "\uFFFD",
+	'"':  "&#34;",
+	'\'': "&#39;",
+	'+':  "&#43;",
+	'<':  "&lt;",
+	'>':  "&gt;",
+}
+
+var htmlNoSpaceReplacements = []string{
+	0:    "&#xfffd;",
+	'\t': "&#9;",
+	'\n': "&#10;",
+	'\v': "&#11;",
+	'\f': "&#12;",
+	'\r': "&#13;",
+	' ':  "&#32;",
+	'"':  "&#34;",
+	'&':  "&amp;",
+	'\'': "&#39;",
+	'+':  "&#43;",
+	'<':  "&lt;",
+	'=':  "&#61;",
+	'>':  "&gt;",
+	'`':  "&#96;",
+}
+
+func stringify(v any) (string, bool) {
+	switch s := indirect(v).(type) {
+	case string:
+		return s, false
+	case template.HTML:
+		return string(s), true
+	case nil:
+		return "", false
+	}
+	return fmt.Sprint(indirectToStringerOrError(v)), false
+}
+
+func stringifyURL(v any) (string, bool) {
+	if u, ok := indirect(v).(template.URL); ok {
+		return string(u), true
+	}
+	s, _ := stringify(v)
+	return s, false
+}
+
+func indirect(v any) any {
+	if v == nil {
+		return nil
+	}
+	if t := reflect.TypeOf(v); t.Kind() != reflect.Pointer {
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv.Interface()
+}
+
+var (
+	stringerType = reflect.TypeFor[fmt.Stringer]()
+	errorType    = reflect.TypeFor[error]()
+)
+
+func indirectToStringerOrError(v any) any {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	for !rv.Type().Implements(stringerType) &&
+		!rv.Type().Implements(errorType) &&
+		rv.Kind() == reflect.Pointer &&
+		!rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv.Interface()
+}
+
+func replace(s string, replacements []string, badRunes bool) string {
+	var b strings.Builder
+	written := 0
+	for i := 0; i < len(s); {
+		r, width := utf8.DecodeRuneInString(s[i:])
+		if int(r) < len(replacements) {
+			if repl := replacements[r]; repl != "" {
+				b.WriteString(s[written:i])
+				b.WriteString(repl)
+				written = i + width
+			}
+		} else if !badRunes && (0xfdd0 <= r && r <= 0xfdef || 0xfff0 <= r && r <= 0xffff) {
+			fmt.Fprintf(&b, "%s&#x%x;", s[written:i], r)
+			written = i + width
+		}
+		i += width
+	}
+	if written == 0 {
+		return s
+	}
+	b.WriteString(s[written:])
+	return b.String()
+}
+
+func processURL(s string, norm bool) string {
+	var b strings.Builder
+	written := 0
+	for i := range len(s) {
+		c := s[i]
+		switch {
+		case strings.IndexByte("!#$&*+,/:;=?@[]", c) >= 0:
+			if norm {
+				continue
+			}
+		case strings.IndexByte("-._~", c) >= 0:
+			continue
+		case c == '%':
+			if norm && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+				continue
+			}
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+			continue
+		}
+		b.WriteString(s[written:i])
+		fmt.Fprintf(&b, "%%%02x", c)
+		written = i + 1
+	}
+	if written == 0 {
+		return s
+	}
+	b.WriteString(s[written:])
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
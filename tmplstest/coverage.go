@@ -0,0 +1,212 @@
+// Package tmplstest provides helpers for testing applications built on tmpls.
+package tmplstest
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+	"text/template/parse"
+
+	"github.com/fivethirty/tmpls"
+)
+
+// Coverage lists which template files were exercised by recorded executions.
+// A file counts as covered when any template it defines was executed or is
+// referenced, directly or transitively, by an executed template.
+type Coverage struct {
+	Covered   []string
+	Uncovered []string
+}
+
+// Measure computes coverage for the files in fsys matching patterns, or every
+// file in fsys when no patterns are given, from usage recorded by a Templates
+// created with Config.TrackUsage.
+func Measure(fsys fs.FS, usage []tmpls.Usage, patterns ...string) (Coverage, error) {
+	files, err := listFiles(fsys, patterns)
+	if err != nil {
+		return Coverage{}, err
+	}
+
+	// definers maps each template name to the files defining it, and
+	// references maps each file and name to the names its tree references.
+	definers := map[string][]string{}
+	references := map[string]map[string][]string{}
+	for _, file := range files {
+		trees, err := parseFile(fsys, file)
+		if err != nil {
+			return Coverage{}, err
+		}
+		references[file] = map[string][]string{}
+		for name, tree := range trees {
+			definers[name] = append(definers[name], file)
+			references[file][name] = templateReferences(tree.Root)
+		}
+	}
+
+	covered := map[string]bool{}
+	for _, u := range usage {
+		// files matched by the executed glob override common definitions
+		resolve := func(name string) []string {
+			var matched []string
+			for _, file := range definers[name] {
+				if ok, _ := path.Match(u.Glob, file); ok {
+					matched = append(matched, file)
+				}
+			}
+			if len(matched) > 0 {
+				return matched
+			}
+			return definers[name]
+		}
+		reached := map[string]bool{}
+		queue := []string{u.Template}
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			if reached[name] {
+				continue
+			}
+			reached[name] = true
+			for _, file := range resolve(name) {
+				covered[file] = true
+				queue = append(queue, references[file][name]...)
+			}
+		}
+	}
+
+	var coverage Coverage
+	for _, file := range files {
+		if covered[file] {
+			coverage.Covered = append(coverage.Covered, file)
+		} else {
+			coverage.Uncovered = append(coverage.Uncovered, file)
+		}
+	}
+	return coverage, nil
+}
+
+// Ratio returns the covered fraction of files, or 1 when there are no files.
+func (c Coverage) Ratio() float64 {
+	total := len(c.Covered) + len(c.Uncovered)
+	if total == 0 {
+		return 1
+	}
+	return float64(len(c.Covered)) / float64(total)
+}
+
+// Check returns an error listing uncovered files if the ratio is below min.
+func (c Coverage) Check(minRatio float64) error {
+	if c.Ratio() >= minRatio {
+		return nil
+	}
+	return fmt.Errorf(
+		"template coverage %.1f%% is below %.1f%%, never executed:\n\t%s",
+		c.Ratio()*100,
+		minRatio*100,
+		strings.Join(c.Uncovered, "\n\t"),
+	)
+}
+
+func (c Coverage) String() string {
+	return fmt.Sprintf(
+		"template coverage: %.1f%% of %d files",
+		c.Ratio()*100,
+		len(c.Covered)+len(c.Uncovered),
+	)
+}
+
+// Run is meant to be called from TestMain. It runs the tests, then measures
+// coverage of fsys using the usage recorded by templates, prints a report and
+// fails the run if coverage is below minRatio. A minRatio of 0 only reports.
+func Run(
+	m *testing.M,
+	templates *tmpls.Templates,
+	fsys fs.FS,
+	minRatio float64,
+	patterns ...string,
+) int {
+	code := m.Run()
+	if code != 0 {
+		return code
+	}
+	coverage, err := Measure(fsys, templates.Usage(), patterns...)
+	if err == nil {
+		fmt.Println(coverage)
+		err = coverage.Check(minRatio)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func listFiles(fsys fs.FS, patterns []string) ([]string, error) {
+	var files []string
+	if len(patterns) == 0 {
+		err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() {
+				files = append(files, p)
+			}
+			return nil
+		})
+		return files, err
+	}
+	for _, pattern := range patterns {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+func parseFile(fsys fs.FS, file string) (map[string]*parse.Tree, error) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	// like template.ParseFS, the file's own template is named by its base name
+	tree := parse.New(path.Base(file))
+	tree.Mode = parse.SkipFuncCheck
+	trees := map[string]*parse.Tree{}
+	if _, err := tree.Parse(string(data), "", "", trees); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return trees, nil
+}
+
+func templateReferences(node parse.Node) []string {
+	var names []string
+	switch n := node.(type) {
+	case *parse.TemplateNode:
+		names = append(names, n.Name)
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			names = append(names, templateReferences(child)...)
+		}
+	case *parse.IfNode:
+		names = append(names, branchReferences(&n.BranchNode)...)
+	case *parse.RangeNode:
+		names = append(names, branchReferences(&n.BranchNode)...)
+	case *parse.WithNode:
+		names = append(names, branchReferences(&n.BranchNode)...)
+	}
+	return names
+}
+
+func branchReferences(n *parse.BranchNode) []string {
+	return append(templateReferences(n.List), templateReferences(n.ElseList)...)
+}
@@ -0,0 +1,126 @@
+package tmplstest_test
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/fivethirty/tmpls/tmplstest"
+)
+
+var coverageFS = fstest.MapFS{
+	"home.html.tmpl": &fstest.MapFile{
+		Data: []byte(`{{ template "layout.html.tmpl" . }}{{ define "content" }}home{{ end }}`),
+	},
+	"about.html.tmpl": &fstest.MapFile{
+		Data: []byte(`{{ template "layout.html.tmpl" . }}{{ define "content" }}about{{ end }}`),
+	},
+	"common/layout.html.tmpl": &fstest.MapFile{
+		Data: []byte(`<main>{{ template "content" . }}{{ template "footer" }}</main>`),
+	},
+	"common/footer.html.tmpl": &fstest.MapFile{
+		Data: []byte(`{{ define "footer" }}<footer></footer>{{ end }}`),
+	},
+	"common/unused.html.tmpl": &fstest.MapFile{
+		Data: []byte(`{{ define "unused" }}{{ end }}`),
+	},
+}
+
+func TestMeasure(t *testing.T) {
+	t.Parallel()
+
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: coverageFS,
+			CommonGlob:  "common/*.html.tmpl",
+			TrackUsage:  true,
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := templates.Execute("home.html.tmpl", "home.html.tmpl", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		expected tmplstest.Coverage
+	}{
+		{
+			name: "should measure every file",
+			expected: tmplstest.Coverage{
+				Covered: []string{
+					"common/footer.html.tmpl",
+					"common/layout.html.tmpl",
+					"home.html.tmpl",
+				},
+				Uncovered: []string{
+					"about.html.tmpl",
+					"common/unused.html.tmpl",
+				},
+			},
+		},
+		{
+			name:     "should measure only matching files",
+			patterns: []string{"*.html.tmpl"},
+			expected: tmplstest.Coverage{
+				Covered:   []string{"home.html.tmpl"},
+				Uncovered: []string{"about.html.tmpl"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			coverage, err := tmplstest.Measure(coverageFS, templates.Usage(), test.patterns...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(coverage, test.expected) {
+				t.Fatalf("expected %v but got %v", test.expected, coverage)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	coverage := tmplstest.Coverage{
+		Covered:   []string{"a.html.tmpl"},
+		Uncovered: []string{"b.html.tmpl"},
+	}
+
+	tests := []struct {
+		name        string
+		minRatio    float64
+		expectError bool
+	}{
+		{
+			name:        "should pass at threshold",
+			minRatio:    0.5,
+			expectError: false,
+		},
+		{
+			name:        "should fail below threshold",
+			minRatio:    0.75,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := coverage.Check(test.minRatio)
+			if test.expectError != (err != nil) {
+				t.Fatalf("expectError=%v, got %v", test.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package tmplstest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Faults is a tmpls.FaultInjector for exercising error paths. Faults are keyed
+// by the exact glob passed to Execute. Parse faults only fire when a glob is
+// parsed, so combine them with Config.DisableCache or set them before the
+// first execution. The zero value injects nothing and is ready to use.
+type Faults struct {
+	mu      sync.Mutex
+	parse   map[string]error
+	execute map[string]error
+	delay   map[string]time.Duration
+}
+
+// FailParse makes parsing glob fail with err.
+func (f *Faults) FailParse(glob string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.parse == nil {
+		f.parse = map[string]error{}
+	}
+	f.parse[glob] = err
+}
+
+// FailExecute makes executing any template from glob fail with err.
+func (f *Faults) FailExecute(glob string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.execute == nil {
+		f.execute = map[string]error{}
+	}
+	f.execute[glob] = err
+}
+
+// Delay makes executing any template from glob take at least d, or until the
+// execution's context is done.
+func (f *Faults) Delay(glob string, d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.delay == nil {
+		f.delay = map[string]time.Duration{}
+	}
+	f.delay[glob] = d
+}
+
+// Reset removes every injected fault.
+func (f *Faults) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.parse = nil
+	f.execute = nil
+	f.delay = nil
+}
+
+func (f *Faults) BeforeParse(glob string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.parse[glob]
+}
+
+func (f *Faults) BeforeExecute(ctx context.Context, glob string, _ string) error {
+	f.mu.Lock()
+	d, err := f.delay[glob], f.execute[glob]
+	f.mu.Unlock()
+	if d > 0 {
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
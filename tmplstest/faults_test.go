@@ -0,0 +1,115 @@
+package tmplstest_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/fivethirty/tmpls/tmplstest"
+)
+
+var faultsFS = fstest.MapFS{
+	"page.html.tmpl": &fstest.MapFile{
+		Data: []byte(`page`),
+	},
+	"other.html.tmpl": &fstest.MapFile{
+		Data: []byte(`other`),
+	},
+	"common/empty.html.tmpl": &fstest.MapFile{},
+}
+
+func TestFaults(t *testing.T) {
+	t.Parallel()
+
+	errInjected := errors.New("injected")
+
+	tests := []struct {
+		name        string
+		inject      func(f *tmplstest.Faults)
+		glob        string
+		expectErr   error
+		timeout     time.Duration
+		expectDelay time.Duration
+	}{
+		{
+			name:      "should fail parse",
+			inject:    func(f *tmplstest.Faults) { f.FailParse("page.html.tmpl", errInjected) },
+			glob:      "page.html.tmpl",
+			expectErr: errInjected,
+		},
+		{
+			name:      "should fail execute",
+			inject:    func(f *tmplstest.Faults) { f.FailExecute("page.html.tmpl", errInjected) },
+			glob:      "page.html.tmpl",
+			expectErr: errInjected,
+		},
+		{
+			name: "should delay execute",
+			inject: func(f *tmplstest.Faults) {
+				f.Delay("page.html.tmpl", 20*time.Millisecond)
+			},
+			glob:        "page.html.tmpl",
+			expectDelay: 20 * time.Millisecond,
+		},
+		{
+			name: "should stop delay when context is done",
+			inject: func(f *tmplstest.Faults) {
+				f.Delay("page.html.tmpl", time.Hour)
+			},
+			glob:      "page.html.tmpl",
+			timeout:   20 * time.Millisecond,
+			expectErr: context.DeadlineExceeded,
+		},
+		{
+			name:   "should not affect other globs",
+			inject: func(f *tmplstest.Faults) { f.FailExecute("page.html.tmpl", errInjected) },
+			glob:   "other.html.tmpl",
+		},
+		{
+			name: "should not inject after reset",
+			inject: func(f *tmplstest.Faults) {
+				f.FailParse("page.html.tmpl", errInjected)
+				f.Reset()
+			},
+			glob: "page.html.tmpl",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			faults := &tmplstest.Faults{}
+			test.inject(faults)
+			templates, err := tmpls.New(
+				tmpls.Config{
+					TemplatesFS: faultsFS,
+					CommonGlob:  "common/*.html.tmpl",
+					Faults:      faults,
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			if test.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, test.timeout)
+				defer cancel()
+			}
+			start := time.Now()
+			_, err = templates.ExecuteContext(ctx, test.glob, test.glob, nil)
+			if !errors.Is(err, test.expectErr) {
+				t.Fatalf("expected %v but got %v", test.expectErr, err)
+			}
+			if elapsed := time.Since(start); elapsed < test.expectDelay {
+				t.Fatalf("expected delay of %v but took %v", test.expectDelay, elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package tmplstest
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/fivethirty/tmpls/fixture"
+)
+
+// Fixture returns the sample data kept next to template in fsys, failing the
+// test if it is missing or can't be decoded.
+func Fixture(t testing.TB, fsys fs.FS, template string) any {
+	t.Helper()
+	data, ok, err := fixture.Load(fsys, template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("no fixture for %s", template)
+	}
+	return data
+}
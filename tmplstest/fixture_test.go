@@ -0,0 +1,37 @@
+package tmplstest_test
+
+import (
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+	"github.com/fivethirty/tmpls/tmplstest"
+)
+
+func TestFixture(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"common/empty.html.tmpl": &fstest.MapFile{},
+		"page.html.tmpl":         &fstest.MapFile{Data: []byte(`<h1>{{ .Title }}</h1>`)},
+		"page.html.tmpl.json":    &fstest.MapFile{Data: []byte(`{"Title": "Golden"}`)},
+	}
+	templates, err := tmpls.New(
+		tmpls.Config{TemplatesFS: fsys, CommonGlob: "common/*.html.tmpl"},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := tmplstest.Fixture(t, fsys, "page.html.tmpl")
+	output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "<h1>Golden</h1>"
+	if output != expected {
+		t.Fatalf("expected %s but got %s", expected, output)
+	}
+}
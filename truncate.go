@@ -0,0 +1,231 @@
+package tmpls
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const ellipsis = "…"
+
+// voidElements have no closing tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true,
+	"track": true, "wbr": true,
+}
+
+// truncateHTML shortens value to at most n visible characters, appending an
+// ellipsis and closing the tags left open. Values of type template.HTML are
+// truncated as markup, counting entities as one character, while strings
+// are escaped first. Characters are counted as graphemeLen splits them.
+func truncateHTML(value any, n int) (template.HTML, error) {
+	if n < 0 {
+		return "", fmt.Errorf("truncateHTML: negative length %d", n)
+	}
+	var src string
+	switch v := value.(type) {
+	case template.HTML:
+		src = string(v)
+	case string:
+		src = html.EscapeString(v)
+	default:
+		return "", fmt.Errorf("truncateHTML: unsupported type %T", value)
+	}
+	var out strings.Builder
+	var open []string
+	visible := 0
+	for len(src) > 0 {
+		if visible == n {
+			if !hasVisibleText(src) {
+				out.WriteString(src)
+				break
+			}
+			truncated := strings.TrimRight(out.String(), " \t\r\n")
+			out.Reset()
+			out.WriteString(truncated)
+			out.WriteString(ellipsis)
+			for i := len(open) - 1; i >= 0; i-- {
+				out.WriteString("</" + open[i] + ">")
+			}
+			break
+		}
+		if src[0] == '<' {
+			tag, name, closing := nextTag(src)
+			out.WriteString(tag)
+			src = src[len(tag):]
+			switch {
+			case name == "" || voidElements[name] || strings.HasSuffix(tag, "/>"):
+			case closing:
+				if i := lastIndex(open, name); i >= 0 {
+					open = open[:i]
+				}
+			default:
+				open = append(open, name)
+			}
+			continue
+		}
+		size := 1
+		if src[0] == '&' {
+			if end := strings.IndexByte(src, ';'); end > 0 && end <= 32 {
+				size = end + 1
+			}
+		} else {
+			size = graphemeLen(src)
+		}
+		out.WriteString(src[:size])
+		src = src[size:]
+		visible++
+	}
+	return template.HTML(out.String()), nil
+}
+
+// truncate shortens s to at most n characters, appending an ellipsis, without
+// splitting characters made of several code points such as emoji sequences
+// and letters with combining accents:
+//
+//	{{ truncate 80 .Comment }}
+func truncate(n int, s string) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("truncate: negative length %d", n)
+	}
+	end := 0
+	for visible := 0; end < len(s); visible++ {
+		if visible == n {
+			return strings.TrimRight(s[:end], " \t\r\n") + ellipsis, nil
+		}
+		end += graphemeLen(s[end:])
+	}
+	return s, nil
+}
+
+// graphemeLen returns the length in bytes of the character at the start of
+// s, approximating Unicode grapheme clusters: a code point followed by any
+// combining marks, variation selectors, emoji modifiers and tags, and code
+// points joined to it with zero width joiners, or a pair of regional
+// indicators making a flag.
+func graphemeLen(s string) int {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == '\r' && strings.HasPrefix(s[size:], "\n") {
+		return size + 1
+	}
+	if isRegionalIndicator(r) {
+		if next, n := utf8.DecodeRuneInString(s[size:]); isRegionalIndicator(next) {
+			return size + n
+		}
+		return size
+	}
+	for size < len(s) {
+		next, n := utf8.DecodeRuneInString(s[size:])
+		switch {
+		case next == zeroWidthJoiner:
+			size += n
+			if size < len(s) {
+				_, n = utf8.DecodeRuneInString(s[size:])
+				size += n
+			}
+		case unicode.In(next, unicode.Mn, unicode.Me, unicode.Mc) ||
+			unicode.Is(unicode.Variation_Selector, next) ||
+			(next >= 0x1f3fb && next <= 0x1f3ff) || // skin tone modifiers
+			(next >= 0xe0020 && next <= 0xe007f): // tags
+			size += n
+		default:
+			return size
+		}
+	}
+	return size
+}
+
+const zeroWidthJoiner = '\u200d'
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// nextTag returns the tag, comment or doctype at the start of src, the
+// lowercased element name if it is a tag, and whether it is a closing tag.
+// The raw text of script and style elements is returned with their opening
+// tags, since it isn't visible and may contain '<'.
+func nextTag(src string) (tag string, name string, closing bool) {
+	if strings.HasPrefix(src, "<!--") {
+		end := strings.Index(src, "-->")
+		if end < 0 {
+			return src, "", false
+		}
+		return src[:end+3], "", false
+	}
+	end := tagEnd(src)
+	if end < 0 {
+		return src, "", false
+	}
+	tag = src[:end]
+	inner := strings.TrimPrefix(tag[1:], "/")
+	closing = len(inner) < len(tag)-1
+	nameEnd := strings.IndexAny(inner, " \t\r\n/>")
+	if nameEnd < 0 {
+		nameEnd = len(inner)
+	}
+	name = strings.ToLower(inner[:nameEnd])
+	if name == "" || name[0] == '!' || name[0] == '?' {
+		return tag, "", closing
+	}
+	if !closing && (name == "script" || name == "style") {
+		raw := strings.Index(strings.ToLower(src[end:]), "</"+name)
+		if raw < 0 {
+			raw = len(src) - end
+		}
+		tag = src[:end+raw]
+	}
+	return tag, name, closing
+}
+
+// tagEnd returns the index just past the '>' closing the tag at the start of
+// src, skipping quoted attribute values, or -1 if it is unclosed.
+func tagEnd(src string) int {
+	var quote byte
+	for i := 1; i < len(src); i++ {
+		switch c := src[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// hasVisibleText reports whether src has non-whitespace text outside tags.
+func hasVisibleText(src string) bool {
+	for len(src) > 0 {
+		if src[0] == '<' {
+			tag, _, _ := nextTag(src)
+			src = src[len(tag):]
+			continue
+		}
+		text := src
+		if end := strings.IndexByte(src, '<'); end >= 0 {
+			text = src[:end]
+		}
+		if strings.TrimSpace(text) != "" {
+			return true
+		}
+		src = src[len(text):]
+	}
+	return false
+}
+
+func lastIndex(names []string, name string) int {
+	for i := len(names) - 1; i >= 0; i-- {
+		if names[i] == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,185 @@
+package tmpls_test
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestTruncateHTML(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		value       any
+		n           int
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should keep short html",
+			value:    template.HTML(`<p>Hello <b>world</b></p>`),
+			n:        11,
+			expected: `<p>Hello <b>world</b></p>`,
+		},
+		{
+			name:     "should close open tags",
+			value:    template.HTML(`<p>Hello <b>world</b> again</p>`),
+			n:        8,
+			expected: `<p>Hello <b>wo…</b></p>`,
+		},
+		{
+			name:     "should trim whitespace before ellipsis",
+			value:    template.HTML(`<p>Hello <i>world</i></p>`),
+			n:        6,
+			expected: `<p>Hello…</p>`,
+		},
+		{
+			name:     "should count entities as one character",
+			value:    template.HTML(`<p>&lt;a&gt; &amp; more</p>`),
+			n:        5,
+			expected: `<p>&lt;a&gt; &amp;…</p>`,
+		},
+		{
+			name: "should skip void elements, comments and raw text",
+			value: template.HTML(`a<br><!-- <b> --><img src="x>y"/>` +
+				`<script>if (a<b) {}</script>bcd<p>e</p>`),
+			n:        3,
+			expected: `a<br><!-- <b> --><img src="x>y"/><script>if (a<b) {}</script>bc…`,
+		},
+		{
+			name:     "should count runes",
+			value:    template.HTML(`<em>héllo wörld</em>`),
+			n:        4,
+			expected: `<em>héll…</em>`,
+		},
+		{
+			name:     "should keep emoji sequences whole",
+			value:    template.HTML("<b>hi 👨‍👩‍👧 there</b>"),
+			n:        4,
+			expected: "<b>hi 👨‍👩‍👧…</b>",
+		},
+		{
+			name:     "should keep trailing tags without text",
+			value:    template.HTML(`<p>abc</p> <p><img src="a"></p>`),
+			n:        3,
+			expected: `<p>abc</p> <p><img src="a"></p>`,
+		},
+		{
+			name:     "should escape strings",
+			value:    `<b>bold</b>`,
+			n:        4,
+			expected: `&lt;b&gt;b…`,
+		},
+		{
+			name:        "should fail for negative length",
+			value:       template.HTML(`a`),
+			n:           -1,
+			expectError: true,
+		},
+		{
+			name:        "should fail for unsupported type",
+			value:       1,
+			n:           1,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(
+				t,
+				tmpls.Config{},
+				`{{ truncateHTML .Value .N }}`,
+				map[string]any{"Value": test.value, "N": test.n},
+			)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		value       string
+		n           int
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should leave short strings as they are",
+			value:    "hello",
+			n:        5,
+			expected: "hello",
+		},
+		{
+			name:     "should trim trailing whitespace before the ellipsis",
+			value:    "hello world",
+			n:        6,
+			expected: "hello…",
+		},
+		{
+			name:     "should keep combining marks with their letter",
+			value:    "cafe\u0301 au lait",
+			n:        4,
+			expected: "cafe\u0301…",
+		},
+		{
+			name:     "should keep joined emoji whole",
+			value:    "👩\u200d💻👍🏽 done",
+			n:        2,
+			expected: "👩\u200d💻👍🏽…",
+		},
+		{
+			name:     "should keep flags whole",
+			value:    "🇳🇱🇩🇪🇫🇷",
+			n:        1,
+			expected: "🇳🇱…",
+		},
+		{
+			name:        "should fail on negative lengths",
+			value:       "hello",
+			n:           -1,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(
+				t,
+				tmpls.Config{},
+				`{{ truncate .N .Value }}`,
+				map[string]any{"Value": test.value, "N": test.n},
+			)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package tmpls
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URLBuilder builds the URL of a named route, for the url func.
+type URLBuilder interface {
+	URL(name string, params map[string]string) (string, error)
+}
+
+// URLFunc adapts an ordinary function, such as a router's reverse lookup, to
+// a URLBuilder.
+type URLFunc func(name string, params map[string]string) (string, error)
+
+func (f URLFunc) URL(name string, params map[string]string) (string, error) {
+	return f(name, params)
+}
+
+// Routes is a URLBuilder mapping route names to net/http.ServeMux style
+// patterns, e.g. "/users/{id}" or "/files/{path...}". Params filling
+// wildcards are path-escaped, and the rest are added as query parameters.
+type Routes map[string]string
+
+func (r Routes) URL(name string, params map[string]string) (string, error) {
+	pattern, ok := r[name]
+	if !ok {
+		return "", fmt.Errorf("unknown route %q", name)
+	}
+	// drop any method or host, as in "GET example.com/users/{id}"
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		pattern = path
+	}
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		pattern = pattern[i:]
+	}
+	pattern = strings.ReplaceAll(pattern, "{$}", "")
+	used := map[string]bool{}
+	var out strings.Builder
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			out.WriteString(pattern)
+			break
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("route %q has an unclosed wildcard", name)
+		}
+		out.WriteString(pattern[:start])
+		wildcard := pattern[start+1 : start+end]
+		pattern = pattern[start+end+1:]
+		wildcard, rest := strings.CutSuffix(wildcard, "...")
+		value, ok := params[wildcard]
+		if !ok {
+			return "", fmt.Errorf("route %q requires param %q", name, wildcard)
+		}
+		used[wildcard] = true
+		if !rest {
+			out.WriteString(url.PathEscape(value))
+			continue
+		}
+		segments := strings.Split(value, "/")
+		for i, segment := range segments {
+			segments[i] = url.PathEscape(segment)
+		}
+		out.WriteString(strings.Join(segments, "/"))
+	}
+	query := url.Values{}
+	for key, value := range params {
+		if !used[key] {
+			query.Set(key, value)
+		}
+	}
+	if len(query) > 0 {
+		out.WriteString("?" + query.Encode())
+	}
+	return out.String(), nil
+}
+
+// url builds the URL of the named route from Config.URLs, with params given
+// as name-value pairs:
+//
+//	<a href="{{ url "user.show" "id" .ID }}">
+func (t *Templates) url(name string, pairs ...any) (string, error) {
+	if t.config.URLs == nil {
+		return "", errors.New("url requires Config.URLs")
+	}
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("url requires name-value pairs but got %d arguments", len(pairs))
+	}
+	params := make(map[string]string, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return "", fmt.Errorf("url param names must be strings but got %T", pairs[i])
+		}
+		params[key] = fmt.Sprint(pairs[i+1])
+	}
+	return t.config.URLs.URL(name, params)
+}
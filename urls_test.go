@@ -0,0 +1,94 @@
+package tmpls_test
+
+import (
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestURL(t *testing.T) {
+	t.Parallel()
+
+	routes := tmpls.Routes{
+		"home":       "GET /{$}",
+		"user.show":  "GET /users/{id}",
+		"files.show": "example.com/files/{path...}",
+	}
+
+	tests := []struct {
+		name        string
+		urls        tmpls.URLBuilder
+		src         string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should build url without params",
+			urls:     routes,
+			src:      `<a href="{{ url "home" }}">`,
+			expected: `<a href="/">`,
+		},
+		{
+			name:     "should fill wildcards",
+			urls:     routes,
+			src:      `<a href="{{ url "user.show" "id" 42 }}">`,
+			expected: `<a href="/users/42">`,
+		},
+		{
+			name:     "should escape wildcards and add query params",
+			urls:     routes,
+			src:      `<a href="{{ url "files.show" "path" "a b/c?d" "v" 2 "q" "x&y" }}">`,
+			expected: `<a href="/files/a%20b/c%3Fd?q=x%26y&amp;v=2">`,
+		},
+		{
+			name: "should use custom builder",
+			urls: tmpls.URLFunc(func(name string, params map[string]string) (string, error) {
+				return "/" + name + "/" + params["id"], nil
+			}),
+			src:      `{{ url "posts" "id" "7" }}`,
+			expected: `/posts/7`,
+		},
+		{
+			name:        "should fail for missing param",
+			urls:        routes,
+			src:         `{{ url "user.show" }}`,
+			expectError: true,
+		},
+		{
+			name:        "should fail for unknown route",
+			urls:        routes,
+			src:         `{{ url "user.edit" "id" 1 }}`,
+			expectError: true,
+		},
+		{
+			name:        "should fail for odd params",
+			urls:        routes,
+			src:         `{{ url "user.show" "id" }}`,
+			expectError: true,
+		},
+		{
+			name:        "should fail without builder",
+			src:         `{{ url "home" }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := render(t, tmpls.Config{URLs: test.urls}, test.src, nil)
+			if test.expectError {
+				if !tmpls.IsExecError(err) {
+					t.Fatalf("expected exec error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package tmpls
+
+import (
+	"context"
+	"reflect"
+)
+
+// UserProvider resolves the signed-in user for the request carried by ctx,
+// returning nil if nobody is signed in.
+type UserProvider interface {
+	CurrentUser(ctx context.Context) (any, error)
+}
+
+// UserFunc adapts an ordinary function to a UserProvider.
+type UserFunc func(ctx context.Context) (any, error)
+
+func (f UserFunc) CurrentUser(ctx context.Context) (any, error) {
+	return f(ctx)
+}
+
+func (t *Templates) currentUser(ctx context.Context) any {
+	return func() (any, error) {
+		return t.resolveUser(ctx)
+	}
+}
+
+func (t *Templates) loggedIn(ctx context.Context) any {
+	return func() (bool, error) {
+		user, err := t.resolveUser(ctx)
+		if err != nil {
+			return false, err
+		}
+		if user == nil {
+			return false, nil
+		}
+		value := reflect.ValueOf(user)
+		switch value.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			return !value.IsNil(), nil
+		}
+		return true, nil
+	}
+}
+
+// resolveUser asks Config.Users at most once per execution.
+func (t *Templates) resolveUser(ctx context.Context) (any, error) {
+	if t.config.Users == nil {
+		if rc := RenderContextFrom(ctx); rc != nil {
+			return rc.User, nil
+		}
+		return nil, nil
+	}
+	execution := executionFrom(ctx)
+	if execution == nil {
+		return t.config.Users.CurrentUser(ctx)
+	}
+	execution.userOnce.Do(func() {
+		execution.user, execution.userErr = t.config.Users.CurrentUser(ctx)
+	})
+	return execution.user, execution.userErr
+}
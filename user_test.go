@@ -0,0 +1,115 @@
+package tmpls_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/fivethirty/tmpls"
+)
+
+type user struct {
+	Name string
+}
+
+type userKey struct{}
+
+func TestCurrentUser(t *testing.T) {
+	t.Parallel()
+
+	fromContext := func(ctx context.Context) (any, error) {
+		u, _ := ctx.Value(userKey{}).(*user)
+		return u, nil
+	}
+
+	tests := []struct {
+		name        string
+		users       tmpls.UserProvider
+		ctx         context.Context
+		src         string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should resolve user from provider",
+			users:    tmpls.UserFunc(fromContext),
+			ctx:      context.WithValue(context.Background(), userKey{}, &user{Name: "Tom"}),
+			src:      `{{ if loggedIn }}Hi {{ currentUser.Name }}{{ end }}`,
+			expected: "Hi Tom",
+		},
+		{
+			name:     "should not be logged in with nil pointer user",
+			users:    tmpls.UserFunc(fromContext),
+			ctx:      context.Background(),
+			src:      `{{ if loggedIn }}Hi{{ else }}Sign in{{ end }}`,
+			expected: "Sign in",
+		},
+		{
+			name: "should fall back to render context user",
+			ctx: tmpls.WithRenderContext(
+				context.Background(),
+				&tmpls.RenderContext{User: user{Name: "Jerry"}},
+			),
+			src:      `{{ if loggedIn }}Hi {{ currentUser.Name }}{{ end }}`,
+			expected: "Hi Jerry",
+		},
+		{
+			name: "should return provider error",
+			users: tmpls.UserFunc(func(context.Context) (any, error) {
+				return nil, errors.New("session expired")
+			}),
+			ctx:         context.Background(),
+			src:         `{{ loggedIn }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			templates := newTemplates(t, tmpls.Config{Users: test.users}, test.src)
+			output, err := templates.ExecuteContext(
+				test.ctx,
+				"page.html.tmpl",
+				"page.html.tmpl",
+				nil,
+			)
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestCurrentUserResolvedOncePerExecution(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int64
+	users := tmpls.UserFunc(func(context.Context) (any, error) {
+		calls.Add(1)
+		return &user{Name: "Tom"}, nil
+	})
+	templates := newTemplates(
+		t,
+		tmpls.Config{Users: users},
+		`{{ loggedIn }}{{ currentUser.Name }}{{ currentUser.Name }}`,
+	)
+	for range 2 {
+		if _, err := templates.Execute("page.html.tmpl", "page.html.tmpl", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 calls but got %d", calls.Load())
+	}
+}
@@ -0,0 +1,147 @@
+//go:build !tmpls_lite && !js && !wasip1 && !tinygo
+
+package tmpls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch observes the directory behind a TemplatesFS created with DirFS,
+// dropping the cached executors of globs that parsed or may now match changed
+// files so the next execution parses the new templates. Changes within
+// Config.WatchDebounce of each other are coalesced into one batch,
+// invalidating the cache once, and each batch is
+// then sent on the returned channel, which apps can use to trigger live
+// reload. SwapFS moves the watch to the new filesystem's directory. The
+// channel must be drained and is closed once ctx is done, or once SwapFS
+// replaces the filesystem with one that can't be watched.
+func (t *Templates) Watch(ctx context.Context) (<-chan []ChangeEvent, error) {
+	swapped := t.swaps()
+	watcher, root, err := t.watchFS()
+	if err != nil {
+		return nil, err
+	}
+
+	batches := make(chan []ChangeEvent)
+	go func() {
+		defer close(batches)
+		defer func() { watcher.Close() }()
+		var pending changeBatch
+		var flush <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-swapped:
+				// SwapFS dropped every cached executor, so pending
+				// changes to the old directory no longer matter
+				swapped = t.swaps()
+				next, nextRoot, err := t.watchFS()
+				if err != nil {
+					t.log().Error("Watching swapped templates failed", "error", err)
+					return
+				}
+				watcher.Close()
+				watcher, root = next, nextRoot
+				pending.take()
+				flush = nil
+				continue
+			case err := <-watcher.Errors:
+				t.log().Error("Watching templates failed", "error", err)
+				continue
+			case event := <-watcher.Events:
+				change, ok := t.change(watcher, root, event)
+				if !ok {
+					continue
+				}
+				pending.add(change)
+				if t.config.WatchDebounce > 0 {
+					flush = time.After(t.config.WatchDebounce)
+					continue
+				}
+			case <-flush:
+			}
+			flush = nil
+			batch := pending.take()
+			dropped := t.invalidate(batch)
+			t.log().Debug("Templates changed", "changes", len(batch), "invalidated", dropped)
+			select {
+			case batches <- batch:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return batches, nil
+}
+
+// watchFS watches every directory behind the current templates filesystem,
+// returning the watcher and the root directory.
+func (t *Templates) watchFS() (*fsnotify.Watcher, string, error) {
+	dir, ok := t.state.Load().fsys.(dirFS)
+	if !ok {
+		return nil, "", fmt.Errorf("TemplatesFS must be created with DirFS to watch it")
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, "", err
+	}
+	if err := addDirs(watcher, dir.dir); err != nil {
+		return nil, "", errors.Join(err, watcher.Close())
+	}
+	return watcher, dir.dir, nil
+}
+
+// change converts event to a path relative to root, watching any new
+// directory.
+func (t *Templates) change(
+	watcher *fsnotify.Watcher,
+	root string,
+	event fsnotify.Event,
+) (ChangeEvent, bool) {
+	if event.Op == fsnotify.Chmod {
+		return ChangeEvent{}, false
+	}
+	rel, err := filepath.Rel(root, event.Name)
+	if err != nil {
+		return ChangeEvent{}, false
+	}
+	if event.Has(fsnotify.Create) {
+		if err := addDirs(watcher, event.Name); err != nil {
+			t.log().Error("Watching new directory failed", "error", err, "path", rel)
+		}
+	}
+	return ChangeEvent{Path: filepath.ToSlash(rel), Op: changeOp(event.Op)}, true
+}
+
+func changeOp(op fsnotify.Op) ChangeOp {
+	switch {
+	case op.Has(fsnotify.Create):
+		return Created
+	case op.Has(fsnotify.Remove), op.Has(fsnotify.Rename):
+		return Removed
+	default:
+		return Modified
+	}
+}
+
+// addDirs watches root and every directory below it. A root that isn't a
+// directory is ignored.
+func addDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		return watcher.Add(path)
+	})
+}
@@ -0,0 +1,10 @@
+//go:build tmpls_lite || js || wasip1 || tinygo
+
+package tmpls
+
+import "context"
+
+// Watch is unsupported in this build.
+func (t *Templates) Watch(ctx context.Context) (<-chan []ChangeEvent, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,315 @@
+//go:build !tmpls_lite && !js && !wasip1 && !tinygo
+
+package tmpls_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/fivethirty/tmpls"
+)
+
+type watched struct {
+	t         *testing.T
+	dir       string
+	templates *tmpls.Templates
+	batches   <-chan []tmpls.ChangeEvent
+}
+
+func watch(t *testing.T, debounce time.Duration) *watched {
+	t.Helper()
+	return watchWith(t, tmpls.Config{WatchDebounce: debounce})
+}
+
+func watchWith(t *testing.T, config tmpls.Config) *watched {
+	t.Helper()
+	w := &watched{t: t, dir: t.TempDir()}
+	w.write("common/empty.html.tmpl", "")
+	w.write("page.html.tmpl", "before")
+
+	config.TemplatesFS = tmpls.DirFS(w.dir)
+	config.CommonGlob = "common/*.html.tmpl"
+	templates, err := tmpls.New(
+		config,
+		slog.New(slog.DiscardHandler),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	batches, err := templates.Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		for range batches {
+		}
+	})
+	w.templates = templates
+	w.batches = batches
+	return w
+}
+
+func (w *watched) write(name string, contents string) {
+	w.t.Helper()
+	file := filepath.Join(w.dir, name)
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		w.t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(contents), 0o600); err != nil {
+		w.t.Fatal(err)
+	}
+}
+
+func (w *watched) execute(expected string) {
+	w.t.Helper()
+	output, err := w.templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+	if err != nil {
+		w.t.Fatal(err)
+	}
+	if output != expected {
+		w.t.Fatalf("expected %s but got %s", expected, output)
+	}
+}
+
+// next waits for a batch containing expected.
+func (w *watched) next(expected tmpls.ChangeEvent) []tmpls.ChangeEvent {
+	w.t.Helper()
+	for {
+		select {
+		case batch := <-w.batches:
+			if slices.Contains(batch, expected) {
+				return batch
+			}
+		case <-time.After(5 * time.Second):
+			w.t.Fatalf("timed out waiting for %v", expected)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	w := watch(t, 0)
+	w.execute("before")
+	w.write("page.html.tmpl", "after")
+	w.next(tmpls.ChangeEvent{Path: "page.html.tmpl", Op: tmpls.Modified})
+	w.execute("after")
+
+	w.write("nested/new.html.tmpl", "")
+	w.next(tmpls.ChangeEvent{Path: "nested", Op: tmpls.Created})
+	w.write("nested/new.html.tmpl", "new")
+	w.next(tmpls.ChangeEvent{Path: "nested/new.html.tmpl", Op: tmpls.Modified})
+}
+
+func TestWatchDebounce(t *testing.T) {
+	t.Parallel()
+
+	w := watch(t, 200*time.Millisecond)
+	w.execute("before")
+	w.write("page.html.tmpl", "after")
+	w.write("other.html.tmpl", "")
+	w.write("other.html.tmpl", "other")
+	w.write("page.html.tmpl", "latest")
+
+	batch := w.next(tmpls.ChangeEvent{Path: "page.html.tmpl", Op: tmpls.Modified})
+	expected := []tmpls.ChangeEvent{
+		{Path: "page.html.tmpl", Op: tmpls.Modified},
+		{Path: "other.html.tmpl", Op: tmpls.Created},
+	}
+	if !slices.Equal(batch, expected) {
+		t.Fatalf("expected %v but got %v", expected, batch)
+	}
+	w.execute("latest")
+}
+
+func TestWatchInvalidatesDependents(t *testing.T) {
+	t.Parallel()
+
+	counter := &parseCounter{parses: map[string]int{}}
+	w := watchWith(t, tmpls.Config{Faults: counter, WatchDebounce: 100 * time.Millisecond})
+	w.write("other.html.tmpl", "other")
+	w.next(tmpls.ChangeEvent{Path: "other.html.tmpl", Op: tmpls.Created})
+
+	globs := []string{"page.html.tmpl", "other.html.tmpl", "*.html.tmpl"}
+	executeAll := func() {
+		t.Helper()
+		for _, glob := range globs {
+			if _, err := w.templates.Execute(glob, "page.html.tmpl", nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	expectParses := func(expected map[string]int) {
+		t.Helper()
+		for _, glob := range globs {
+			if parses := counter.count(glob); parses != expected[glob] {
+				t.Fatalf("expected %d parses of %s but got %d", expected[glob], glob, parses)
+			}
+		}
+	}
+	w.write("page.html.tmpl", `{{ define "page.html.tmpl" }}page{{ end }}`)
+	w.write("other.html.tmpl", `{{ define "page.html.tmpl" }}other{{ end }}`)
+	w.next(tmpls.ChangeEvent{Path: "other.html.tmpl", Op: tmpls.Modified})
+	executeAll()
+	expectParses(map[string]int{"page.html.tmpl": 1, "other.html.tmpl": 1, "*.html.tmpl": 1})
+
+	w.write("page.html.tmpl", "changed")
+	w.next(tmpls.ChangeEvent{Path: "page.html.tmpl", Op: tmpls.Modified})
+	executeAll()
+	expectParses(map[string]int{"page.html.tmpl": 2, "other.html.tmpl": 1, "*.html.tmpl": 2})
+
+	w.write("new.html.tmpl", "")
+	w.next(tmpls.ChangeEvent{Path: "new.html.tmpl", Op: tmpls.Created})
+	executeAll()
+	expectParses(map[string]int{"page.html.tmpl": 2, "other.html.tmpl": 1, "*.html.tmpl": 3})
+
+	w.write("common/empty.html.tmpl", "{{/* changed */}}")
+	w.next(tmpls.ChangeEvent{Path: "common/empty.html.tmpl", Op: tmpls.Modified})
+	executeAll()
+	expectParses(map[string]int{"page.html.tmpl": 3, "other.html.tmpl": 2, "*.html.tmpl": 4})
+}
+
+func TestWatchDiscoversComponents(t *testing.T) {
+	t.Parallel()
+
+	w := watchWith(t, tmpls.Config{
+		ComponentsGlob: "components/*.html.tmpl",
+		WatchDebounce:  100 * time.Millisecond,
+	})
+	w.write("components/old.html.tmpl", "old")
+	w.next(tmpls.ChangeEvent{Path: "components", Op: tmpls.Created})
+	w.write("page.html.tmpl", `{{ component "old" }}`)
+	w.next(tmpls.ChangeEvent{Path: "page.html.tmpl", Op: tmpls.Modified})
+	w.execute("old")
+
+	w.write("components/new.html.tmpl", "new")
+	w.next(tmpls.ChangeEvent{Path: "components/new.html.tmpl", Op: tmpls.Created})
+	w.write("page.html.tmpl", `{{ component "old" }} {{ component "new" }}`)
+	w.next(tmpls.ChangeEvent{Path: "page.html.tmpl", Op: tmpls.Modified})
+	w.execute("old new")
+}
+
+func TestWatchRequiresDirFS(t *testing.T) {
+	t.Parallel()
+
+	templates, err := tmpls.New(
+		tmpls.Config{TemplatesFS: fstest.MapFS{}},
+		slog.New(slog.DiscardHandler),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := templates.Watch(t.Context()); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+}
+
+func TestWatchFollowsSwapFS(t *testing.T) {
+	t.Parallel()
+
+	w := watch(t, 0)
+	w.execute("before")
+	old := w.dir
+	w.dir = t.TempDir()
+	w.write("common/empty.html.tmpl", "")
+	w.write("page.html.tmpl", "swapped")
+	if err := w.templates.SwapFS(tmpls.DirFS(w.dir), false); err != nil {
+		t.Fatal(err)
+	}
+	w.execute("swapped")
+
+	// the watch moves to the new directory in the background
+	modified := tmpls.ChangeEvent{Path: "page.html.tmpl", Op: tmpls.Modified}
+	deadline := time.After(5 * time.Second)
+	for moved := false; !moved; {
+		w.write("page.html.tmpl", "after")
+		select {
+		case batch := <-w.batches:
+			moved = slices.Contains(batch, modified)
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("timed out waiting for the watch to move")
+		}
+	}
+	w.execute("after")
+
+	// the old directory is no longer watched
+	if err := os.WriteFile(filepath.Join(old, "page.html.tmpl"), []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w.write("other.html.tmpl", "")
+	batch := w.next(tmpls.ChangeEvent{Path: "other.html.tmpl", Op: tmpls.Created})
+	if slices.Contains(batch, modified) {
+		t.Fatalf("expected no change to the old directory but got %v", batch)
+	}
+}
+
+func TestWatchStopsOnUnwatchableSwap(t *testing.T) {
+	t.Parallel()
+
+	w := watch(t, 0)
+	fsys := fstest.MapFS{"page.html.tmpl": &fstest.MapFile{}}
+	if err := w.templates.SwapFS(fsys, false); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case _, ok := <-w.batches:
+		if ok {
+			t.Fatal("expected batches to be closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for batches to close")
+	}
+}
+
+func TestWatchKeepsPreviousOnParseError(t *testing.T) {
+	t.Parallel()
+
+	w := watch(t, 0)
+	w.execute("before")
+	w.write("page.html.tmpl", "{{ if }}")
+	w.next(tmpls.ChangeEvent{Path: "page.html.tmpl", Op: tmpls.Modified})
+	w.execute("before")
+	w.execute("before")
+	w.write("page.html.tmpl", "fixed")
+	w.next(tmpls.ChangeEvent{Path: "page.html.tmpl", Op: tmpls.Modified})
+	w.execute("fixed")
+}
+
+func TestWatchStrictReload(t *testing.T) {
+	t.Parallel()
+
+	w := watchWith(t, tmpls.Config{StrictReload: true})
+	w.execute("before")
+	w.write("page.html.tmpl", "{{ if }}")
+	w.next(tmpls.ChangeEvent{Path: "page.html.tmpl", Op: tmpls.Modified})
+	_, err := w.templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+	if !tmpls.IsParseError(err) {
+		t.Fatalf("expected parse error but got %v", err)
+	}
+}
+
+func TestWatchDropsRemoved(t *testing.T) {
+	t.Parallel()
+
+	w := watch(t, 0)
+	w.execute("before")
+	if err := os.Remove(filepath.Join(w.dir, "page.html.tmpl")); err != nil {
+		t.Fatal(err)
+	}
+	w.next(tmpls.ChangeEvent{Path: "page.html.tmpl", Op: tmpls.Removed})
+	_, err := w.templates.Execute("page.html.tmpl", "page.html.tmpl", nil)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected %v but got %v", fs.ErrNotExist, err)
+	}
+}
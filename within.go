@@ -0,0 +1,74 @@
+package tmpls
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+)
+
+// setKey holds the ContextExecutor an execution's templates were cloned from,
+// for funcs starting executions of their own.
+type setKey struct{}
+
+// within renders the content of a within block, or its else branch if the
+// content takes longer than timeout, so a slow fragment degrades instead of
+// stalling the page:
+//
+//	{{ within 50ms }}{{ .Recommendations }}{{ else }}<a href="/picks">Picks</a>{{ end }}
+//
+// The content runs as an execution of its own with a context canceled after
+// timeout, so it doesn't see the page's once keys or slots, and keeps running
+// in the background when abandoned. Without an else branch nothing is
+// rendered in its place.
+func (t *Templates) within(ctx context.Context) any {
+	return func(timeout any, name string, fallback string, dot any) (template.HTML, error) {
+		executor, execution, err := executorFrom(ctx)
+		if err != nil {
+			return "", err
+		}
+		set, ok := ctx.Value(setKey{}).(ContextExecutor)
+		if !ok {
+			return "", errors.New("within requires HTMLEngine or TextEngine")
+		}
+		duration, err := toDuration(timeout)
+		if err != nil {
+			return "", err
+		}
+		child, cancel := context.WithTimeout(ctx, duration)
+		defer cancel()
+		type result struct {
+			output string
+			err    error
+		}
+		results := make(chan result, 1)
+		// the content still counts towards the page's Quotas
+		child = withExecution(child)
+		executionFrom(child).meter = execution.meter
+		go func() {
+			var buffer bytes.Buffer
+			err := set.ExecuteTemplateContext(child, &buffer, name, dot)
+			results <- result{output: buffer.String(), err: err}
+		}()
+		select {
+		case r := <-results:
+			if r.err != nil {
+				return "", r.err
+			}
+			if executionFrom(child).requestBound {
+				bindToRequest(ctx)
+			}
+			return template.HTML(r.output), nil
+		case <-child.Done():
+		}
+		t.log().Warn("Block timed out, rendering fallback", "timeout", duration)
+		if fallback == "" {
+			return "", nil
+		}
+		var buffer bytes.Buffer
+		if err := executor.ExecuteTemplate(&buffer, fallback, dot); err != nil {
+			return "", err
+		}
+		return template.HTML(buffer.String()), nil
+	}
+}
@@ -0,0 +1,112 @@
+package tmpls_test
+
+import (
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fivethirty/tmpls"
+)
+
+func TestWithin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		src         string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "should render fast content",
+			src:      `<p>{{ within 1m }}{{ call .Fast }}{{ else }}fallback{{ end }}</p>`,
+			expected: `<p>&lt;fast&gt;</p>`,
+		},
+		{
+			name: "should render fallback for slow content",
+			src: `<p>{{ within 20ms }}{{ call .Slow }}` +
+				`{{ else }}<i>{{ .Name }}</i>{{ end }}</p>`,
+			expected: `<p><i>page</i></p>`,
+		},
+		{
+			name:     "should render nothing for slow content without else",
+			src:      `<p>{{ within "20ms" }}{{ call .Slow }}{{ end }}</p>`,
+			expected: `<p></p>`,
+		},
+		{
+			name: "should honour trim markers around else",
+			src: "<p>{{ within 20ms -}}\n {{ call .Slow }} \n" +
+				"{{- else -}}\n x \n{{- end }}</p>",
+			expected: `<p>x</p>`,
+		},
+		{
+			name:        "should fail for content errors",
+			src:         `{{ within 1m }}{{ index .Name 10 }}{{ else }}fallback{{ end }}`,
+			expectError: true,
+		},
+		{
+			name:        "should fail for second else",
+			src:         `{{ within 1m }}a{{ else }}b{{ else }}c{{ end }}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			release := make(chan struct{})
+			t.Cleanup(func() {
+				close(release)
+			})
+			data := map[string]any{
+				"Name": "page",
+				"Fast": func() string { return "<fast>" },
+				"Slow": func() string {
+					<-release
+					return "slow"
+				},
+			}
+			templates := newTemplates(t, tmpls.Config{}, test.src)
+			output, err := templates.Execute("page.html.tmpl", "page.html.tmpl", data)
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, output)
+			}
+		})
+	}
+}
+
+func TestWithinText(t *testing.T) {
+	t.Parallel()
+
+	templates, err := tmpls.New(
+		tmpls.Config{
+			TemplatesFS: fstest.MapFS{
+				"page.txt.tmpl": &fstest.MapFile{
+					Data: []byte(`a{{ within 1s }}<inner>{{ end }}b`),
+				},
+				"common/empty.txt.tmpl": &fstest.MapFile{},
+			},
+			CommonGlob: "common/*.txt.tmpl",
+		},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := templates.Execute("page.txt.tmpl", "page.txt.tmpl", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "a<inner>b" {
+		t.Fatalf("expected a<inner>b but got %s", output)
+	}
+}